@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+)
+
+// Archiver copies finalized backups elsewhere, e.g. to remote storage.
+type Archiver interface {
+	// Archive archives the backup with the given path.  The backup must not
+	// be changed or removed, which the Logger does once Archive returns if
+	// DeleteArchived is set.  Metadata, signature and checksum files are next
+	// to the backup, with the same name followed by ".meta", ".sig" and "."
+	// and the name of the Checksum algorithm.  The
+	// context is canceled once ArchiveTimeout has passed, if set.
+	Archive(ctx context.Context, path string) error
+}
+
+// archive gives the backup to the Archiver.
+func (l *Logger) archive(name string) error {
+	ctx := context.Background()
+	if l.ArchiveTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, l.ArchiveTimeout)
+		defer cancel()
+	}
+	if err := l.Archiver.Archive(ctx, name); err != nil {
+		return fmt.Errorf("can't archive backup: %s", err)
+	}
+	if l.DeleteArchived {
+		if l.archived == nil {
+			l.archived = make(map[string]bool)
+		}
+		l.archived[name] = true
+	}
+	return nil
+}
+
+// pruneArchived removes the archived backups that aren't among the newest
+// KeepLocalBackups, nor kept for being unshipped.
+func (l *Logger) pruneArchived() error {
+	if len(l.archived) == 0 {
+		return nil
+	}
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	files, err := l.oldLogFiles()
+	if err != nil {
+		return err
+	}
+	removable := l.removableFilter()
+	present := make(map[string]bool, len(files))
+	for _, f := range files {
+		fn := filepath.Join(f.dir, f.Name())
+		present[fn] = true
+		if !l.archived[fn] || (removable != nil && !removable(f)) {
+			continue
+		}
+		errRemove := l.remove(fn)
+		if err == nil && errRemove != nil {
+			err = errRemove
+		}
+		delete(l.archived, fn)
+	}
+	// forget about the backups removed by other means.
+	for fn := range l.archived {
+		if !present[fn] {
+			delete(l.archived, fn)
+		}
+	}
+	return err
+}
+
+// pinnedFilter returns a func reporting whether a backup is not among the
+// newest KeepLocalBackups, or nil if no backups are kept that way.
+func (l *Logger) pinnedFilter() func(logInfo) bool {
+	if l.KeepLocalBackups <= 0 {
+		return nil
+	}
+	pinned := make(map[string]bool, l.KeepLocalBackups)
+	if files, err := l.oldLogFiles(); err == nil {
+		for i := 0; i < len(files) && i < l.KeepLocalBackups; i++ {
+			pinned[filepath.Join(files[i].dir, files[i].Name())] = true
+		}
+	}
+	return func(f logInfo) bool {
+		return !pinned[filepath.Join(f.dir, f.Name())]
+	}
+}
@@ -0,0 +1,169 @@
+package lumberjack
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeArchiver records the content of the backups it archives.
+type fakeArchiver struct {
+	mu       sync.Mutex
+	archived map[string][]byte
+	err      error
+
+	// block makes Archive wait for the context to be done.
+	block bool
+}
+
+func (a *fakeArchiver) Archive(ctx context.Context, path string) error {
+	if a.block {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.err != nil {
+		return a.err
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if a.archived == nil {
+		a.archived = make(map[string][]byte)
+	}
+	a.archived[path] = b
+	return nil
+}
+
+func (a *fakeArchiver) content(path string) []byte {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.archived[path]
+}
+
+func TestArchiverKeepLocalBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestArchiverKeepLocalBackups", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	archiver := &fakeArchiver{}
+	l := &Logger{
+		Filename:         filename,
+		MaxSize:          10,
+		Archiver:         archiver,
+		DeleteArchived:   true,
+		KeepLocalBackups: 1,
+	}
+	defer l.Close()
+
+	var backups []string
+	for _, b := range []string{"one", "two", "three"} {
+		writeToCurrentLog(t, l, filename, []byte(b))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		backups = append(backups, backupFile(dir))
+
+		isNil(l.Mill(context.Background()), t)
+	}
+
+	equals("one", string(archiver.content(backups[0])), t)
+	equals("two", string(archiver.content(backups[1])), t)
+	equals("three", string(archiver.content(backups[2])), t)
+
+	// only the newest backup is kept locally.
+	notExist(backups[0], t)
+	notExist(backups[1], t)
+	existsWithContent(backups[2], []byte("three"), t)
+	fileCount(dir, 2, t)
+}
+
+func TestArchiverFailure(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestArchiverFailure", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		Archiver:       &fakeArchiver{err: errors.New("unreachable")},
+		DeleteArchived: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+
+	// backups that weren't archived stay.
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+}
+
+func TestArchiveTimeout(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestArchiveTimeout", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		Archiver:       &fakeArchiver{block: true},
+		ArchiveTimeout: time.Millisecond,
+		DeleteArchived: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+
+	// the archiving was canceled, so the backup stays.
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+}
+
+func TestKeepLocalBackupsOverridesMaxBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestKeepLocalBackupsOverridesMaxBackups", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:         filename,
+		MaxSize:          10,
+		MaxBackups:       1,
+		KeepLocalBackups: 2,
+	}
+	defer l.Close()
+
+	var backups []string
+	for _, b := range []string{"one", "two", "three"} {
+		writeToCurrentLog(t, l, filename, []byte(b))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		backups = append(backups, backupFile(dir))
+	}
+	isNil(l.Mill(context.Background()), t)
+
+	notExist(backups[0], t)
+	exists(backups[1], t)
+	exists(backups[2], t)
+	fileCount(dir, 3, t)
+}
@@ -0,0 +1,264 @@
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// defaultPartSize is the default size of the parts of a multipart upload.
+	defaultPartSize = 64 * 1024 * 1024
+
+	// defaultMaxRetries is the default number of retries of a part.
+	defaultMaxRetries = 3
+)
+
+// retryDelay is how long the first retry of a part waits, doubling with each
+// retry.  It is a variable so tests can shorten it.
+var retryDelay = time.Second
+
+// partSize returns the size of the parts of a multipart upload.
+func (a *Archiver) partSize() int64 {
+	if a.PartSize > 0 {
+		return a.PartSize
+	}
+	return defaultPartSize
+}
+
+// maxRetries returns the number of times the upload of a part is retried.
+func (a *Archiver) maxRetries() int {
+	if a.MaxRetries > 0 {
+		return a.MaxRetries
+	}
+	return defaultMaxRetries
+}
+
+// completedPart is a part of a multipart upload, as listed to complete it.
+type completedPart struct {
+	PartNumber int
+	ETag       string
+}
+
+// uploadMultipart uploads the file as the object with the given key in parts
+// of PartSize.  It resumes an upload of the object that is in progress, so
+// parts uploaded by an earlier attempt with the same content aren't uploaded
+// again.  An upload that fails is left in progress to be resumed; a lifecycle
+// rule of the bucket should abort incomplete uploads eventually.
+func (a *Archiver) uploadMultipart(ctx context.Context, f *os.File, key string, size int64) error {
+	id, uploaded, err := a.resumeUpload(ctx, key)
+	if err != nil {
+		return fmt.Errorf("can't resume upload of %s: %s", key, err)
+	}
+	if id == "" {
+		if id, err = a.createUpload(ctx, key, f.Name()); err != nil {
+			return fmt.Errorf("can't start upload of %s: %s", key, err)
+		}
+	}
+
+	partSize := a.partSize()
+	var parts []completedPart
+	for n, off := 1, int64(0); off < size; n, off = n+1, off+partSize {
+		length := partSize
+		if size-off < length {
+			length = size - off
+		}
+		section := io.NewSectionReader(f, off, length)
+		md5Sum, sha256Sum, err := hashPart(section)
+		if err != nil {
+			return fmt.Errorf("can't read file to upload: %s", err)
+		}
+		etag := `"` + hex.EncodeToString(md5Sum) + `"`
+		if uploaded[n] != etag {
+			if err := a.uploadPart(ctx, section, key, id, n, md5Sum, sha256Sum); err != nil {
+				return fmt.Errorf("can't upload part %d of %s: %s", n, key, err)
+			}
+		}
+		parts = append(parts, completedPart{n, etag})
+	}
+
+	if err := a.completeUpload(ctx, key, id, parts); err != nil {
+		return fmt.Errorf("can't complete upload of %s: %s", key, err)
+	}
+	return nil
+}
+
+// hashPart returns the MD5 and SHA-256 hashes of the part, and rewinds it.
+func hashPart(part *io.SectionReader) (md5Sum, sha256Sum []byte, err error) {
+	hMD5, hSHA256 := md5.New(), sha256.New()
+	if _, err := io.Copy(io.MultiWriter(hMD5, hSHA256), part); err != nil {
+		return nil, nil, err
+	}
+	if _, err := part.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+	return hMD5.Sum(nil), hSHA256.Sum(nil), nil
+}
+
+// uploadPart uploads a part of a multipart upload, retrying it if it fails or
+// if the ETag S3 returns doesn't match its MD5 hash.
+func (a *Archiver) uploadPart(ctx context.Context, part *io.SectionReader, key, id string, n int, md5Sum, sha256Sum []byte) error {
+	etag := `"` + hex.EncodeToString(md5Sum) + `"`
+	query := url.Values{"partNumber": {strconv.Itoa(n)}, "uploadId": {id}}
+	delay := retryDelay
+	var err error
+	for attempt := 0; attempt <= a.maxRetries(); attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+		if _, err = part.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		var req *http.Request
+		req, err = http.NewRequest(http.MethodPut, a.objectURL(key)+"?"+query.Encode(), part)
+		if err != nil {
+			return err
+		}
+		req.ContentLength = part.Size()
+		req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(md5Sum))
+		var resp *http.Response
+		resp, err = a.do(ctx, req, hex.EncodeToString(sha256Sum))
+		if err != nil {
+			continue
+		}
+		resp.Body.Close()
+		if got := resp.Header.Get("ETag"); got != etag {
+			err = fmt.Errorf("expected ETag %s, got %s", etag, got)
+			continue
+		}
+		return nil
+	}
+	return err
+}
+
+// createUpload starts a multipart upload of the named file as the object with
+// the given key, and returns its ID.
+func (a *Archiver) createUpload(ctx context.Context, key, name string) (string, error) {
+	req, err := http.NewRequest(http.MethodPost, a.objectURL(key)+"?uploads", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(name))
+	if a.StorageClass != "" {
+		req.Header.Set("X-Amz-Storage-Class", a.StorageClass)
+	}
+	var result struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := a.doXML(ctx, req, nil, &result); err != nil {
+		return "", err
+	}
+	if result.UploadID == "" {
+		return "", errors.New("no upload ID in the response")
+	}
+	return result.UploadID, nil
+}
+
+// resumeUpload returns the ID of the most recently started multipart upload
+// of the object with the given key that is still in progress, if any, along
+// with the ETags of its parts by part number.
+func (a *Archiver) resumeUpload(ctx context.Context, key string) (string, map[int]string, error) {
+	query := url.Values{"uploads": {""}, "prefix": {key}}
+	req, err := http.NewRequest(http.MethodGet, a.endpoint()+"/"+a.Bucket+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var uploads struct {
+		Uploads []struct {
+			Key       string
+			UploadID  string `xml:"UploadId"`
+			Initiated time.Time
+		} `xml:"Upload"`
+	}
+	if err := a.doXML(ctx, req, nil, &uploads); err != nil {
+		return "", nil, err
+	}
+	var id string
+	var initiated time.Time
+	for _, u := range uploads.Uploads {
+		if u.Key == key && (id == "" || u.Initiated.After(initiated)) {
+			id, initiated = u.UploadID, u.Initiated
+		}
+	}
+	if id == "" {
+		return "", nil, nil
+	}
+
+	query = url.Values{"uploadId": {id}}
+	req, err = http.NewRequest(http.MethodGet, a.objectURL(key)+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", nil, err
+	}
+	var parts struct {
+		Parts []completedPart `xml:"Part"`
+	}
+	if err := a.doXML(ctx, req, nil, &parts); err != nil {
+		return "", nil, err
+	}
+	uploaded := make(map[int]string, len(parts.Parts))
+	for _, p := range parts.Parts {
+		uploaded[p.PartNumber] = p.ETag
+	}
+	return id, uploaded, nil
+}
+
+// completeUpload completes a multipart upload with the given parts.
+func (a *Archiver) completeUpload(ctx context.Context, key, id string, parts []completedPart) error {
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name        `xml:"CompleteMultipartUpload"`
+		Parts   []completedPart `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+	query := url.Values{"uploadId": {id}}
+	req, err := http.NewRequest(http.MethodPost, a.objectURL(key)+"?"+query.Encode(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	// S3 can report an error after it has sent a successful status.
+	var result struct {
+		XMLName xml.Name
+		Code    string
+		Message string
+	}
+	if err := a.doXML(ctx, req, body, &result); err != nil {
+		return err
+	}
+	if result.XMLName.Local == "Error" {
+		return fmt.Errorf("%s: %s", result.Code, result.Message)
+	}
+	return nil
+}
+
+// doXML sends the request with the given body, and decodes the XML response
+// into v.
+func (a *Archiver) doXML(ctx context.Context, req *http.Request, body []byte, v interface{}) error {
+	h := sha256.Sum256(body)
+	resp, err := a.do(ctx, req, hex.EncodeToString(h[:]))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := xml.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("can't decode response: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+// Package s3 provides a lumberjack.Archiver that uploads backups to Amazon S3,
+// or to a service compatible with it, such as MinIO.
+//
+// It only uses the standard library, signing its requests with AWS Signature
+// Version 4 itself, so that importing it doesn't pull in the AWS SDK.
+// Backups larger than PartSize are uploaded in parts, each of which is retried
+// on its own and checked against the checksum S3 returns, and an upload that
+// fails is resumed from the parts already uploaded the next time the backup
+// is archived.
+package s3
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2"
+)
+
+func init() {
+	lumberjack.RegisterFeature("archiver/s3")
+}
+
+// sidecarSuffixes returns the suffixes of the files that go with a backup,
+// which are uploaded along with it if they exist.
+func sidecarSuffixes() []string {
+	suffixes := []string{".meta", ".sig"}
+	for _, name := range lumberjack.GetCapabilities().Checksums {
+		suffixes = append(suffixes, "."+name)
+	}
+	return suffixes
+}
+
+// Archiver uploads backups to an S3 bucket.  It can be used as the Archiver of
+// a lumberjack.Logger:
+//
+//	l := &lumberjack.Logger{
+//		Filename:       "/var/log/myapp/foo.log",
+//		Archiver:       &s3.Archiver{Bucket: "logs", Prefix: "myapp/"},
+//		DeleteArchived: true,
+//	}
+//
+// Credentials and the region are taken from the usual AWS environment
+// variables unless set.  Its fields must not be changed once it is in use.
+type Archiver struct {
+	// Bucket is the name of the bucket to upload backups to.
+	Bucket string
+
+	// Prefix is prepended to the file name of a backup to form the key of
+	// the object it is uploaded as, e.g. "myapp/" for myapp/foo-2020-01-01T00-00-00.000.log.gz.
+	Prefix string
+
+	// Region is the region of the bucket.  The default is the value of the
+	// AWS_REGION environment variable, or else us-east-1.
+	Region string
+
+	// Endpoint is the URL of the S3 service, for services compatible with S3.
+	// Objects are addressed with the bucket in the path.  The default is the
+	// endpoint of Amazon S3 for Region.
+	Endpoint string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are the credentials to
+	// sign requests with.  The defaults are the values of the
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+	// environment variables.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// StorageClass, if set, is the storage class of the uploaded objects,
+	// e.g. STANDARD_IA.
+	StorageClass string
+
+	// PartSize is the size of the parts backups larger than it are uploaded
+	// in, in bytes.  S3 rejects parts smaller than 5 MB, and uploads of more
+	// than 10,000 parts.  The default is 64 MB.
+	PartSize int64
+
+	// MaxRetries is the number of times the upload of a part is retried
+	// before the upload is given up on.  The default is 3.
+	MaxRetries int
+
+	// Client is the HTTP client used to upload backups.  The default is
+	// http.DefaultClient.
+	Client *http.Client
+}
+
+// Archive implements lumberjack.Archiver.  It uploads the backup, and the
+// metadata, signature and checksum files next to it if there are any.
+func (a *Archiver) Archive(ctx context.Context, name string) error {
+	if err := a.upload(ctx, name); err != nil {
+		return err
+	}
+	for _, suffix := range sidecarSuffixes() {
+		if _, err := os.Stat(name + suffix); os.IsNotExist(err) {
+			continue
+		}
+		if err := a.upload(ctx, name+suffix); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// upload uploads the named file as an object, in parts if it is larger than
+// PartSize.
+func (a *Archiver) upload(ctx context.Context, name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("can't open file to upload: %s", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("can't stat file to upload: %s", err)
+	}
+	key := a.Prefix + filepath.Base(name)
+	if fi.Size() > a.partSize() {
+		return a.uploadMultipart(ctx, f, key, fi.Size())
+	}
+
+	// the payload is hashed first, so it can be streamed to S3 unsigned.
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Errorf("can't read file to upload: %s", err)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("can't read file to upload: %s", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, a.objectURL(key), f)
+	if err != nil {
+		return fmt.Errorf("can't create upload request: %s", err)
+	}
+	req.ContentLength = size
+	req.Header.Set("Content-Type", contentType(name))
+	if a.StorageClass != "" {
+		req.Header.Set("X-Amz-Storage-Class", a.StorageClass)
+	}
+	resp, err := a.do(ctx, req, hex.EncodeToString(h.Sum(nil)))
+	if err != nil {
+		return fmt.Errorf("can't upload %s: %s", key, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// do signs and sends the request, whose body has the given hex encoded
+// SHA-256 hash, and returns the response if it is successful.  The caller
+// must close its body.
+func (a *Archiver) do(ctx context.Context, req *http.Request, payloadHash string) (*http.Response, error) {
+	req = req.WithContext(ctx)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	a.credentials().sign(req, "s3", a.region(), payloadHash, time.Now())
+
+	client := a.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return resp, nil
+}
+
+// objectURL returns the URL of the object with the given key.
+func (a *Archiver) objectURL(key string) string {
+	return a.endpoint() + "/" + a.Bucket + "/" + key
+}
+
+// region returns the region of the bucket.
+func (a *Archiver) region() string {
+	if a.Region != "" {
+		return a.Region
+	}
+	if r := os.Getenv("AWS_REGION"); r != "" {
+		return r
+	}
+	return "us-east-1"
+}
+
+// endpoint returns the URL of the S3 service, without a trailing slash.
+func (a *Archiver) endpoint() string {
+	if a.Endpoint != "" {
+		return strings.TrimSuffix(a.Endpoint, "/")
+	}
+	return "https://s3." + a.region() + ".amazonaws.com"
+}
+
+// credentials returns the credentials to sign requests with.
+func (a *Archiver) credentials() credentials {
+	c := credentials{a.AccessKeyID, a.SecretAccessKey, a.SessionToken}
+	if c.accessKeyID == "" && c.secretAccessKey == "" {
+		c = credentials{
+			os.Getenv("AWS_ACCESS_KEY_ID"),
+			os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			os.Getenv("AWS_SESSION_TOKEN"),
+		}
+	}
+	return c
+}
+
+// contentType returns the content type to upload the named file with.
+func contentType(name string) string {
+	switch path.Ext(name) {
+	case ".gz":
+		return "application/gzip"
+	case ".meta":
+		return "application/json"
+	case ".log", ".txt":
+		return "text/plain; charset=utf-8"
+	}
+	return "application/octet-stream"
+}
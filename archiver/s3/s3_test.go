@@ -0,0 +1,295 @@
+package s3
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2"
+)
+
+func TestSign(t *testing.T) {
+	// the example from the AWS documentation of Signature Version 4.
+	req, err := http.NewRequest("GET", "https://iam.amazonaws.com/?Action=ListUsers&Version=2010-05-08", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded; charset=utf-8")
+	c := credentials{accessKeyID: "AKIDEXAMPLE", secretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"}
+	emptyHash := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	c.sign(req, "iam", "us-east-1", emptyHash, time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC))
+
+	exp := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/iam/aws4_request, " +
+		"SignedHeaders=content-type;host;x-amz-date, " +
+		"Signature=5d672d79c15b13162d9279b0855cfba6789a8edb4c82c400e06b5924a6f2b5d7"
+	if got := req.Header.Get("Authorization"); got != exp {
+		t.Fatalf("expected Authorization %q, got %q", exp, got)
+	}
+}
+
+// fakeS3 records the objects put to it, uploaded whole or in parts.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	auth    []string
+
+	// uploads holds the parts of the multipart uploads in progress, by
+	// upload ID.
+	uploads map[string]*fakeUpload
+	// failParts is the number of times the upload of each part fails
+	// before it succeeds, by part number.
+	failParts map[int]int
+	// partPuts counts the uploads of parts.
+	partPuts int
+}
+
+type fakeUpload struct {
+	path  string
+	parts map[int]string
+}
+
+func (s *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "bad request", http.StatusBadRequest)
+		return
+	}
+	if strings.HasPrefix(r.URL.Path, "/logs/denied/") {
+		http.Error(w, "AccessDenied", http.StatusForbidden)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.objects == nil {
+		s.objects = make(map[string]string)
+		s.uploads = make(map[string]*fakeUpload)
+	}
+	s.auth = append(s.auth, r.Header.Get("Authorization"))
+
+	query := r.URL.Query()
+	_, uploads := query["uploads"]
+	id := query.Get("uploadId")
+	switch {
+	case r.Method == http.MethodPut && id == "":
+		s.objects[r.URL.Path] = string(b)
+	case r.Method == http.MethodPost && uploads:
+		id := fmt.Sprint("upload-", len(s.uploads)+1)
+		s.uploads[id] = &fakeUpload{path: r.URL.Path, parts: make(map[int]string)}
+		fmt.Fprintf(w, "<InitiateMultipartUploadResult><UploadId>%s</UploadId></InitiateMultipartUploadResult>", id)
+	case r.Method == http.MethodGet && uploads:
+		fmt.Fprint(w, "<ListMultipartUploadsResult>")
+		for id, u := range s.uploads {
+			if key := strings.TrimPrefix(u.path, "/logs/"); strings.HasPrefix(key, query.Get("prefix")) {
+				fmt.Fprintf(w, "<Upload><Key>%s</Key><UploadId>%s</UploadId></Upload>", key, id)
+			}
+		}
+		fmt.Fprint(w, "</ListMultipartUploadsResult>")
+	case s.uploads[id] == nil:
+		http.Error(w, "NoSuchUpload", http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		s.partPuts++
+		n, _ := strconv.Atoi(query.Get("partNumber"))
+		if s.failParts[n] > 0 {
+			s.failParts[n]--
+			http.Error(w, "InternalError", http.StatusInternalServerError)
+			return
+		}
+		s.uploads[id].parts[n] = string(b)
+		w.Header().Set("ETag", fmt.Sprintf(`"%x"`, md5.Sum(b)))
+	case r.Method == http.MethodGet:
+		fmt.Fprint(w, "<ListPartsResult>")
+		for n, part := range s.uploads[id].parts {
+			fmt.Fprintf(w, `<Part><PartNumber>%d</PartNumber><ETag>"%x"</ETag></Part>`, n, md5.Sum([]byte(part)))
+		}
+		fmt.Fprint(w, "</ListPartsResult>")
+	case r.Method == http.MethodPost:
+		var complete struct {
+			Parts []completedPart `xml:"Part"`
+		}
+		if err := xml.Unmarshal(b, &complete); err != nil {
+			http.Error(w, "MalformedXML", http.StatusBadRequest)
+			return
+		}
+		var object strings.Builder
+		for i, p := range complete.Parts {
+			part, ok := s.uploads[id].parts[p.PartNumber]
+			if !ok || p.PartNumber != i+1 || p.ETag != fmt.Sprintf(`"%x"`, md5.Sum([]byte(part))) {
+				fmt.Fprint(w, "<Error><Code>InvalidPart</Code><Message>bad part</Message></Error>")
+				return
+			}
+			object.WriteString(part)
+		}
+		s.objects[s.uploads[id].path] = object.String()
+		delete(s.uploads, id)
+		fmt.Fprint(w, "<CompleteMultipartUploadResult></CompleteMultipartUploadResult>")
+	default:
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "TestArchive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "foo.log")
+	if err := ioutil.WriteFile(name, []byte("boo!"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(name+".meta", []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := &fakeS3{}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	a := &Archiver{
+		Bucket:          "logs",
+		Prefix:          "myapp/",
+		Region:          "eu-west-1",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+	}
+	if err := a.Archive(context.Background(), name); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := s.objects["/logs/myapp/foo.log"]; got != "boo!" {
+		t.Fatalf("expected the backup to be uploaded, got %q", got)
+	}
+	if got := s.objects["/logs/myapp/foo.log.meta"]; got != "{}" {
+		t.Fatalf("expected the metadata to be uploaded, got %q", got)
+	}
+	if len(s.objects) != 2 {
+		t.Fatalf("expected 2 objects, got %v", s.objects)
+	}
+	for _, auth := range s.auth {
+		if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/") || !strings.Contains(auth, "/eu-west-1/s3/aws4_request") {
+			t.Fatalf("unexpected Authorization %q", auth)
+		}
+	}
+
+	a.Prefix = "denied/"
+	err = a.Archive(context.Background(), name)
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected the upload to be denied, got %v", err)
+	}
+}
+
+func TestArchiveMultipart(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	dir, err := ioutil.TempDir("", "TestArchiveMultipart")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "foo.log")
+	content := "0123456789abcdefghij!"
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second part fails twice before it goes through.
+	s := &fakeS3{failParts: map[int]int{2: 2}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	a := &Archiver{
+		Bucket:          "logs",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		PartSize:        10,
+	}
+	if err := a.Archive(context.Background(), name); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.objects["/logs/foo.log"]; got != content {
+		t.Fatalf("expected the backup to be uploaded in parts, got %q", got)
+	}
+	if s.partPuts != 5 {
+		t.Fatalf("expected 3 parts and 2 retries to be put, got %d puts", s.partPuts)
+	}
+	if len(s.uploads) != 0 {
+		t.Fatalf("expected no upload in progress, got %v", s.uploads)
+	}
+}
+
+func TestArchiveMultipartResume(t *testing.T) {
+	defer func(d time.Duration) { retryDelay = d }(retryDelay)
+	retryDelay = time.Millisecond
+
+	dir, err := ioutil.TempDir("", "TestArchiveMultipartResume")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "foo.log")
+	content := "0123456789abcdefghij!"
+	if err := ioutil.WriteFile(name, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// the second part fails more often than it is retried.
+	s := &fakeS3{failParts: map[int]int{2: 3}}
+	server := httptest.NewServer(s)
+	defer server.Close()
+
+	a := &Archiver{
+		Bucket:          "logs",
+		Endpoint:        server.URL,
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "secret",
+		PartSize:        10,
+		MaxRetries:      2,
+	}
+	err = a.Archive(context.Background(), name)
+	if err == nil || !strings.Contains(err.Error(), "part 2") {
+		t.Fatalf("expected the upload of part 2 to fail, got %v", err)
+	}
+	if len(s.uploads) != 1 || len(s.objects) != 0 {
+		t.Fatalf("expected the upload to be left in progress, got %v and %v", s.uploads, s.objects)
+	}
+
+	// archiving it again only uploads the parts that are missing.
+	s.partPuts = 0
+	if err := a.Archive(context.Background(), name); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.objects["/logs/foo.log"]; got != content {
+		t.Fatalf("expected the upload to be resumed, got %q", got)
+	}
+	if s.partPuts != 2 {
+		t.Fatalf("expected only the parts missing to be put, got %d puts", s.partPuts)
+	}
+}
+
+func TestFeature(t *testing.T) {
+	for _, f := range lumberjack.GetCapabilities().Features {
+		if f == "archiver/s3" {
+			return
+		}
+	}
+	t.Fatal("expected archiver/s3 among the features")
+}
+
+var _ lumberjack.Archiver = (*Archiver)(nil)
@@ -0,0 +1,254 @@
+package lumberjack
+
+import (
+	"errors"
+	"sync"
+)
+
+// ErrDropped is returned by AsyncLogger.Write when the write is dropped
+// because the buffer is full and the DropPolicy is DropNewest.
+var ErrDropped = errors.New("lumberjack: write dropped because the buffer is full")
+
+// DropPolicy determines what an AsyncLogger does with a write that doesn't fit
+// in its buffer.
+type DropPolicy string
+
+const (
+	// Block makes the write wait until there is room in the buffer.  No writes
+	// are lost, but Write is only fast as long as the disk keeps up.
+	Block DropPolicy = ""
+
+	// DropNewest drops the write, which returns ErrDropped.
+	DropNewest DropPolicy = "newest"
+
+	// DropOldest drops the oldest buffered writes until the write fits.
+	DropOldest DropPolicy = "oldest"
+)
+
+// AsyncLogger is a Logger that writes to the log file in the background, so
+// that Write only has to copy its data to an in-memory ring buffer and doesn't
+// wait for the disk, or for the log file to be rotated.  Each write ends up in
+// the log file as a whole, in the order of the calls to Write.  If the buffer
+// fills up, the DropPolicy decides whether Write waits or writes are lost.
+//
+// Errors writing to the log file are returned by the next call to Flush, Sync
+// or Close.
+type AsyncLogger struct {
+	*Logger
+	policy DropPolicy
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	// ring holds used bytes starting at head, wrapping around at the end, and
+	// lens the lengths of the writes they belong to, oldest first.
+	ring []byte
+	head int
+	used int
+	lens []int
+
+	// running and busy report whether the goroutine writing the buffered data
+	// to the log file is running and writing; stopping asks it to stop once
+	// the buffer is empty.
+	running  bool
+	busy     bool
+	stopping bool
+	err      error
+
+	writes  int64
+	dropped int64
+
+	// batch and batchLens are only used by the writing goroutine.
+	batch     []byte
+	batchLens []int
+}
+
+// NewAsyncLogger returns an AsyncLogger writing to l through a buffer of size
+// bytes, or of 4096 bytes if size is 0.  Writes larger than the buffer are
+// written to the log file right away, once everything buffered before them is.
+// The Logger must only be written to through the AsyncLogger from then on.
+func NewAsyncLogger(l *Logger, size int, policy DropPolicy) *AsyncLogger {
+	if size <= 0 {
+		size = defaultBufferSize
+	}
+	a := &AsyncLogger{Logger: l, policy: policy, ring: make([]byte, size)}
+	a.cond = sync.NewCond(&a.mu)
+	return a
+}
+
+// Write implements io.Writer.  It copies p to the buffer and returns, unless
+// the buffer is full.
+func (a *AsyncLogger) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.writes++
+
+	if len(p) > len(a.ring) {
+		a.drain()
+		l := a.Logger
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.writeFiltered(p, l.write)
+	}
+
+	droppedOldest := false
+	for a.used+len(p) > len(a.ring) {
+		switch a.policy {
+		case DropNewest:
+			a.dropped++
+			a.Logger.recordDrop(DropBufferFull, len(p))
+			return 0, ErrDropped
+		case DropOldest:
+			a.Logger.recordDrop(DropBufferFull, a.lens[0])
+			a.head = (a.head + a.lens[0]) % len(a.ring)
+			a.used -= a.lens[0]
+			a.lens = a.lens[1:]
+			a.dropped++
+			droppedOldest = true
+		default:
+			a.cond.Wait()
+		}
+	}
+
+	tail := (a.head + a.used) % len(a.ring)
+	n := copy(a.ring[tail:], p)
+	copy(a.ring, p[n:])
+	a.used += len(p)
+	a.lens = append(a.lens, len(p))
+	if !droppedOldest {
+		a.Logger.endDrops(DropBufferFull)
+	}
+
+	if !a.running {
+		a.running = true
+		go a.run()
+	}
+	a.cond.Broadcast()
+	return len(p), nil
+}
+
+// Flush waits until everything written so far is written to the log file,
+// and returns the first error writing to the log file since the last call to
+// Flush, Sync or Close.
+func (a *AsyncLogger) Flush() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.drain()
+	err := a.err
+	a.err = nil
+	return err
+}
+
+// Sync is like Flush, but also commits the log file to stable storage like
+// Logger.Sync.
+func (a *AsyncLogger) Sync() error {
+	err := a.Flush()
+	if errSync := a.Logger.Sync(); err == nil {
+		err = errSync
+	}
+	return err
+}
+
+// Rotate writes everything written so far to the log file, and then rotates
+// it like Logger.Rotate.
+func (a *AsyncLogger) Rotate() error {
+	err := a.Flush()
+	if errRotate := a.Logger.Rotate(); err == nil {
+		err = errRotate
+	}
+	return err
+}
+
+// Close writes everything written so far to the log file, stops writing in
+// the background, and closes the log file like Logger.Close.  A later Write
+// starts writing in the background again.
+func (a *AsyncLogger) Close() error {
+	a.mu.Lock()
+	a.stopping = true
+	a.cond.Broadcast()
+	for a.running {
+		a.cond.Wait()
+	}
+	a.stopping = false
+	err := a.err
+	a.err = nil
+	a.mu.Unlock()
+
+	if errClose := a.Logger.Close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// Stats returns statistics about the writes made by the AsyncLogger.
+func (a *AsyncLogger) Stats() Stats {
+	s := a.Logger.Stats()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	s.Writes += a.writes
+	s.Dropped += a.dropped
+	return s
+}
+
+// drain waits until the buffer is empty and nothing is being written.  It is
+// called with a.mu held.
+func (a *AsyncLogger) drain() {
+	for a.used > 0 || a.busy {
+		a.cond.Wait()
+	}
+}
+
+// run writes the buffered data to the log file until asked to stop.
+func (a *AsyncLogger) run() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for {
+		for a.used == 0 && !a.stopping {
+			a.cond.Wait()
+		}
+		if a.used == 0 {
+			a.running = false
+			a.cond.Broadcast()
+			return
+		}
+
+		// take everything buffered, to write it without holding the lock.
+		end := a.head + a.used
+		if end > len(a.ring) {
+			end = len(a.ring)
+		}
+		a.batch = append(a.batch[:0], a.ring[a.head:end]...)
+		a.batch = append(a.batch, a.ring[:a.used-len(a.batch)]...)
+		a.batchLens = append(a.batchLens[:0], a.lens...)
+		a.head, a.used, a.lens = 0, 0, a.lens[:0]
+		a.busy = true
+		a.cond.Broadcast()
+
+		a.mu.Unlock()
+		err := a.writeBatch()
+		a.mu.Lock()
+
+		a.busy = false
+		if a.err == nil {
+			a.err = err
+		}
+		a.cond.Broadcast()
+	}
+}
+
+// writeBatch writes the writes taken from the buffer to the log file, one at
+// a time, so that each of them ends up in a single log file.
+func (a *AsyncLogger) writeBatch() error {
+	l := a.Logger
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var err error
+	p := a.batch
+	for _, n := range a.batchLens {
+		if _, errWrite := l.writeFiltered(p[:n], l.write); err == nil {
+			err = errWrite
+		}
+		p = p[n:]
+	}
+	return err
+}
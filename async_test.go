@@ -0,0 +1,184 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+// stallAsync locks the Logger of a, and waits until the AsyncLogger's
+// goroutine has taken the buffered data and is waiting to write it.
+func stallAsync(a *AsyncLogger, t testing.TB) {
+	a.Logger.mu.Lock()
+	_, err := a.Write([]byte("stall"))
+	isNilUp(err, t, 1)
+	a.mu.Lock()
+	for !a.busy {
+		a.cond.Wait()
+	}
+	a.mu.Unlock()
+}
+
+func TestAsyncLogger(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncLogger", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}, 10, Block)
+	defer a.Close()
+
+	for _, s := range []string{"boo!", "foo!", "bar!"} {
+		n, err := a.Write([]byte(s))
+		isNil(err, t)
+		equals(4, n, t)
+	}
+	isNil(a.Flush(), t)
+	existsWithContent(filename, []byte("boo!foo!bar!"), t)
+
+	// writes larger than the buffer go straight through, after the buffer.
+	_, err := a.Write([]byte("baz!"))
+	isNil(err, t)
+	_, err = a.Write([]byte("0123456789!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!foo!bar!baz!0123456789!"), t)
+
+	isNil(a.Sync(), t)
+	stats := a.Stats()
+	equals(int64(5), stats.Writes, t)
+	equals(int64(0), stats.Dropped, t)
+}
+
+func TestAsyncLoggerBlock(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncLoggerBlock", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}, 8, Block)
+	defer a.Close()
+
+	stallAsync(a, t)
+	_, err := a.Write([]byte("boo!boo!"))
+	isNil(err, t)
+
+	written := make(chan struct{})
+	go func() {
+		_, err := a.Write([]byte("foo!"))
+		isNil(err, t)
+		close(written)
+	}()
+	select {
+	case <-written:
+		t.Fatal("expected the write to wait for room in the buffer")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	a.Logger.mu.Unlock()
+	<-written
+	isNil(a.Flush(), t)
+	existsWithContent(filename, []byte("stallboo!boo!foo!"), t)
+}
+
+func TestAsyncLoggerDropNewest(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncLoggerDropNewest", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}, 8, DropNewest)
+	defer a.Close()
+
+	stallAsync(a, t)
+	for _, s := range []string{"boo!", "foo!"} {
+		_, err := a.Write([]byte(s))
+		isNil(err, t)
+	}
+	n, err := a.Write([]byte("bar!"))
+	equals(ErrDropped, err, t)
+	equals(0, n, t)
+
+	a.Logger.mu.Unlock()
+	isNil(a.Flush(), t)
+	existsWithContent(filename, []byte("stallboo!foo!"), t)
+	equals(int64(1), a.Stats().Dropped, t)
+}
+
+func TestAsyncLoggerDropOldest(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncLoggerDropOldest", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}, 10, DropOldest)
+	defer a.Close()
+
+	stallAsync(a, t)
+	for _, s := range []string{"boo!", "foo!", "barbazqux"} {
+		_, err := a.Write([]byte(s))
+		isNil(err, t)
+	}
+
+	// the two oldest writes made room for the last one, which wraps around
+	// the end of the buffer.
+	a.Logger.mu.Unlock()
+	isNil(a.Flush(), t)
+	existsWithContent(filename, []byte("stallbarbazqux"), t)
+	equals(int64(2), a.Stats().Dropped, t)
+}
+
+func TestAsyncLoggerRotateAndClose(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncLoggerRotateAndClose", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}, 100, Block)
+	defer a.Close()
+
+	_, err := a.Write([]byte("boo!"))
+	isNil(err, t)
+	newFakeTime()
+	isNil(a.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+
+	_, err = a.Write([]byte("foo!"))
+	isNil(err, t)
+	isNil(a.Close(), t)
+	existsWithContent(filename, []byte("foo!"), t)
+	a.mu.Lock()
+	running := a.running
+	a.mu.Unlock()
+	assert(!running, t, "expected no goroutine writing after Close")
+
+	// writes after Close reopen the log file.
+	_, err = a.Write([]byte("bar!"))
+	isNil(err, t)
+	isNil(a.Flush(), t)
+	existsWithContent(filename, []byte("foo!bar!"), t)
+}
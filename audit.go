@@ -0,0 +1,69 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// auditFilter returns a func reporting that no backup may be removed, or nil
+// if AuditMode isn't set.
+func (l *Logger) auditFilter() func(logInfo) bool {
+	if !l.AuditMode {
+		return nil
+	}
+	return func(logInfo) bool { return false }
+}
+
+// seal moves a finalized backup, along with the files that go with it, into
+// the subdirectory of its directory for the day it was rotated, and makes
+// them read-only.  It returns the new name of the backup.
+func (l *Logger) seal(name string) (string, error) {
+	t := l.now()
+	if f, err := l.findBackup(name); err == nil && !f.timestamp.IsZero() {
+		t = f.timestamp
+	}
+	t = t.In(l.location())
+	dir := filepath.Join(filepath.Dir(name), t.Format("2006"), t.Format("01"), t.Format("02"))
+	if err := l.mkdirAll(dir); err != nil {
+		return "", fmt.Errorf("can't make directory for sealed backup: %s", err)
+	}
+
+	sealed := filepath.Join(dir, filepath.Base(name))
+	if _, err := os_Stat(sealed); err == nil {
+		return "", fmt.Errorf("can't seal backup %s: %s already exists", name, sealed)
+	}
+	if err := sealFile(name, sealed); err != nil {
+		return "", err
+	}
+	// the files that go with the backup are best effort, most backups won't
+	// have all of them.
+	extras := []string{metadataName(name), name + signatureSuffix}
+	for _, algorithm := range checksumNames() {
+		extras = append(extras, name+"."+algorithm)
+	}
+	for _, extra := range extras {
+		if _, err := os_Stat(extra); err != nil {
+			continue
+		}
+		if err := sealFile(extra, filepath.Join(dir, filepath.Base(extra))); err != nil {
+			return sealed, err
+		}
+	}
+	return sealed, nil
+}
+
+// sealFile moves src to dst and removes its write permissions.
+func sealFile(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		return fmt.Errorf("can't move backup to seal it: %s", err)
+	}
+	info, err := os_Stat(dst)
+	if err != nil {
+		return fmt.Errorf("can't seal backup: %s", err)
+	}
+	if err := os.Chmod(dst, info.Mode().Perm()&^0222); err != nil {
+		return fmt.Errorf("can't make backup read-only: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuditMode(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAuditMode", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		Compress:   true,
+		Checksum:   "sha256",
+		AuditMode:  true,
+	}
+	defer l.Close()
+
+	var sealed []string
+	for _, s := range []string{"boo!", "foo!", "moo!"} {
+		_, err := l.Write([]byte(s))
+		isNil(err, t)
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		isNil(l.Mill(context.Background()), t)
+
+		// the backup goes to the directory of the day it was rotated.
+		now := fakeTime()
+		day := filepath.Join(dir, now.Format("2006"), now.Format("01"), now.Format("02"))
+		name := filepath.Join(day, filepath.Base(backupFile(dir))+compressSuffix)
+		exists(name, t)
+		exists(name+".sha256", t)
+		sealed = append(sealed, name)
+	}
+
+	// MaxBackups doesn't remove any of them, and they can't be written to.
+	for _, name := range sealed {
+		info, err := os.Stat(name)
+		isNil(err, t)
+		equals(os.FileMode(0), info.Mode().Perm()&0222, t)
+	}
+	equals(int64(0), l.Stats().Removed, t)
+
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(0, len(backups), t)
+}
@@ -0,0 +1,48 @@
+package lumberjack
+
+import (
+	"time"
+)
+
+// defaultMaxOpenRetryBackoff is the longest wait between attempts to open the
+// log file if MaxOpenRetryBackoff isn't set.
+const defaultMaxOpenRetryBackoff = time.Minute
+
+// openBackoff returns the error of the last attempt to open the log file if
+// it's too soon to try again.
+func (l *Logger) openBackoff() error {
+	if l.openErr == nil || !l.now().Before(l.openRetry) {
+		return nil
+	}
+	return l.openErr
+}
+
+// openResult records the outcome of an attempt to open the log file, and
+// works out when to try again if it failed.
+func (l *Logger) openResult(err error) {
+	if err == nil {
+		l.openErr = nil
+		l.openFails = 0
+		return
+	}
+	l.openFails++
+	var wait time.Duration
+	if l.OpenRetryBackoff > 0 {
+		max := l.MaxOpenRetryBackoff
+		if max <= 0 {
+			max = defaultMaxOpenRetryBackoff
+		}
+		wait = l.OpenRetryBackoff
+		for i := 1; i < l.openFails && wait < max; i++ {
+			wait *= 2
+		}
+		if wait > max {
+			wait = max
+		}
+		l.openErr = err
+		l.openRetry = l.now().Add(wait)
+	}
+	if l.OnOpenError != nil {
+		l.OnOpenError(err, l.openFails, wait)
+	}
+}
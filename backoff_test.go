@@ -0,0 +1,50 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOpenRetryBackoff(t *testing.T) {
+	currentTime = fakeTime
+	dir := makeTempDir("TestOpenRetryBackoff", t)
+	defer os.RemoveAll(dir)
+
+	// the log file's directory is a file, so it can't be created.
+	notADir := filepath.Join(dir, "notadir")
+	err := ioutil.WriteFile(notADir, []byte("data"), 0644)
+	isNil(err, t)
+
+	var waits []time.Duration
+	l := &Logger{
+		Filename:            logFile(notADir),
+		OpenRetryBackoff:    time.Second,
+		MaxOpenRetryBackoff: 3 * time.Second,
+		OnOpenError: func(err error, failures int, retryIn time.Duration) {
+			equals(len(waits)+1, failures, t)
+			waits = append(waits, retryIn)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	for i := 0; i < 3; i++ {
+		_, err = l.Write(b)
+		notNil(err, t)
+		// writes before the retry time don't try to open the file again.
+		_, err = l.Write(b)
+		notNil(err, t)
+		fakeCurrentTime = fakeCurrentTime.Add(waits[len(waits)-1])
+	}
+	equals([]time.Duration{time.Second, 2 * time.Second, 3 * time.Second}, waits, t)
+
+	// once the problem is fixed, the next attempt succeeds.
+	isNil(os.Remove(notADir), t)
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	equals(0, l.openFails, t)
+}
@@ -0,0 +1,177 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// BackupInfo describes a backup file.
+type BackupInfo struct {
+	// Name is the base name of the backup file.
+	Name string
+
+	// Path is the full path of the backup file.
+	Path string
+
+	// Timestamp is the time encoded in the name of the backup, which is the
+	// time it was rotated.
+	Timestamp time.Time
+
+	// Size is the size of the backup file in bytes.
+	Size int64
+
+	// Compressed is true if the backup file is compressed.
+	Compressed bool
+
+	// Encrypted is true if the backup file is encrypted, see Encryption.
+	Encrypted bool
+
+	// RunID is the RunID of the Logger that created the backup, if it was set
+	// and the backup has a metadata file.
+	RunID string
+}
+
+// Backups returns the backups of the log file, newest first, narrowed down
+// and ordered by the given options.
+func (l *Logger) Backups(opts ...ListOption) ([]BackupInfo, error) {
+	var o listOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	l.mu.Lock()
+	files, err := l.oldLogFiles()
+	l.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	backups := []BackupInfo{}
+	for _, f := range files {
+		b := backupInfo(f)
+		if o.match(b) {
+			backups = append(backups, b)
+		}
+	}
+	if o.ascending {
+		for i, j := 0, len(backups)-1; i < j; i, j = i+1, j-1 {
+			backups[i], backups[j] = backups[j], backups[i]
+		}
+	}
+	if o.limit > 0 && len(backups) > o.limit {
+		backups = backups[:o.limit]
+	}
+	return backups, nil
+}
+
+// ListOption narrows down or orders the backups returned by Backups.
+type ListOption func(*listOptions)
+
+type listOptions struct {
+	from, to     time.Time
+	limit        int
+	ascending    bool
+	compressed   bool
+	uncompressed bool
+	runID        string
+}
+
+// Between only lists the backups with a timestamp in the range [from, to).  A
+// zero from or to leaves the range open on that side.
+func Between(from, to time.Time) ListOption {
+	return func(o *listOptions) {
+		o.from, o.to = from, to
+	}
+}
+
+// Limit lists at most n backups, counted in the order they're listed.
+func Limit(n int) ListOption {
+	return func(o *listOptions) {
+		o.limit = n
+	}
+}
+
+// Ascending lists the oldest backups first.
+func Ascending() ListOption {
+	return func(o *listOptions) {
+		o.ascending = true
+	}
+}
+
+// CompressedOnly only lists compressed backups.
+func CompressedOnly() ListOption {
+	return func(o *listOptions) {
+		o.compressed = true
+	}
+}
+
+// UncompressedOnly only lists uncompressed backups.
+func UncompressedOnly() ListOption {
+	return func(o *listOptions) {
+		o.uncompressed = true
+	}
+}
+
+// ForRun only lists the backups created by a Logger with the given RunID.
+func ForRun(runID string) ListOption {
+	return func(o *listOptions) {
+		o.runID = runID
+	}
+}
+
+// match reports whether b is listed.
+func (o listOptions) match(b BackupInfo) bool {
+	if !o.from.IsZero() && b.Timestamp.Before(o.from) {
+		return false
+	}
+	if !o.to.IsZero() && !b.Timestamp.Before(o.to) {
+		return false
+	}
+	if o.compressed && !b.Compressed {
+		return false
+	}
+	if o.uncompressed && b.Compressed {
+		return false
+	}
+	if o.runID != "" && b.RunID != o.runID {
+		return false
+	}
+	return true
+}
+
+// backupInfo returns the BackupInfo describing f.
+func backupInfo(f logInfo) BackupInfo {
+	b := BackupInfo{
+		Name:       f.Name(),
+		Path:       filepath.Join(f.dir, f.Name()),
+		Timestamp:  f.timestamp,
+		Size:       f.Size(),
+		Compressed: isCompressed(f.Name()),
+		Encrypted:  isEncrypted(f.Name()),
+	}
+	if meta, err := readMetadata(b.Path); err == nil {
+		b.RunID = meta.RunID
+	}
+	return b
+}
+
+// backupFileInfo presents a BackupInfo as an os.FileInfo, so backups that
+// only exist as descriptions can go through the same retention rules as the
+// ones on disk.
+type backupFileInfo struct {
+	b BackupInfo
+}
+
+func (fi backupFileInfo) Name() string {
+	if fi.b.Compressed && !isCompressed(fi.b.Name) {
+		return fi.b.Name + compressSuffix
+	}
+	return fi.b.Name
+}
+
+func (fi backupFileInfo) Size() int64        { return fi.b.Size }
+func (fi backupFileInfo) Mode() os.FileMode  { return 0 }
+func (fi backupFileInfo) ModTime() time.Time { return fi.b.Timestamp }
+func (fi backupFileInfo) IsDir() bool        { return false }
+func (fi backupFileInfo) Sys() interface{}   { return nil }
@@ -0,0 +1,124 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBackups", t)
+	defer os.RemoveAll(dir)
+
+	// four backups an hour apart, the two oldest compressed.
+	// names only keep milliseconds.
+	start := fakeTime().Truncate(time.Millisecond)
+	var times []time.Time
+	var names []string
+	for i := 0; i < 4; i++ {
+		ts := start.Add(time.Duration(i) * time.Hour)
+		name := backupFileWithTime(dir, ts)
+		if i < 2 {
+			name += compressSuffix
+		}
+		isNil(ioutil.WriteFile(name, []byte("foo"), 0644), t)
+		times = append(times, ts)
+		names = append(names, filepath.Base(name))
+	}
+	// not a backup.
+	isNil(ioutil.WriteFile(filepath.Join(dir, "foo.txt"), []byte("foo"), 0644), t)
+
+	l := &Logger{Filename: logFile(dir)}
+	defer l.Close()
+
+	list := func(opts ...ListOption) []string {
+		backups, err := l.Backups(opts...)
+		isNilUp(err, t, 1)
+		got := []string{}
+		for _, b := range backups {
+			got = append(got, b.Name)
+		}
+		return got
+	}
+
+	equals([]string{names[3], names[2], names[1], names[0]}, list(), t)
+	equals([]string{names[0], names[1], names[2], names[3]}, list(Ascending()), t)
+	equals([]string{names[3], names[2]}, list(Limit(2)), t)
+	equals([]string{names[0], names[1]}, list(Ascending(), Limit(2)), t)
+	equals([]string{names[2], names[1]}, list(Between(times[1], times[3])), t)
+	equals([]string{names[3], names[2]}, list(Between(times[2], time.Time{})), t)
+	equals([]string{names[0]}, list(Between(time.Time{}, times[1])), t)
+	equals([]string{names[1], names[0]}, list(CompressedOnly()), t)
+	equals([]string{names[3], names[2]}, list(UncompressedOnly()), t)
+	equals([]string{names[2]}, list(UncompressedOnly(), Ascending(), Limit(1)), t)
+	equals([]string{}, list(CompressedOnly(), UncompressedOnly()), t)
+
+	backups, err := l.Backups(Limit(1))
+	isNil(err, t)
+	equals(BackupInfo{
+		Name:      names[3],
+		Path:      filepath.Join(dir, names[3]),
+		Timestamp: times[3].UTC(),
+		Size:      3,
+	}, backups[0], t)
+}
+
+func TestBackupsRunID(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBackupsRunID", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		RunID:    "deploy-1",
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+	equals("deploy-1", l.LastRotation().RunID, t)
+
+	// the next run of the application.
+	isNil(l.Close(), t)
+	l2 := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		RunID:    "deploy-2",
+	}
+	defer l2.Close()
+	_, err := l2.Write([]byte("foo!"))
+	isNil(err, t)
+	newFakeTime()
+	isNil(l2.Rotate(), t)
+	second := backupFile(dir)
+
+	// the metadata is written without WriteMetadata.
+	exists(metadataName(first), t)
+
+	backups, err := l2.Backups()
+	isNil(err, t)
+	equals(2, len(backups), t)
+	equals("deploy-2", backups[0].RunID, t)
+	equals("deploy-1", backups[1].RunID, t)
+
+	backups, err = l2.Backups(ForRun("deploy-1"))
+	isNil(err, t)
+	equals(1, len(backups), t)
+	equals(first, backups[0].Path, t)
+
+	backups, err = l2.Backups(ForRun("deploy-2"))
+	isNil(err, t)
+	equals(1, len(backups), t)
+	equals(second, backups[0].Path, t)
+}
@@ -0,0 +1,81 @@
+package lumberjack_test
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jfrog/lumberjack/v2"
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func BenchmarkWriteSmall(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	lumberjacktest.Benchmark(b, 100, func(filename string) io.WriteCloser {
+		return &lumberjack.Logger{Filename: filename}
+	})
+}
+
+func BenchmarkWriteLarge(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	lumberjacktest.Benchmark(b, 64*1024, func(filename string) io.WriteCloser {
+		return &lumberjack.Logger{Filename: filename, MaxBackups: 1}
+	})
+}
+
+func BenchmarkWriteRotationHeavy(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	// a rotation every 256 writes.
+	lumberjacktest.Benchmark(b, 4096, func(filename string) io.WriteCloser {
+		return &lumberjack.Logger{Filename: filename, MaxSize: 1, MaxBackups: 3}
+	})
+}
+
+func BenchmarkWriteCompress(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	lumberjacktest.Benchmark(b, 4096, func(filename string) io.WriteCloser {
+		return &lumberjack.Logger{Filename: filename, MaxSize: 1, MaxBackups: 3, Compress: true}
+	})
+}
+
+func BenchmarkWriteBuffered(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	lumberjacktest.Benchmark(b, 100, func(filename string) io.WriteCloser {
+		return &lumberjack.Logger{Filename: filename, BufferSize: 64 * 1024}
+	})
+}
+
+func BenchmarkWriteAsync(b *testing.B) {
+	lumberjack.RestoreGlobals()
+	lumberjacktest.Benchmark(b, 100, func(filename string) io.WriteCloser {
+		return lumberjack.NewAsyncLogger(&lumberjack.Logger{Filename: filename}, 1024*1024, lumberjack.Block)
+	})
+}
+
+// TestWriteAllocs guards against allocations creeping into the write path.
+func TestWriteAllocs(t *testing.T) {
+	lumberjack.RestoreGlobals()
+	dir, err := ioutil.TempDir("", "TestWriteAllocs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	l := &lumberjack.Logger{Filename: filepath.Join(dir, "foobar.log")}
+	defer l.Close()
+	msg := []byte("boo!\n")
+	if _, err := l.Write(msg); err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := l.Write(msg); err != nil {
+			t.Fatal(err)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("expected no allocations per write, got %v", allocs)
+	}
+}
@@ -0,0 +1,70 @@
+package lumberjack
+
+// defaultBufferSize is the buffer size of an AsyncLogger if none is given.
+const defaultBufferSize = 4096
+
+// Flush writes the buffered data to the log file.
+func (l *Logger) Flush() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.flush()
+}
+
+// writeBuffered does the work of Write if BufferSize is set.  p is buffered
+// if it fits in the buffer, and written to the log file right away otherwise.
+func (l *Logger) writeBuffered(p []byte) (int, error) {
+	if cap(l.buf) != l.BufferSize {
+		// BufferSize was changed.
+		if err := l.flush(); err != nil {
+			return 0, err
+		}
+		l.buf = make([]byte, 0, l.BufferSize)
+	}
+	if len(l.buf)+len(p) > cap(l.buf) {
+		if err := l.flush(); err != nil {
+			return 0, err
+		}
+	}
+	if len(p) >= cap(l.buf) || int64(len(p)) > l.max() {
+		return l.write(p)
+	}
+	l.buf = append(l.buf, p...)
+	return len(p), nil
+}
+
+// flush writes the buffered data, opening and rotating the log file as
+// needed, in chunks no larger than MaxSize.
+func (l *Logger) flush() error {
+	for len(l.buf) > 0 {
+		chunk := l.buf
+		if max := l.max(); int64(len(chunk)) > max {
+			chunk = chunk[:max]
+		}
+		// a rotation while writing mustn't write the chunk again.
+		rest := l.buf[len(chunk):]
+		l.buf = nil
+		n, err := l.write(chunk)
+		l.buf = append(chunk[:0], chunk[n:]...)
+		l.buf = append(l.buf, rest...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// flushClosing writes the buffered data to the log file before it is closed,
+// so that nothing ends up in the wrong file when it is rotated, or is lost.
+func (l *Logger) flushClosing() error {
+	if len(l.buf) == 0 {
+		return nil
+	}
+	n, err := l.writeFile(l.buf)
+	l.size += int64(n)
+	l.countBytes(n)
+	if n > 0 {
+		l.recordWrite()
+	}
+	l.buf = append(l.buf[:0], l.buf[n:]...)
+	return err
+}
@@ -0,0 +1,161 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+)
+
+func TestBuffered(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBuffered", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		BufferSize: 10,
+	}
+	defer l.Close()
+
+	n, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	equals(4, n, t)
+	n, err = l.Write([]byte("boo!"))
+	isNil(err, t)
+	equals(4, n, t)
+	notExist(filename, t)
+
+	// the buffer is full.
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!boo!"), t)
+
+	isNil(l.Flush(), t)
+	existsWithContent(filename, []byte("boo!boo!foo!"), t)
+
+	// writes larger than the buffer go straight through.
+	_, err = l.Write([]byte("0123456789"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!boo!foo!0123456789"), t)
+
+	stats := l.Stats()
+	equals(int64(4), stats.Writes, t)
+	equals(int64(3), stats.PhysicalWrites, t)
+	assert(stats.WriteAmplification() < 1, t, "expected fewer physical writes, got %v", stats)
+}
+
+func TestBufferedRotate(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBufferedRotate", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		BufferSize: 100,
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+	existsWithContent(filename, []byte{}, t)
+
+}
+
+func TestBufferedSizeRotation(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBufferedSizeRotation", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		BufferSize: 8,
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!boo!"))
+	isNil(err, t)
+	isNil(l.Flush(), t)
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+
+	// the flush doesn't fit in the log file, which is rotated.
+	newFakeTime()
+	isNil(l.Flush(), t)
+	existsWithContent(backupFile(dir), []byte("boo!boo!"), t)
+	existsWithContent(filename, []byte("foo!"), t)
+}
+
+func TestBufferedClose(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBufferedClose", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{Filename: filename, BufferSize: 10}
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	notExist(filename, t)
+	isNil(l.Close(), t)
+	existsWithContent(filename, []byte("boo!"), t)
+}
+
+func TestBufferSize(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBufferSize", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		BufferSize: 10,
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	notExist(filename, t)
+	isNil(l.Flush(), t)
+	existsWithContent(filename, []byte("boo!"), t)
+
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+	isNil(l.Sync(), t)
+	existsWithContent(filename, []byte("boo!foo!"), t)
+
+	// a bigger buffer takes effect with the next write.
+	l.BufferSize = 20
+	_, err = l.Write([]byte("0123456789"))
+	isNil(err, t)
+	_, err = l.Write([]byte("bar!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!foo!"), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("boo!foo!0123456789bar!"), t)
+	existsWithContent(filename, []byte{}, t)
+
+	stats := l.Stats()
+	equals(int64(4), stats.Writes, t)
+	equals(int64(3), stats.PhysicalWrites, t)
+}
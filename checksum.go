@@ -0,0 +1,96 @@
+package lumberjack
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+var (
+	checksumsMu sync.RWMutex
+	checksums   = map[string]func() hash.Hash{
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+)
+
+// RegisterChecksum makes a hash algorithm available under the given name, for
+// use as Checksum.  The name is also the suffix of the checksum files, so it
+// should be the one verification tools expect.  It is meant to be called at
+// initialization, e.g. to register BLAKE3 from a third party package:
+//
+//	lumberjack.RegisterChecksum("b3", func() hash.Hash { return blake3.New(32, nil) })
+func RegisterChecksum(name string, newHash func() hash.Hash) {
+	checksumsMu.Lock()
+	defer checksumsMu.Unlock()
+	checksums[name] = newHash
+}
+
+// lookupChecksum returns the hash algorithm registered with the given name.
+func lookupChecksum(name string) (func() hash.Hash, bool) {
+	checksumsMu.RLock()
+	defer checksumsMu.RUnlock()
+	newHash, ok := checksums[name]
+	return newHash, ok
+}
+
+// checksumNames returns the names of the registered hash algorithms, sorted.
+func checksumNames() []string {
+	checksumsMu.RLock()
+	defer checksumsMu.RUnlock()
+	names := make([]string, 0, len(checksums))
+	for name := range checksums {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// checksum returns the name of the hash algorithm used to checksum backups,
+// or "" if they aren't checksummed.
+func (l *Logger) checksum() string {
+	if l.Checksum == "" && l.WriteChecksums {
+		return "sha256"
+	}
+	return l.Checksum
+}
+
+// writeChecksum writes the checksum of the backup next to it, in the format
+// of sha256sum and the like, so that e.g. "sha256sum -c" can verify it.
+func (l *Logger) writeChecksum(name string) error {
+	algorithm := l.checksum()
+	newHash, ok := lookupChecksum(algorithm)
+	if !ok {
+		return fmt.Errorf("unknown checksum %q", algorithm)
+	}
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("can't open backup to checksum: %s", err)
+	}
+	defer f.Close()
+	h := newHash()
+	if _, err := io.Copy(h, f); err != nil {
+		return fmt.Errorf("can't checksum backup: %s", err)
+	}
+	line := hex.EncodeToString(h.Sum(nil)) + "  " + filepath.Base(name) + "\n"
+	if err := ioutil.WriteFile(name+"."+algorithm, []byte(line), 0644); err != nil {
+		return fmt.Errorf("can't write backup checksum: %s", err)
+	}
+	return nil
+}
+
+// removeChecksums removes the checksum files of the backup, whichever hash
+// algorithm they were written with.
+func removeChecksums(name string) {
+	for _, algorithm := range checksumNames() {
+		_ = os.Remove(name + "." + algorithm)
+	}
+}
@@ -0,0 +1,110 @@
+package lumberjack
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"hash"
+	"hash/crc32"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestChecksum(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestChecksum", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		Checksum:   "sha512",
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	first := backupFile(dir)
+	sum := sha512.Sum512(b)
+	existsWithContent(first+".sha512", []byte(hex.EncodeToString(sum[:])+"  "+filepath.Base(first)+"\n"), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+
+	// the checksum goes along with the backup.
+	notExist(first+".sha512", t)
+	exists(backupFile(dir)+".sha512", t)
+	fileCount(dir, 3, t)
+}
+
+func TestRegisterChecksum(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRegisterChecksum", t)
+	defer os.RemoveAll(dir)
+
+	RegisterChecksum("crc32", func() hash.Hash { return crc32.NewIEEE() })
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		Checksum: "crc32",
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	h := crc32.NewIEEE()
+	h.Write(b)
+	existsWithContent(backupFile(dir)+".crc32", []byte(hex.EncodeToString(h.Sum(nil))+"  "+filepath.Base(backupFile(dir))+"\n"), t)
+
+	l.Checksum = "unknown"
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+}
+
+func TestWriteChecksums(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestWriteChecksums", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		Compress:       true,
+		WriteChecksums: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+
+	// the checksum is of the compressed backup.
+	compressed := backupFile(dir) + compressSuffix
+	content, err := ioutil.ReadFile(compressed)
+	isNil(err, t)
+	sum := sha256.Sum256(content)
+	existsWithContent(compressed+".sha256", []byte(hex.EncodeToString(sum[:])+"  "+filepath.Base(compressed)+"\n"), t)
+	notExist(backupFile(dir)+".sha256", t)
+}
@@ -0,0 +1,87 @@
+package lumberjack
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// backupName creates a new filename for a backup of the log file, using the
+// NameCodec to put a timestamp of the current time in it, using the local time
+// if requested (otherwise UTC).
+//
+// If the clock went back since the last backup, e.g. because of an NTP
+// correction or a VM being resumed, or hasn't moved on enough for the name to
+// change, the backup gets the timestamp of the last backup instead, with the
+// next sequence number.  This keeps backup names unique, and the order of the
+// names the order of the rotations, which retention relies on.
+func (l *Logger) backupName(local bool) string {
+	if l.SequentialBackups {
+		return l.sequenceName()
+	}
+	t := l.now()
+	if !local {
+		t = t.UTC()
+	}
+	codec := l.codec()
+	seq := 0
+	if stamp, _, err := codec.Decode(codec.Encode(t, 0)); err == nil {
+		if last, lastSeq, ok := l.lastBackup(); ok && !stamp.After(last) {
+			stamp, seq = last, lastSeq+1
+		}
+		// the parsed timestamp formats to the same name.
+		t = stamp
+		l.lastStamp, l.lastSeq, l.lastKnown = stamp, seq, true
+	}
+	name := codec.Encode(t, seq)
+	return filepath.Join(l.shardDir(name), name)
+}
+
+// lastBackup returns the timestamp and sequence number of the newest backup,
+// looking for it on disk the first time.
+func (l *Logger) lastBackup() (stamp time.Time, seq int, ok bool) {
+	if !l.lastKnown {
+		l.lastKnown = true
+		if files, err := l.oldLogFiles(); err == nil && len(files) > 0 {
+			l.lastStamp, l.lastSeq = files[0].timestamp, files[0].seq
+		}
+	}
+	return l.lastStamp, l.lastSeq, !l.lastStamp.IsZero()
+}
+
+// Clock tells the time and runs functions after a delay.  The default is the
+// system clock.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// AfterFunc calls f in its own goroutine after the duration d has
+	// elapsed, like time.AfterFunc.  The returned stop function cancels the
+	// call, and reports whether it did so before f was called.
+	AfterFunc(d time.Duration, f func()) (stop func() bool)
+}
+
+// systemClock is the Clock used if none is set.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) AfterFunc(d time.Duration, f func()) func() bool {
+	return time.AfterFunc(d, f).Stop
+}
+
+// clock returns the Clock to use.
+func (l *Logger) clock() Clock {
+	if l.Clock != nil {
+		return l.Clock
+	}
+	return systemClock{}
+}
+
+// now returns the current time for naming backups and scheduling rotations,
+// according to the Clock if one is set.
+func (l *Logger) now() time.Time {
+	if l.Clock != nil {
+		return l.Clock.Now()
+	}
+	return currentTime()
+}
@@ -0,0 +1,81 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestClockGoesBack(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestClockGoesBack", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		MaxBackups: 2,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("one"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	stamp := fakeTime()
+	existsWithContent(backupFile(dir), []byte("one"), t)
+
+	// the clock goes back an hour.
+	fakeCurrentTime = fakeCurrentTime.Add(-time.Hour)
+	writeToCurrentLog(t, l, filename, []byte("two"))
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFileWithTime(dir, stamp, withSequence(1)), []byte("two"), t)
+
+	// and hasn't moved on for the next rotation.
+	writeToCurrentLog(t, l, filename, []byte("three"))
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFileWithTime(dir, stamp, withSequence(2)), []byte("three"), t)
+
+	// we need to wait a little bit since the files get removed on a
+	// different goroutine.
+	<-time.After(10 * time.Millisecond)
+
+	// the backup that was rotated first is the one removed.
+	notExist(backupFileWithTime(dir, stamp), t)
+	fileCount(dir, 3, t)
+
+	// once the clock is past the last backup, names are back to normal.
+	newFakeTime()
+	writeToCurrentLog(t, l, filename, []byte("four"))
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("four"), t)
+}
+
+func TestClockBehindExistingBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestClockBehindExistingBackups", t)
+	defer os.RemoveAll(dir)
+
+	// a backup from before a restart, with the clock now an hour behind it.
+	stamp := fakeTime().Truncate(time.Millisecond)
+	existing := backupFileWithTime(dir, stamp, withSequence(3))
+	isNil(ioutil.WriteFile(existing, []byte("old"), 0644), t)
+	fakeCurrentTime = fakeCurrentTime.Add(-time.Hour)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("new"))
+	isNil(l.Rotate(), t)
+	existsWithContent(existing, []byte("old"), t)
+	existsWithContent(backupFileWithTime(dir, stamp, withSequence(4)), []byte("new"), t)
+}
@@ -0,0 +1,124 @@
+package lumberjack
+
+import (
+	"reflect"
+	"time"
+)
+
+// Option overrides a setting of a Logger created by NewLogger or CloneWith.
+type Option func(*Logger)
+
+// NewLogger returns a Logger with the default settings, changed by the given
+// options:
+//
+//	l, err := lumberjack.NewLogger(
+//		lumberjack.WithFilename("/var/log/myapp/app.log"),
+//		lumberjack.WithMaxSize(100),
+//		lumberjack.WithCompress(true),
+//	)
+//
+// Unlike a Logger set up by hand, its settings are checked up front: the
+// error is a *ConfigError if Validate finds problems with them.
+func NewLogger(opts ...Option) (*Logger, error) {
+	l := &Logger{}
+	for _, opt := range opts {
+		opt(l)
+	}
+	if err := l.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// WithConfig sets the settings of c.
+func WithConfig(c Config) Option {
+	return func(l *Logger) { l.setConfig(c) }
+}
+
+// WithFilename sets the Filename.
+func WithFilename(filename string) Option {
+	return func(l *Logger) { l.Filename = filename }
+}
+
+// WithMaxSize sets the MaxSize.
+func WithMaxSize(megabytes int) Option {
+	return func(l *Logger) { l.MaxSize = megabytes }
+}
+
+// WithMaxAge sets the MaxAge.
+func WithMaxAge(days int) Option {
+	return func(l *Logger) { l.MaxAge = days }
+}
+
+// WithMaxAgeDuration sets the MaxAgeDuration.
+func WithMaxAgeDuration(d time.Duration) Option {
+	return func(l *Logger) { l.MaxAgeDuration = d }
+}
+
+// WithMaxBackups sets the MaxBackups.
+func WithMaxBackups(n int) Option {
+	return func(l *Logger) { l.MaxBackups = n }
+}
+
+// WithMaxTotalSize sets the MaxTotalSize.
+func WithMaxTotalSize(megabytes int) Option {
+	return func(l *Logger) { l.MaxTotalSize = megabytes }
+}
+
+// WithCompress sets the Compress setting.
+func WithCompress(compress bool) Option {
+	return func(l *Logger) { l.Compress = compress }
+}
+
+// WithLocalTime sets the LocalTime setting.
+func WithLocalTime(local bool) Option {
+	return func(l *Logger) { l.LocalTime = local }
+}
+
+// WithBackupDir sets the BackupDir.
+func WithBackupDir(dir string) Option {
+	return func(l *Logger) { l.BackupDir = dir }
+}
+
+// WithTimeFormat sets the TimeFormat.
+func WithTimeFormat(format string) Option {
+	return func(l *Logger) { l.TimeFormat = format }
+}
+
+// CloneWith returns a new Logger with the same settings as l, changed by the
+// given options, for the common case of several log files sharing a policy:
+//
+//	base := &lumberjack.Logger{MaxSize: 100, MaxBackups: 5, Compress: true}
+//	api := base.CloneWith(lumberjack.WithFilename("/var/log/myapp/api.log"))
+//	db := base.CloneWith(lumberjack.WithFilename("/var/log/myapp/db.log"))
+//
+// Only the settings are copied, not the state of l, such as its open log
+// file or statistics.  If l is managed by a Manager, so is the clone.
+func (l *Logger) CloneWith(opts ...Option) *Logger {
+	l.mu.Lock()
+	clone := &Logger{}
+	src := reflect.ValueOf(l).Elem()
+	dst := reflect.ValueOf(clone).Elem()
+	for i := 0; i < src.NumField(); i++ {
+		if src.Type().Field(i).PkgPath != "" {
+			// unexported fields hold the state.
+			continue
+		}
+		v := src.Field(i)
+		if v.Kind() == reflect.Slice && !v.IsNil() {
+			// don't share the backing array.
+			v = reflect.AppendSlice(reflect.MakeSlice(v.Type(), 0, v.Len()), v)
+		}
+		dst.Field(i).Set(v)
+	}
+	m := l.manager
+	l.mu.Unlock()
+
+	for _, opt := range opts {
+		opt(clone)
+	}
+	if m != nil {
+		m.Add(clone)
+	}
+	return clone
+}
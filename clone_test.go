@@ -0,0 +1,92 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCloneWith(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	// the disk usage changes under the test, so it isn't reported.
+	statDisk = func(string) (int64, int64, bool) { return 0, 0, false }
+	defer func() { statDisk = diskUsage }()
+
+	dir := makeTempDir("TestCloneWith", t)
+	defer os.RemoveAll(dir)
+
+	m := &Manager{MaxTotalSize: 1000}
+	base := &Logger{
+		Filename:   logFile(dir),
+		MaxSize:    100,
+		MaxBackups: 5,
+		Compress:   true,
+		BackupDirs: []string{dir},
+		OnRotate:   func(RotationInfo) {},
+	}
+	m.Add(base)
+	defer base.Close()
+	_, err := base.Write([]byte("boo!"))
+	isNil(err, t)
+
+	other := filepath.Join(dir, "other.log")
+	clone := base.CloneWith(WithFilename(other), WithMaxBackups(2))
+	defer clone.Close()
+
+	equals(other, clone.Filename, t)
+	equals(2, clone.MaxBackups, t)
+	equals(100, clone.MaxSize, t)
+	equals(true, clone.Compress, t)
+	equals(base.BackupDirs, clone.BackupDirs, t)
+	notNil(clone.OnRotate, t)
+	equals(5, base.MaxBackups, t)
+
+	// the clone doesn't share the state or the slices of the original.
+	isNil(clone.file, t)
+	equals(Stats{}, clone.Stats(), t)
+	// the Manager's cleanup reads the directories, so it must be done first.
+	isNil(base.Mill(context.Background()), t)
+	clone.BackupDirs[0] = "elsewhere"
+	equals(dir, base.BackupDirs[0], t)
+	clone.BackupDirs[0] = dir
+
+	equals([]*Logger{base, clone}, m.loggers, t)
+
+	_, err = clone.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(other, []byte("foo!"), t)
+	existsWithContent(logFile(dir), []byte("boo!"), t)
+	isNil(clone.Mill(context.Background()), t)
+}
+
+func TestNewLogger(t *testing.T) {
+	l, err := NewLogger(WithFilename("/var/log/app.log"), WithMaxSize(100), WithCompress(true))
+	isNil(err, t)
+	equals("/var/log/app.log", l.Filename, t)
+	equals(100, l.MaxSize, t)
+	equals(true, l.Compress, t)
+
+	l, err = NewLogger(WithConfig(Config{MaxBackups: 5, LocalTime: true}), WithMaxBackups(3))
+	isNil(err, t)
+	equals(3, l.MaxBackups, t)
+	equals(true, l.LocalTime, t)
+
+	l, err = NewLogger(WithMaxAgeDuration(12 * time.Hour))
+	isNil(err, t)
+	equals(12*time.Hour, l.MaxAgeDuration, t)
+	_, err = NewLogger(WithMaxAgeDuration(-time.Hour))
+	notNil(err, t)
+
+	// a date is enough, since backups of the same day are numbered.
+	_, err = NewLogger(WithTimeFormat("2006-01-02"))
+	isNil(err, t)
+
+	l, err = NewLogger(WithFilename("/var/log/app.log"), WithBackupDir("/var/log/app.log"), WithTimeFormat("2006/01/02"))
+	notNil(err, t)
+	isNil(l, t)
+	_, ok := err.(*ConfigError)
+	assert(ok, t, "expected a *ConfigError, got %T", err)
+}
@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NameCodec encodes and decodes the names of backup files.  The same codec is
+// used to name backups when rotating and to recognize them when cleaning up,
+// so naming and parsing can't get out of step.
+type NameCodec interface {
+	// Encode returns the base name of a backup created at time t, which is
+	// already in UTC or local time as configured.  seq is 0, unless several
+	// backups must be told apart for the same time, in which case it counts
+	// up from 1.
+	Encode(t time.Time, seq int) string
+
+	// Decode parses a base name returned by Encode, without any compression
+	// suffix.  It returns an error for names Encode can't have returned, which
+	// don't belong to backups.
+	Decode(name string) (t time.Time, seq int, err error)
+}
+
+// codec returns the NameCodec used for backups.
+func (l *Logger) codec() NameCodec {
+	if l.NameCodec != nil {
+		return l.NameCodec
+	}
+	if l.SequentialBackups {
+		return sequenceCodec{base: filepath.Base(l.filename())}
+	}
+	prefix, ext := l.prefixAndExt()
+	return timeFormatCodec{prefix: prefix, ext: ext, format: l.timeFormat()}
+}
+
+// timeFormatCodec is the default NameCodec.  It puts the timestamp between the
+// log file's name and its extension, followed by a three digit sequence number
+// when it is needed, e.g. foo-2014-05-04T14-44-33.555-001.log.
+type timeFormatCodec struct {
+	prefix string
+	ext    string
+	format string
+}
+
+func (c timeFormatCodec) Encode(t time.Time, seq int) string {
+	timestamp := t.Format(c.format)
+	if seq > 0 {
+		timestamp += fmt.Sprintf("-%03d", seq)
+	}
+	return c.prefix + timestamp + c.ext
+}
+
+func (c timeFormatCodec) Decode(name string) (time.Time, int, error) {
+	if !strings.HasPrefix(name, c.prefix) {
+		return time.Time{}, 0, errors.New("mismatched prefix")
+	}
+	if !strings.HasSuffix(name, c.ext) || len(name) < len(c.prefix)+len(c.ext) {
+		return time.Time{}, 0, errors.New("mismatched extension")
+	}
+	timestamp := name[len(c.prefix) : len(name)-len(c.ext)]
+	t, err := c.parse(timestamp)
+	if err == nil {
+		return t, 0, nil
+	}
+	if i := strings.LastIndex(timestamp, "-"); i > 0 {
+		seq, seqErr := strconv.Atoi(timestamp[i+1:])
+		if seqErr == nil && seq > 0 {
+			if t, tErr := c.parse(timestamp[:i]); tErr == nil {
+				return t, seq, nil
+			}
+		}
+	}
+	return time.Time{}, 0, err
+}
+
+// parse parses the timestamp of a backup name.  Backups named with fractional
+// seconds of another precision, e.g. before TimePrecision was changed, are
+// recognized too.
+func (c timeFormatCodec) parse(timestamp string) (time.Time, error) {
+	t, err := time.Parse(c.format, timestamp)
+	if err != nil {
+		if format := anyPrecision(c.format); format != c.format {
+			if t, err := time.Parse(format, timestamp); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return t, err
+}
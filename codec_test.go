@@ -0,0 +1,89 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTimeFormatCodec(t *testing.T) {
+	c := timeFormatCodec{prefix: "foo-", ext: ".log", format: DefaultTimeFormat}
+	ts := time.Date(2014, 5, 4, 14, 44, 33, 555000000, time.UTC)
+
+	equals("foo-2014-05-04T14-44-33.555.log", c.Encode(ts, 0), t)
+	equals("foo-2014-05-04T14-44-33.555-002.log", c.Encode(ts, 2), t)
+
+	tests := []struct {
+		name    string
+		want    time.Time
+		seq     int
+		wantErr bool
+	}{
+		{"foo-2014-05-04T14-44-33.555.log", ts, 0, false},
+		{"foo-2014-05-04T14-44-33.555-002.log", ts, 2, false},
+		{"foo-2014-05-04T14-44-33.555-abc.log", time.Time{}, 0, true},
+		{"foo-2014-05-04T14-44-33.555-000.log", time.Time{}, 0, true},
+		{"bar-2014-05-04T14-44-33.555.log", time.Time{}, 0, true},
+		{"foo.log", time.Time{}, 0, true},
+	}
+	for _, test := range tests {
+		got, seq, err := c.Decode(test.name)
+		equals(test.want, got, t)
+		equals(test.seq, seq, t)
+		equals(test.wantErr, err != nil, t)
+	}
+}
+
+// upperCodec names backups like FOO.<timestamp>.<letter for the sequence>.
+type upperCodec struct{}
+
+func (upperCodec) Encode(t time.Time, seq int) string {
+	return "FOO." + t.Format("20060102150405") + "." + string(rune('a'+seq))
+}
+
+func (upperCodec) Decode(name string) (time.Time, int, error) {
+	parts := strings.Split(name, ".")
+	if len(parts) != 3 || parts[0] != "FOO" || len(parts[2]) != 1 {
+		return time.Time{}, 0, os.ErrInvalid
+	}
+	ts, err := time.Parse("20060102150405", parts[1])
+	return ts, int(parts[2][0] - 'a'), err
+}
+
+func TestCustomNameCodec(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCustomNameCodec", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		NameCodec:  upperCodec{},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := filepath.Join(dir, upperCodec{}.Encode(fakeTime().UTC(), 0))
+	existsWithContent(first, b, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	// we need to wait a little bit since the files get deleted on a different
+	// goroutine.
+	<-time.After(10 * time.Millisecond)
+
+	// retention recognizes the custom names.
+	notExist(first, t)
+	exists(filepath.Join(dir, upperCodec{}.Encode(fakeTime().UTC(), 0)), t)
+	fileCount(dir, 2, t)
+}
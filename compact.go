@@ -0,0 +1,173 @@
+package lumberjack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// compactingSuffix is appended to the name of a combined backup while it is
+// being written, so that it isn't mistaken for a backup until it is complete.
+const compactingSuffix = ".compacting"
+
+// compact merges runs of consecutive backups smaller than CompactBelow in the
+// given backups, sorted newest first, and reports whether it merged any.
+func (l *Logger) compact(files []logInfo) (bool, error) {
+	if l.CompactBelow <= 0 || l.SequentialBackups || l.AuditMode {
+		return false, nil
+	}
+	below := int64(l.CompactBelow) * int64(megabyte)
+
+	var (
+		run       []logInfo
+		runSize   int64
+		compacted bool
+		err       error
+	)
+	flush := func() {
+		if len(run) > 1 {
+			errCompact := l.compactRun(run)
+			if errCompact == nil {
+				compacted = true
+			} else if err == nil {
+				err = errCompact
+			}
+		}
+		run, runSize = nil, 0
+	}
+	// backups are merged oldest first, so the content stays in order.
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		name := filepath.Join(f.dir, f.Name())
+		if f.Size() >= below || isEncrypted(name) || l.isReading(name) {
+			flush()
+			continue
+		}
+		if len(run) > 0 && (run[0].dir != f.dir || runSize+f.Size() > l.max()) {
+			flush()
+		}
+		run = append(run, f)
+		runSize += f.Size()
+	}
+	flush()
+	return compacted, err
+}
+
+// compactRun merges the given backups, oldest first, into a single backup
+// named after the newest of them.  Compressed backups are decompressed, and
+// the combined backup is compressed and finalized like a newly rotated one.
+func (l *Logger) compactRun(run []logInfo) error {
+	oldest, newest := run[0], run[len(run)-1]
+	name := filepath.Join(newest.dir, trimCompressSuffix(newest.Name()))
+	tmp := l.stepName(name)
+	size, err := concatBackups(run, tmp, backupMode(l.BackupFileMode, newest))
+	if err != nil {
+		return err
+	}
+	first, errFirst := readMetadata(filepath.Join(oldest.dir, oldest.Name()))
+	meta, errLast := readMetadata(filepath.Join(newest.dir, newest.Name()))
+
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	defer unlock()
+	for _, f := range run {
+		if _, err := os.Lstat(filepath.Join(f.dir, f.Name())); err != nil {
+			// another process removed or compressed it meanwhile.
+			os.Remove(tmp)
+			return nil
+		}
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("can't rename combined backup: %s", err)
+	}
+	for _, f := range run {
+		part := filepath.Join(f.dir, f.Name())
+		if part == name {
+			continue
+		}
+		if err := removeBackup(part); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("can't remove combined backup: %s", err)
+		}
+	}
+	// signatures and checksums of the newest backup are stale now.
+	_ = os.Remove(name + signatureSuffix)
+	removeChecksums(name)
+
+	// the metadata of the combined backup spans the writes to all of them.
+	if errFirst == nil || errLast == nil {
+		if errLast != nil {
+			meta = RotationInfo{Time: newest.timestamp}
+		}
+		if errFirst == nil {
+			meta.FirstWrite = first.FirstWrite
+		}
+		meta.NewPath = name
+		meta.Bytes = size
+		meta.CompressedPath = ""
+		// metadata is best effort.
+		_ = writeMetadata(name, meta)
+	}
+
+	l.countCompacted(len(run))
+	if !l.Compress {
+		l.queueFinalize(name)
+	}
+	return nil
+}
+
+// concatBackups writes the content of the given backups, decompressed, to a
+// new file with the given name, and returns its size.
+func concatBackups(run []logInfo, dst string, mode os.FileMode) (n int64, err error) {
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, mode)
+	if err != nil {
+		return 0, fmt.Errorf("can't create combined backup: %s", err)
+	}
+	defer func() {
+		if errClose := out.Close(); err == nil {
+			err = errClose
+		}
+		if err != nil {
+			os.Remove(dst)
+		}
+	}()
+	if err := out.Chmod(mode); err != nil {
+		return 0, fmt.Errorf("can't set mode of combined backup: %s", err)
+	}
+
+	for _, f := range run {
+		written, err := copyBackup(out, filepath.Join(f.dir, f.Name()))
+		n += written
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// copyBackup copies the content of the named backup, decompressed, to w.
+func copyBackup(w io.Writer, name string) (int64, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return 0, fmt.Errorf("can't open backup to combine: %s", err)
+	}
+	defer f.Close()
+	var r io.Reader = f
+	if c := compressorFor(name); c != nil {
+		dec, err := c.NewReader(f)
+		if err != nil {
+			return 0, fmt.Errorf("can't decompress backup to combine: %s", err)
+		}
+		defer dec.Close()
+		r = dec
+	}
+	n, err := io.Copy(w, r)
+	if err != nil {
+		return n, fmt.Errorf("can't combine backup: %s", err)
+	}
+	return n, nil
+}
@@ -0,0 +1,110 @@
+package lumberjack
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestCompactBelow(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCompactBelow", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       100,
+		CompactBelow:  10,
+		WriteMetadata: true,
+	}
+	defer l.Close()
+
+	first := fakeTime()
+	for _, s := range []string{"foo\n", "boo\n", "bar\n"} {
+		writeToCurrentLog(t, l, filename, []byte(s))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		isNil(l.Mill(context.Background()), t)
+	}
+
+	// the backups are merged into the newest one.
+	existsWithContent(backupFile(dir), []byte("foo\nboo\nbar\n"), t)
+	meta, err := readMetadata(backupFile(dir))
+	isNil(err, t)
+	assert(first.Equal(meta.FirstWrite), t, "expected the first write of the oldest backup, got %v", meta.FirstWrite)
+	equals(int64(12), meta.Bytes, t)
+	fileCount(dir, 3, t)
+	equals(int64(4), l.Stats().Compacted, t)
+}
+
+func TestCompactBelowLargeBackup(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCompactBelowLargeBackup", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      100,
+		CompactBelow: 10,
+	}
+	defer l.Close()
+
+	var backups []string
+	for _, s := range []string{"foo\n", "a large backup\n", "boo\n", "bar\n"} {
+		writeToCurrentLog(t, l, filename, []byte(s))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		backups = append(backups, backupFile(dir))
+	}
+	isNil(l.Mill(context.Background()), t)
+
+	// backups aren't merged across one that isn't small.
+	existsWithContent(backups[0], []byte("foo\n"), t)
+	existsWithContent(backups[1], []byte("a large backup\n"), t)
+	notExist(backups[2], t)
+	existsWithContent(backups[3], []byte("boo\nbar\n"), t)
+	fileCount(dir, 4, t)
+}
+
+func TestCompactBelowCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCompactBelowCompressed", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      1000,
+		CompactBelow: 100,
+		Compress:     true,
+	}
+	defer l.Close()
+
+	for _, s := range []string{"foo\n", "boo\n"} {
+		writeToCurrentLog(t, l, filename, []byte(s))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		isNil(l.Mill(context.Background()), t)
+	}
+
+	// the merged backup is compressed again.
+	compressed := backupFile(dir) + compressSuffix
+	exists(compressed, t)
+	notExist(backupFile(dir), t)
+	fileCount(dir, 2, t)
+	r, err := l.OpenBackup(compressed)
+	isNil(err, t)
+	content, err := ioutil.ReadAll(r)
+	isNil(err, t)
+	isNil(r.Close(), t)
+	equals("foo\nboo\n", string(content), t)
+}
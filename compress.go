@@ -0,0 +1,112 @@
+package lumberjack
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// Compressor compresses backups with a particular algorithm.
+type Compressor interface {
+	// Suffix is appended to the name of backups compressed with the
+	// Compressor, e.g. ".gz".  It must be unique among the registered
+	// Compressors, as it identifies the Compressor to decompress a backup.
+	Suffix() string
+
+	// NewWriter returns a writer compressing everything written to it to w.
+	// Closing the writer must flush the compressed data, but not close w.
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader decompressing the data read from r.
+	NewReader(r io.Reader) (io.ReadCloser, error)
+}
+
+// DefaultCompression is the name of the Compressor used if CompressionCodec
+// isn't set.
+const DefaultCompression = "gzip"
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[string]Compressor{
+		DefaultCompression: gzipCompressor{},
+	}
+)
+
+// RegisterCompressor makes a Compressor available under the given name, for
+// use as CompressionCodec.  Backups compressed with any of the registered
+// Compressors are recognized by their suffix.  It is meant to be called at
+// initialization, e.g. to register zstd from a third party package:
+//
+//	lumberjack.RegisterCompressor("zstd", zstdCompressor{})
+func RegisterCompressor(name string, c Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[name] = c
+}
+
+// lookupCompressor returns the Compressor registered with the given name.
+func lookupCompressor(name string) (Compressor, bool) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	c, ok := compressors[name]
+	return c, ok
+}
+
+// compressor returns the Compressor selected by CompressionCodec.
+func (l *Logger) compressor() (Compressor, error) {
+	name := l.CompressionCodec
+	if name == "" {
+		name = DefaultCompression
+	}
+	c, ok := lookupCompressor(name)
+	if !ok {
+		return nil, fmt.Errorf("unknown compression codec %q", name)
+	}
+	return c, nil
+}
+
+// compressorFor returns the Compressor that compressed the backup with the
+// given name, going by its suffix, or nil if the backup isn't compressed.
+func compressorFor(name string) Compressor {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	for _, c := range compressors {
+		if strings.HasSuffix(name, c.Suffix()) {
+			return c
+		}
+	}
+	return nil
+}
+
+// trimCompressSuffix returns the name of a backup without the suffixes of the
+// Compressor that compressed it and of the Encrypter that encrypted it, if
+// any.
+func trimCompressSuffix(name string) string {
+	name = trimEncryptSuffix(name)
+	if c := compressorFor(name); c != nil {
+		return strings.TrimSuffix(name, c.Suffix())
+	}
+	return name
+}
+
+// isCompressed reports whether the backup with the given name is compressed.
+func isCompressed(name string) bool {
+	return compressorFor(trimEncryptSuffix(name)) != nil
+}
+
+// gzipCompressor compresses with gzip.
+type gzipCompressor struct{}
+
+func (gzipCompressor) Suffix() string {
+	return compressSuffix
+}
+
+func (gzipCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(w), nil
+}
+
+func (gzipCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(r)
+}
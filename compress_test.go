@@ -0,0 +1,113 @@
+package lumberjack
+
+import (
+	"bytes"
+	"compress/flate"
+	"io"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// flateCompressor is a Compressor other than the default one.
+type flateCompressor struct{}
+
+func (flateCompressor) Suffix() string {
+	return ".deflate"
+}
+
+func (flateCompressor) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(w, flate.BestSpeed)
+}
+
+func (flateCompressor) NewReader(r io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(r), nil
+}
+
+func init() {
+	RegisterCompressor("flate", flateCompressor{})
+}
+
+func TestCompressionCodec(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCompressionCodec", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:         filename,
+		MaxSize:          10,
+		MaxBackups:       1,
+		Compress:         true,
+		CompressionCodec: "flate",
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+
+	// we need to wait a little bit since the files get compressed on a
+	// different goroutine.
+	<-time.After(300 * time.Millisecond)
+
+	notExist(first, t)
+	f, err := os.Open(first + ".deflate")
+	isNil(err, t)
+	defer f.Close()
+	got, err := ioutil.ReadAll(flate.NewReader(f))
+	isNil(err, t)
+	equals(string(b), string(got), t)
+
+	backups, err := l.Backups(CompressedOnly())
+	isNil(err, t)
+	equals(1, len(backups), t)
+
+	// the compressed backup counts towards MaxBackups.
+	b2 := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b2)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-time.After(300 * time.Millisecond)
+	notExist(first+".deflate", t)
+	exists(backupFile(dir)+".deflate", t)
+	fileCount(dir, 2, t)
+
+	// and is decompressed when restored.
+	isNil(l.Restore(backupFileWithTime(dir, fakeTime())+".deflate"), t)
+	content, err := ioutil.ReadFile(filename)
+	isNil(err, t)
+	assert(bytes.Equal(b2, content), t, "expected %q, got %q", b2, content)
+}
+
+func TestCompressionCodecUnknown(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCompressionCodecUnknown", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:         filename,
+		MaxSize:          10,
+		Compress:         true,
+		CompressionCodec: "rar",
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-time.After(10 * time.Millisecond)
+
+	// the backup is left uncompressed.
+	existsWithContent(backupFile(dir), b, t)
+	notNil(l.millRunOnce(), t)
+}
@@ -0,0 +1,190 @@
+package lumberjack
+
+import (
+	"fmt"
+	"time"
+)
+
+// Config holds the settings of a Logger that can be expressed as plain data.
+// The fields have the same meaning, and are encoded with the same keys, as
+// the Logger fields of the same name.
+type Config struct {
+	Filename             string               `json:"filename" yaml:"filename"`
+	MaxSize              int                  `json:"maxsize" yaml:"maxsize"`
+	MaxAge               int                  `json:"maxage" yaml:"maxage"`
+	MaxAgeDuration       time.Duration        `json:"maxageduration" yaml:"maxageduration"`
+	UseModTimeFallback   bool                 `json:"usemodtimefallback" yaml:"usemodtimefallback"`
+	MaxBackups           int                  `json:"maxbackups" yaml:"maxbackups"`
+	KeepDaily            int                  `json:"keepdaily" yaml:"keepdaily"`
+	KeepWeekly           int                  `json:"keepweekly" yaml:"keepweekly"`
+	KeepAllFor           time.Duration        `json:"keepallfor" yaml:"keepallfor"`
+	MaxTotalSize         int                  `json:"maxtotalsize" yaml:"maxtotalsize"`
+	MinFreeSpace         int                  `json:"minfreespace" yaml:"minfreespace"`
+	MinFreePercent       float64              `json:"minfreepercent" yaml:"minfreepercent"`
+	RejectLowSpaceWrites bool                 `json:"rejectlowspacewrites" yaml:"rejectlowspacewrites"`
+	CompactBelow         int                  `json:"compactbelow" yaml:"compactbelow"`
+	AuditMode            bool                 `json:"auditmode" yaml:"auditmode"`
+	LocalTime            bool                 `json:"localtime" yaml:"localtime"`
+	Compress             bool                 `json:"compress" yaml:"compress"`
+	CompressionCodec     string               `json:"compressioncodec" yaml:"compressioncodec"`
+	KeepLastDecompressed int                  `json:"keeplastdecompressed" yaml:"keeplastdecompressed"`
+	Checksum             string               `json:"checksum" yaml:"checksum"`
+	Encryption           string               `json:"encryption" yaml:"encryption"`
+	TimeFormat           string               `json:"timeformat" yaml:"timeformat"`
+	TimePrecision        TimePrecision        `json:"timeprecision" yaml:"timeprecision"`
+	SequentialBackups    bool                 `json:"sequentialbackups" yaml:"sequentialbackups"`
+	BackupDir            string               `json:"backupdir" yaml:"backupdir"`
+	BackupDirLayout      string               `json:"backupdirlayout" yaml:"backupdirlayout"`
+	SymlinkCurrent       bool                 `json:"symlinkcurrent" yaml:"symlinkcurrent"`
+	BufferSize           int                  `json:"buffersize" yaml:"buffersize"`
+	MaxBytesPerSecond    int                  `json:"maxbytespersecond" yaml:"maxbytespersecond"`
+	RateLimitPolicy      RateLimitPolicy      `json:"ratelimitpolicy" yaml:"ratelimitpolicy"`
+	SyncPolicy           SyncPolicy           `json:"syncpolicy" yaml:"syncpolicy"`
+	SyncInterval         time.Duration        `json:"syncinterval" yaml:"syncinterval"`
+	OversizeWrites       OversizePolicy       `json:"oversizewrites" yaml:"oversizewrites"`
+	ExistingBackups      ExistingBackupPolicy `json:"existingbackups" yaml:"existingbackups"`
+	RotateAt             string               `json:"rotateat" yaml:"rotateat"`
+}
+
+// Config returns the Logger's settings.
+func (l *Logger) Config() Config {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	return Config{
+		Filename:             l.Filename,
+		MaxSize:              l.MaxSize,
+		MaxAge:               l.MaxAge,
+		MaxAgeDuration:       l.MaxAgeDuration,
+		UseModTimeFallback:   l.UseModTimeFallback,
+		MaxBackups:           l.MaxBackups,
+		KeepDaily:            l.KeepDaily,
+		KeepWeekly:           l.KeepWeekly,
+		KeepAllFor:           l.KeepAllFor,
+		MaxTotalSize:         l.MaxTotalSize,
+		MinFreeSpace:         l.MinFreeSpace,
+		MinFreePercent:       l.MinFreePercent,
+		RejectLowSpaceWrites: l.RejectLowSpaceWrites,
+		CompactBelow:         l.CompactBelow,
+		AuditMode:            l.AuditMode,
+		LocalTime:            l.LocalTime,
+		Compress:             l.Compress,
+		CompressionCodec:     l.CompressionCodec,
+		KeepLastDecompressed: l.KeepLastDecompressed,
+		Checksum:             l.Checksum,
+		Encryption:           l.Encryption,
+		TimeFormat:           l.TimeFormat,
+		TimePrecision:        l.TimePrecision,
+		SequentialBackups:    l.SequentialBackups,
+		BackupDir:            l.BackupDir,
+		BackupDirLayout:      l.BackupDirLayout,
+		SymlinkCurrent:       l.SymlinkCurrent,
+		BufferSize:           l.BufferSize,
+		MaxBytesPerSecond:    l.MaxBytesPerSecond,
+		RateLimitPolicy:      l.RateLimitPolicy,
+		SyncPolicy:           l.SyncPolicy,
+		SyncInterval:         l.SyncInterval,
+		OversizeWrites:       l.OversizeWrites,
+		ExistingBackups:      l.ExistingBackups,
+		RotateAt:             l.RotateAt,
+	}
+}
+
+// UpdateConfig changes the Logger's settings to those of c while it is in use,
+// without closing the log file unless Filename changes.  The log file is
+// rotated straight away if it is larger than the new MaxSize, and old log
+// files are cleaned up according to the new settings.  Backups already in
+// the old BackupDir are left there.  Use c.Validate first to check the
+// settings, since they are taken as they are; only a TimeFormat the backups
+// can't be named with is rejected, leaving the settings unchanged.
+func (l *Logger) UpdateConfig(c Config) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flush(); err != nil {
+		return err
+	}
+
+	// the mill reads the settings without the Logger locked.
+	old := l.Config()
+	l.configMu.Lock()
+	l.setConfig(c)
+	err := l.checkTimeFormat()
+	if err != nil {
+		l.setConfig(old)
+	}
+	l.configMu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	if c.Filename != old.Filename || c.SymlinkCurrent != old.SymlinkCurrent {
+		// the next write opens the new log file.
+		if err := l.close(); err != nil {
+			return err
+		}
+	}
+
+	// what was worked out from the old settings.
+	l.lastKnown = false
+	l.spaceChecked = time.Time{}
+	l.rateTime = time.Time{}
+	if l.file != nil && c.RotateAt != old.RotateAt {
+		l.stopSchedule()
+		l.schedule(true)
+	}
+
+	l.syncSize()
+	if l.file != nil && !l.special && l.size > l.max() {
+		if err = l.rotate(RotationSize); err != nil {
+			err = fmt.Errorf("can't rotate log file for the new MaxSize: %s", err)
+		}
+	}
+	l.mill()
+	return err
+}
+
+// setConfig sets the Logger's settings to those of c.
+func (l *Logger) setConfig(c Config) {
+	l.Filename = c.Filename
+	l.MaxSize = c.MaxSize
+	l.MaxAge = c.MaxAge
+	l.MaxAgeDuration = c.MaxAgeDuration
+	l.UseModTimeFallback = c.UseModTimeFallback
+	l.MaxBackups = c.MaxBackups
+	l.KeepDaily = c.KeepDaily
+	l.KeepWeekly = c.KeepWeekly
+	l.KeepAllFor = c.KeepAllFor
+	l.MaxTotalSize = c.MaxTotalSize
+	l.MinFreeSpace = c.MinFreeSpace
+	l.MinFreePercent = c.MinFreePercent
+	l.RejectLowSpaceWrites = c.RejectLowSpaceWrites
+	l.CompactBelow = c.CompactBelow
+	l.AuditMode = c.AuditMode
+	l.LocalTime = c.LocalTime
+	l.Compress = c.Compress
+	l.CompressionCodec = c.CompressionCodec
+	l.KeepLastDecompressed = c.KeepLastDecompressed
+	l.Checksum = c.Checksum
+	l.Encryption = c.Encryption
+	l.TimeFormat = c.TimeFormat
+	l.TimePrecision = c.TimePrecision
+	l.SequentialBackups = c.SequentialBackups
+	l.BackupDir = c.BackupDir
+	l.BackupDirLayout = c.BackupDirLayout
+	l.SymlinkCurrent = c.SymlinkCurrent
+	l.BufferSize = c.BufferSize
+	l.MaxBytesPerSecond = c.MaxBytesPerSecond
+	l.RateLimitPolicy = c.RateLimitPolicy
+	l.SyncPolicy = c.SyncPolicy
+	l.SyncInterval = c.SyncInterval
+	l.OversizeWrites = c.OversizeWrites
+	l.ExistingBackups = c.ExistingBackups
+	l.RotateAt = c.RotateAt
+}
+
+// max returns the maximum size in bytes of log files before rolling.
+func (c Config) max() int64 {
+	if c.MaxSize == 0 {
+		return int64(defaultMaxSize * megabyte)
+	}
+	return int64(c.MaxSize) * int64(megabyte)
+}
@@ -0,0 +1,62 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUpdateConfig(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestUpdateConfig", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	b := []byte("boo!boo!boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+
+	// the log file is rotated straight away if it's too large for the new
+	// MaxSize.
+	c := l.Config()
+	c.MaxSize = 10
+	c.MaxBackups = 1
+	newFakeTime()
+	isNil(l.UpdateConfig(c), t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(filename, []byte{}, t)
+
+	// and the new settings apply to the cleanup.
+	for i := 0; i < 2; i++ {
+		_, err = l.Write([]byte("foo!"))
+		isNil(err, t)
+		newFakeTime()
+		isNil(l.Rotate(), t)
+	}
+	isNil(l.Mill(context.Background()), t)
+	fileCount(dir, 2, t)
+
+	// settings the backups can't be named with are rejected.
+	bad := c
+	bad.MaxSize = 1000
+	bad.TimeFormat = "2006/01/02"
+	notNil(l.UpdateConfig(bad), t)
+	equals(10, l.MaxSize, t)
+
+	// a new Filename is opened by the next write.
+	c.Filename = filepath.Join(dir, "bar.log")
+	isNil(l.UpdateConfig(c), t)
+	_, err = l.Write([]byte("moo!"))
+	isNil(err, t)
+	existsWithContent(c.Filename, []byte("moo!"), t)
+	existsWithContent(filename, []byte{}, t)
+}
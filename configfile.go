@@ -0,0 +1,129 @@
+package lumberjack
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v2"
+)
+
+// configPollInterval is how often the config file given to WatchConfigFile is
+// checked for changes.  It is a var so we can shorten it during tests.
+var configPollInterval = time.Second
+
+// FromConfigFile returns a Logger with the settings in the config file at
+// path, which is JSON, YAML or TOML depending on its extension (.json, .yaml
+// or .yml, .toml), with the same keys as when a Logger is unmarshaled.  Call
+// WatchConfigFile on the Logger to apply changes to the file while it is in
+// use.
+func FromConfigFile(path string) (*Logger, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read config file: %s", err)
+	}
+	l := &Logger{}
+	if err := decodeConfig(path, data, l); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// WatchConfigFile checks the config file at path, as read by FromConfigFile,
+// for changes every second, and applies them to the Logger with UpdateConfig.
+// Only the settings of Config are applied; changes to the others take a new
+// Logger.  Settings left out of the file keep their current values, so a file
+// with only the changed settings will do.  Errors reading the file or applying
+// the settings are reported to OnError, and the Logger keeps its settings
+// until the file is fixed.  It replaces the config file of an earlier call.
+// The file is no longer watched once Close is called, until WatchConfigFile
+// is called again.
+//
+// Replace the file in one go, e.g. by renaming a new file over it, so it isn't
+// read while half written.  An empty file is taken to be in the middle of
+// being written, and ignored.
+func (l *Logger) WatchConfigFile(path string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopConfigWatch()
+
+	// changes are relative to the file as it is now.
+	last, _ := ioutil.ReadFile(path)
+	interval := configPollInterval
+	stop := make(chan struct{})
+	l.configStop = stop
+	go func() {
+		for {
+			due := make(chan struct{})
+			cancel := l.scheduleWork(WorkConfig, interval, func() { close(due) })
+			select {
+			case <-stop:
+				cancel()
+				return
+			case <-due:
+				last = l.reloadConfig(path, last)
+			}
+		}
+	}()
+}
+
+// stopConfigWatch stops watching the config file given to WatchConfigFile.
+// It is called with the Logger locked.
+func (l *Logger) stopConfigWatch() {
+	if l.configStop != nil {
+		close(l.configStop)
+		l.configStop = nil
+	}
+}
+
+// reloadConfig applies the config file at path to the Logger if its contents
+// differ from last, which were read before, and returns its contents.  Each
+// change is applied, or reported to OnError, once.
+func (l *Logger) reloadConfig(path string, last []byte) []byte {
+	data, err := ioutil.ReadFile(path)
+	switch {
+	case err != nil && last == nil:
+		// reported already.
+		return nil
+	case err != nil:
+		err = fmt.Errorf("can't read config file: %s", err)
+		data = nil
+	case len(data) == 0 || bytes.Equal(data, last):
+		return last
+	default:
+		// settings left out of the file keep their values.
+		c := l.Config()
+		if err = decodeConfig(path, data, &c); err == nil {
+			err = l.UpdateConfig(c)
+		}
+	}
+	if err != nil && l.OnError != nil {
+		l.OnError(err)
+	}
+	return data
+}
+
+// decodeConfig decodes the contents of the config file at path into v, in the
+// format given by the file's extension.
+func decodeConfig(path string, data []byte, v interface{}) error {
+	var err error
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, v)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, v)
+	case ".toml":
+		_, err = toml.Decode(string(data), v)
+	default:
+		return fmt.Errorf("can't tell the format of config file %s from its extension", path)
+	}
+	if err != nil {
+		return fmt.Errorf("can't parse config file %s: %s", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package lumberjack
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFromConfigFile(t *testing.T) {
+	dir := makeTempDir("TestFromConfigFile", t)
+	defer os.RemoveAll(dir)
+
+	configs := map[string]string{
+		"lumberjack.json": `{"filename": "foo", "maxsize": 5, "compress": true}`,
+		"lumberjack.yaml": "filename: foo\nmaxsize: 5\ncompress: true\n",
+		"lumberjack.yml":  "filename: foo\nmaxsize: 5\ncompress: true\n",
+		"lumberjack.toml": "filename = \"foo\"\nmaxsize = 5\ncompress = true\n",
+	}
+	for name, data := range configs {
+		path := filepath.Join(dir, name)
+		isNil(ioutil.WriteFile(path, []byte(data), 0644), t)
+		l, err := FromConfigFile(path)
+		isNil(err, t)
+		equals("foo", l.Filename, t)
+		equals(5, l.Config().MaxSize, t)
+		equals(true, l.Compress, t)
+	}
+
+	path := filepath.Join(dir, "lumberjack.conf")
+	isNil(ioutil.WriteFile(path, []byte("maxsize: 5\n"), 0644), t)
+	_, err := FromConfigFile(path)
+	notNil(err, t)
+
+	_, err = FromConfigFile(filepath.Join(dir, "missing.json"))
+	notNil(err, t)
+}
+
+func TestWatchConfigFile(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	configPollInterval = time.Millisecond
+	defer func() { configPollInterval = time.Second }()
+
+	dir := makeTempDir("TestWatchConfigFile", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	path := filepath.Join(dir, "lumberjack.yaml")
+	write := func(data string) {
+		// the Logger may check the file at any time.
+		isNil(ioutil.WriteFile(path+".tmp", []byte(data), 0644), t)
+		isNil(os.Rename(path+".tmp", path), t)
+	}
+	config := func(maxsize int) {
+		write(fmt.Sprintf("filename: %s\nmaxsize: %d\n", filename, maxsize))
+	}
+	config(100)
+
+	l, err := FromConfigFile(path)
+	isNil(err, t)
+	errs := make(chan error, 10)
+	l.OnError = func(err error) { errs <- err }
+	rotated := make(chan RotationInfo, 10)
+	l.OnRotate = func(r RotationInfo) { rotated <- r }
+	defer l.Close()
+	l.WatchConfigFile(path)
+
+	b := []byte("boo!boo!")
+	_, err = l.Write(b)
+	isNil(err, t)
+
+	// the log file is rotated once the Logger notices the smaller MaxSize.
+	newFakeTime()
+	config(5)
+	select {
+	case <-rotated:
+	case <-time.After(time.Second):
+		t.Fatal("the smaller MaxSize didn't rotate the log file")
+	}
+	isNil(l.Mill(context.Background()), t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(filename, []byte{}, t)
+
+	// a broken config file is reported, and leaves the settings alone.
+	write("maxsize: [\n")
+	select {
+	case err := <-errs:
+		notNil(err, t)
+	case <-time.After(time.Second):
+		t.Fatal("the broken config file wasn't reported")
+	}
+	equals(5, l.Config().MaxSize, t)
+
+	// settings left out of the file keep their values.
+	write("maxsize: 8\n")
+	for i := 0; i < 100 && l.Config().MaxSize != 8; i++ {
+		<-time.After(10 * time.Millisecond)
+	}
+	equals(8, l.Config().MaxSize, t)
+	equals(filename, l.Config().Filename, t)
+}
@@ -0,0 +1,174 @@
+package lumberjack
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// dedupRotated removes the backups rotated since the mill last ran that are
+// identical to the backup before them, and records their rotation times in
+// the metadata of that backup instead.
+func (l *Logger) dedupRotated() error {
+	l.rotatedMu.Lock()
+	rotated := append([]string(nil), l.rotated...)
+	l.rotatedMu.Unlock()
+	if len(rotated) == 0 {
+		return nil
+	}
+
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		return err
+	}
+	files, err := l.oldLogFiles()
+	unlock()
+	if err != nil {
+		return err
+	}
+	index := make(map[string]int, len(files))
+	for i, f := range files {
+		index[filepath.Join(f.dir, f.Name())] = i
+	}
+	removed := make(map[int]bool)
+
+	for _, name := range rotated {
+		i, ok := index[name]
+		if !ok || l.isReading(name) {
+			continue
+		}
+		// files are sorted newest first, so the one before it comes next.
+		prev := i + 1
+		for removed[prev] {
+			prev++
+		}
+		if prev >= len(files) {
+			continue
+		}
+		f, older := files[i], files[prev]
+		olderName := filepath.Join(older.dir, older.Name())
+		same, err := sameContent(name, olderName)
+		if err != nil {
+			return err
+		}
+		if !same {
+			continue
+		}
+		if done, err := l.removeDuplicate(name, olderName, f.timestamp); err != nil || !done {
+			return err
+		}
+		removed[i] = true
+		l.countDeduplicated()
+	}
+	return nil
+}
+
+// recordDuplicate records in the metadata of the backup that a backup
+// identical to it was rotated at t.
+func (l *Logger) recordDuplicate(backup string, t time.Time) error {
+	meta, err := readMetadata(backup)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return fmt.Errorf("can't read backup metadata: %s", err)
+		}
+		meta = RotationInfo{NewPath: backup}
+	}
+	meta.Duplicates = append(meta.Duplicates, t)
+	return writeMetadata(backup, meta)
+}
+
+// sameContent reports whether the two backups have the same content once
+// decompressed.
+func sameContent(a, b string) (bool, error) {
+	ra, err := openDecompressed(a)
+	if err != nil {
+		return false, err
+	}
+	defer ra.Close()
+	rb, err := openDecompressed(b)
+	if err != nil {
+		return false, err
+	}
+	defer rb.Close()
+
+	bufA, bufB := bufio.NewReader(ra), bufio.NewReader(rb)
+	chunkA, chunkB := make([]byte, 32*1024), make([]byte, 32*1024)
+	for {
+		na, errA := io.ReadFull(bufA, chunkA)
+		nb, errB := io.ReadFull(bufB, chunkB)
+		if !bytes.Equal(chunkA[:na], chunkB[:nb]) {
+			return false, nil
+		}
+		endA := errA == io.EOF || errA == io.ErrUnexpectedEOF
+		endB := errB == io.EOF || errB == io.ErrUnexpectedEOF
+		if errA != nil && !endA {
+			return false, fmt.Errorf("can't read backup to compare: %s", errA)
+		}
+		if errB != nil && !endB {
+			return false, fmt.Errorf("can't read backup to compare: %s", errB)
+		}
+		if endA || endB {
+			return endA && endB, nil
+		}
+	}
+}
+
+// decompressedFile reads a backup, decompressed.
+type decompressedFile struct {
+	io.Reader
+	f   *os.File
+	dec io.Closer
+}
+
+// openDecompressed opens the named backup for reading, decompressing it if it
+// is compressed.
+func openDecompressed(name string) (*decompressedFile, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, fmt.Errorf("can't open backup to compare: %s", err)
+	}
+	d := &decompressedFile{Reader: f, f: f}
+	if c := compressorFor(name); c != nil {
+		dec, err := c.NewReader(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("can't decompress backup to compare: %s", err)
+		}
+		d.Reader, d.dec = dec, dec
+	}
+	return d, nil
+}
+
+func (d *decompressedFile) Close() error {
+	if d.dec != nil {
+		d.dec.Close()
+	}
+	return d.f.Close()
+}
+
+// removeDuplicate removes the backup name, recording its rotation time in the
+// metadata of the older backup with the same content, and reports whether it
+// did so, which it doesn't if either has gone since the backups were scanned.
+func (l *Logger) removeDuplicate(name, olderName string, t time.Time) (bool, error) {
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		return false, err
+	}
+	defer unlock()
+	for _, fn := range []string{name, olderName} {
+		if _, err := os.Lstat(fn); os.IsNotExist(err) {
+			return false, nil
+		}
+	}
+	if err := l.recordDuplicate(olderName, t); err != nil {
+		return false, err
+	}
+	if err := removeBackup(name); err != nil {
+		return false, fmt.Errorf("can't remove duplicate backup: %s", err)
+	}
+	return true, nil
+}
@@ -0,0 +1,90 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestDedupBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	// backup names only hold milliseconds, which the times are taken from.
+	fakeCurrentTime = fakeCurrentTime.Truncate(time.Millisecond)
+
+	dir := makeTempDir("TestDedupBackups", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      100,
+		DedupBackups: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+
+	// two empty rotations in a row, like from a scheduler.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	empty := backupFile(dir)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	duplicate := fakeTime()
+	isNil(l.Mill(context.Background()), t)
+
+	existsWithContent(first, []byte("boo!"), t)
+	existsWithContent(empty, []byte{}, t)
+	notExist(backupFile(dir), t)
+	meta, err := readMetadata(empty)
+	isNil(err, t)
+	equals(1, len(meta.Duplicates), t)
+	assert(duplicate.Equal(meta.Duplicates[0]), t, "expected the time of the duplicate, got %v", meta.Duplicates[0])
+	equals(int64(1), l.Stats().Deduplicated, t)
+}
+
+func TestDedupBackupsCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDedupBackupsCompressed", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      100,
+		Compress:     true,
+		DedupBackups: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	first := backupFile(dir) + compressSuffix
+	exists(first, t)
+
+	// the same content again is compared with the compressed backup.
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	notExist(backupFile(dir), t)
+	notExist(backupFile(dir)+compressSuffix, t)
+
+	// different content is kept.
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	exists(backupFile(dir)+compressSuffix, t)
+	fileCount(dir, 4, t)
+}
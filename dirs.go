@@ -0,0 +1,83 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+)
+
+// mkdirAll creates the directory with the given name along with any missing
+// parents, applying DirMode, DirGroup and SELinuxLabel to the directories it
+// creates.
+func (l *Logger) mkdirAll(dir string) error {
+	// find the directories that need creating, outermost first.
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil {
+			break
+		}
+		missing = append([]string{d}, missing...)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	mode := l.DirMode
+	if mode == 0 {
+		mode = 0755
+	}
+	gid := -1
+	if l.DirGroup != "" {
+		var err error
+		if gid, err = lookupGroup(l.DirGroup); err != nil {
+			return err
+		}
+	}
+
+	for _, d := range missing {
+		if err := os.Mkdir(d, mode); err != nil {
+			if os.IsExist(err) {
+				// created by someone else in the meantime.
+				continue
+			}
+			return err
+		}
+		if l.DirMode != 0 && !l.UseUmask {
+			if err := os.Chmod(d, mode); err != nil {
+				return fmt.Errorf("can't set mode of directory: %s", err)
+			}
+		}
+		if gid != -1 {
+			if err := os.Chown(d, -1, gid); err != nil {
+				return fmt.Errorf("can't set group of directory: %s", err)
+			}
+		}
+		if l.SELinuxLabel != "" {
+			if err := setSELinuxLabel(d, l.SELinuxLabel); err != nil {
+				return fmt.Errorf("can't set SELinux label of directory: %s", err)
+			}
+		}
+	}
+	return nil
+}
+
+// lookupGroup returns the ID of the group with the given name or ID.
+func lookupGroup(group string) (int, error) {
+	if gid, err := strconv.Atoi(group); err == nil {
+		return gid, nil
+	}
+	g, err := user.LookupGroup(group)
+	if err != nil {
+		return 0, fmt.Errorf("can't find group %s: %s", group, err)
+	}
+	gid, err := strconv.Atoi(g.Gid)
+	if err != nil {
+		return 0, fmt.Errorf("group %s has a non-numeric ID %s", group, g.Gid)
+	}
+	return gid, nil
+}
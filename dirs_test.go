@@ -0,0 +1,62 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestDirMode(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDirMode", t)
+	defer os.RemoveAll(dir)
+	isNil(os.Chmod(dir, 0755), t)
+
+	backupDir := filepath.Join(dir, "backups", "nested")
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:  filename,
+		MaxSize:   10,
+		BackupDir: backupDir,
+		DirMode:   0750,
+		DirGroup:  strconv.Itoa(os.Getgid()),
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	exists(backupFile(backupDir), t)
+
+	for _, d := range []string{filepath.Dir(backupDir), backupDir} {
+		info, err := os.Stat(d)
+		isNil(err, t)
+		equals(os.FileMode(0750), info.Mode().Perm(), t)
+	}
+
+	// the directory that already existed is left alone.
+	info, err := os.Stat(dir)
+	isNil(err, t)
+	equals(os.FileMode(0755), info.Mode().Perm(), t)
+}
+
+func TestDirGroupUnknown(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDirGroupUnknown", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{
+		Filename: filepath.Join(dir, "logs", "foobar.log"),
+		DirGroup: "no-such-group-for-lumberjack",
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!"))
+	notNil(err, t)
+	notExist(filepath.Join(dir, "logs"), t)
+}
@@ -0,0 +1,16 @@
+package lumberjack
+
+import "syscall"
+
+// diskUsage returns the space available to unprivileged users and the space
+// used on the filesystem holding dir, as reported by statfs.
+func diskUsage(dir string) (free, used int64, ok bool) {
+	var fs syscall.Statfs_t
+	if err := syscall.Statfs(dir, &fs); err != nil {
+		return 0, 0, false
+	}
+	bsize := int64(fs.Bsize)
+	free = int64(fs.Bavail) * bsize
+	used = int64(fs.Blocks-fs.Bfree) * bsize
+	return free, used, true
+}
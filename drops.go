@@ -0,0 +1,109 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// Reasons for writes to be dropped, recorded in the DropJournal.
+const (
+	// DropBufferFull is for writes an AsyncLogger dropped because its buffer
+	// was full.
+	DropBufferFull = "buffer full"
+
+	// DropWriteError is for writes that failed, e.g. because the disk was
+	// full.
+	DropWriteError = "write error"
+
+	// DropLowSpace is for writes rejected because of RejectLowSpaceWrites.
+	DropLowSpace = "low disk space"
+
+	// DropRateLimited is for writes dropped or rejected because of
+	// MaxBytesPerSecond.
+	DropRateLimited = "rate limited"
+)
+
+// dropWindowMax is the longest time covered by a DropRecord, so that the
+// DropJournal is kept up to date during long outages.
+const dropWindowMax = time.Minute
+
+// DropRecord is a line of the DropJournal, which accounts for the writes lost
+// for the same reason during a window of time.
+type DropRecord struct {
+	// Start and End are the times of the first and last lost write.
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+
+	// Reason is why the writes were lost, e.g. DropBufferFull.
+	Reason string `json:"reason"`
+
+	// Writes and Bytes are the number of writes lost, and the number of
+	// bytes they held.
+	Writes int64 `json:"writes"`
+	Bytes  int64 `json:"bytes"`
+}
+
+// recordDrop accounts for a write of n bytes lost for the given reason.
+func (l *Logger) recordDrop(reason string, n int) {
+	if l.DropJournal == "" {
+		return
+	}
+	now := l.now()
+	l.dropsMu.Lock()
+	defer l.dropsMu.Unlock()
+	r := l.drops[reason]
+	if r != nil && now.Sub(r.Start) >= dropWindowMax {
+		l.journalDrops(r)
+		r = nil
+	}
+	if r == nil {
+		if l.drops == nil {
+			l.drops = make(map[string]*DropRecord)
+		}
+		r = &DropRecord{Start: now, Reason: reason}
+		l.drops[reason] = r
+	}
+	r.End = now
+	r.Writes++
+	r.Bytes += int64(n)
+}
+
+// endDrops records the window of writes lost for the given reason, if any, in
+// the DropJournal, since writes go through again.
+func (l *Logger) endDrops(reason string) {
+	if l.DropJournal == "" {
+		return
+	}
+	l.dropsMu.Lock()
+	defer l.dropsMu.Unlock()
+	if r := l.drops[reason]; r != nil {
+		l.journalDrops(r)
+	}
+}
+
+// endAllDrops records all the windows of lost writes in the DropJournal.
+func (l *Logger) endAllDrops() {
+	l.dropsMu.Lock()
+	defer l.dropsMu.Unlock()
+	for _, r := range l.drops {
+		l.journalDrops(r)
+	}
+}
+
+// journalDrops appends a record to the DropJournal, and closes its window.
+// It is called with dropsMu held.  Errors are ignored, since there's nowhere
+// left to report them.
+func (l *Logger) journalDrops(r *DropRecord) {
+	delete(l.drops, r.Reason)
+	b, err := json.Marshal(r)
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(l.DropJournal, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(b, '\n'))
+}
@@ -0,0 +1,100 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// readDropJournal returns the records of the drop journal.
+func readDropJournal(name string, t testing.TB) []DropRecord {
+	b, err := ioutil.ReadFile(name)
+	isNilUp(err, t, 1)
+	var records []DropRecord
+	for _, line := range strings.Split(strings.TrimSpace(string(b)), "\n") {
+		var r DropRecord
+		isNilUp(json.Unmarshal([]byte(line), &r), t, 1)
+		records = append(records, r)
+	}
+	return records
+}
+
+func TestDropJournalAsync(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDropJournalAsync", t)
+	defer os.RemoveAll(dir)
+
+	journal := filepath.Join(dir, "drops.jsonl")
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		DropJournal: journal,
+	}, 8, DropNewest)
+	defer a.Close()
+
+	stallAsync(a, t)
+	for _, s := range []string{"boo!", "foo!"} {
+		_, err := a.Write([]byte(s))
+		isNil(err, t)
+	}
+	start := fakeTime()
+	for _, s := range []string{"bar!", "bazqux!"} {
+		_, err := a.Write([]byte(s))
+		equals(ErrDropped, err, t)
+		fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	}
+	notExist(journal, t)
+
+	// the window is recorded once writes go through again.
+	a.Logger.mu.Unlock()
+	isNil(a.Flush(), t)
+	_, err := a.Write([]byte("boo!"))
+	isNil(err, t)
+	records := readDropJournal(journal, t)
+	equals(1, len(records), t)
+	equals(DropBufferFull, records[0].Reason, t)
+	equals(int64(2), records[0].Writes, t)
+	equals(int64(11), records[0].Bytes, t)
+	assert(records[0].Start.Equal(start), t, "expected the window to start at %v, got %v", start, records[0].Start)
+	assert(records[0].End.Equal(start.Add(time.Second)), t, "expected the window to end a second later, got %v", records[0].End)
+}
+
+func TestDropJournalWriteError(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDropJournalWriteError", t)
+	defer os.RemoveAll(dir)
+
+	journal := filepath.Join(dir, "drops.jsonl")
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		DropJournal: journal,
+	}
+	defer l.Close()
+
+	file_Write = func(f *os.File, p []byte) (int, error) {
+		return 0, errors.New("no space left on device")
+	}
+	_, err := l.Write([]byte("boo!"))
+	notNil(err, t)
+	file_Write = (*os.File).Write
+
+	// an open window is recorded when the Logger is closed.
+	isNil(l.Close(), t)
+	records := readDropJournal(journal, t)
+	equals(1, len(records), t)
+	equals(DropWriteError, records[0].Reason, t)
+	equals(int64(1), records[0].Writes, t)
+	equals(int64(4), records[0].Bytes, t)
+}
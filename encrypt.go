@@ -0,0 +1,226 @@
+package lumberjack
+
+import (
+	"bufio"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Encrypted backups start with a header holding encryptMagic, the length of
+// the key ID as a single byte, the key ID and a random nonce prefix.  The
+// content follows as a sequence of AES-GCM sealed chunks of encryptChunkSize
+// bytes of plaintext, each sealed with the nonce prefix followed by the index
+// of the chunk.  The last chunk, which may be empty, is marked as such in its
+// additional data, so that truncated files are detected.
+const (
+	encryptMagic      = "LJE\x01"
+	encryptChunkSize  = 64 * 1024
+	encryptNonceSize  = 12
+	encryptPrefixSize = encryptNonceSize - 4
+)
+
+// Keyring holds the keys used to encrypt backups.  Each encrypted backup
+// records the ID of the key it was encrypted with, so keys can be rotated by
+// adding a new key and making it current, while keeping the old ones around
+// for as long as backups encrypted with them are retained.
+type Keyring struct {
+	// Keys maps key IDs to AES keys, which are 16, 24 or 32 bytes long.  Key
+	// IDs are at most 255 bytes long.
+	Keys map[string][]byte
+
+	// Current is the ID of the key used to encrypt new backups.
+	Current string
+}
+
+// Encrypt returns a writer encrypting everything written to it with the
+// current key and writing the result to w.  The writer must be closed to
+// write the end of the encrypted content; closing it doesn't close w.
+func (k *Keyring) Encrypt(w io.Writer) (io.WriteCloser, error) {
+	aead, err := k.aead(k.Current)
+	if err != nil {
+		return nil, err
+	}
+	if len(k.Current) > 255 {
+		return nil, fmt.Errorf("key ID %q is too long", k.Current)
+	}
+	prefix := make([]byte, encryptPrefixSize)
+	if _, err := io.ReadFull(rand.Reader, prefix); err != nil {
+		return nil, fmt.Errorf("can't generate nonce: %s", err)
+	}
+
+	header := []byte(encryptMagic)
+	header = append(header, byte(len(k.Current)))
+	header = append(header, k.Current...)
+	header = append(header, prefix...)
+	if _, err := w.Write(header); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{
+		w:     w,
+		aead:  aead,
+		nonce: append(prefix, 0, 0, 0, 0),
+		buf:   make([]byte, 0, encryptChunkSize),
+	}, nil
+}
+
+// Decrypt returns a reader decrypting the encrypted content read from r with
+// the key it was encrypted with, which must be in the keyring.
+func (k *Keyring) Decrypt(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(encryptMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("can't read encryption header: %s", err)
+	}
+	if string(header[:len(encryptMagic)]) != encryptMagic {
+		return nil, errors.New("content is not encrypted by lumberjack")
+	}
+	rest := make([]byte, int(header[len(encryptMagic)])+encryptPrefixSize)
+	if _, err := io.ReadFull(br, rest); err != nil {
+		return nil, fmt.Errorf("can't read encryption header: %s", err)
+	}
+	id := string(rest[:len(rest)-encryptPrefixSize])
+	aead, err := k.aead(id)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{
+		r:     br,
+		aead:  aead,
+		nonce: append(rest[len(id):], 0, 0, 0, 0),
+		buf:   make([]byte, encryptChunkSize+aead.Overhead()),
+	}, nil
+}
+
+// aead returns the cipher for the key with the given ID.
+func (k *Keyring) aead(id string) (cipher.AEAD, error) {
+	key, ok := k.Keys[id]
+	if !ok {
+		return nil, fmt.Errorf("unknown encryption key %q", id)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("invalid encryption key %q: %s", id, err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// chunkAdditionalData returns the additional data authenticated along with a
+// chunk.
+func chunkAdditionalData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+// encryptWriter seals the content written to it chunk by chunk.
+type encryptWriter struct {
+	w     io.Writer
+	aead  cipher.AEAD
+	nonce []byte
+	chunk uint32
+	buf   []byte
+	err   error
+}
+
+func (e *encryptWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n := 0
+	for len(p) > 0 {
+		// a full chunk is only sealed once there's more to write, since the
+		// last chunk is sealed differently.
+		if len(e.buf) == encryptChunkSize {
+			if e.err = e.seal(false); e.err != nil {
+				return n, e.err
+			}
+		}
+		c := copy(e.buf[len(e.buf):cap(e.buf)], p)
+		e.buf = e.buf[:len(e.buf)+c]
+		p = p[c:]
+		n += c
+	}
+	return n, nil
+}
+
+// Close seals the last chunk.
+func (e *encryptWriter) Close() error {
+	if e.err != nil {
+		return e.err
+	}
+	e.err = e.seal(true)
+	if e.err == nil {
+		e.err = errors.New("write to closed encrypted writer")
+		return nil
+	}
+	return e.err
+}
+
+func (e *encryptWriter) seal(last bool) error {
+	if e.chunk == ^uint32(0) {
+		return errors.New("encrypted content is too long")
+	}
+	binary.BigEndian.PutUint32(e.nonce[encryptPrefixSize:], e.chunk)
+	e.chunk++
+	sealed := e.aead.Seal(nil, e.nonce, e.buf, chunkAdditionalData(last))
+	e.buf = e.buf[:0]
+	_, err := e.w.Write(sealed)
+	return err
+}
+
+// decryptReader opens the chunks read from r.
+type decryptReader struct {
+	r     *bufio.Reader
+	aead  cipher.AEAD
+	nonce []byte
+	chunk uint32
+	buf   []byte
+	plain []byte
+	done  bool
+	err   error
+}
+
+func (d *decryptReader) Read(p []byte) (int, error) {
+	for len(d.plain) == 0 {
+		if d.err != nil {
+			return 0, d.err
+		}
+		if d.done {
+			return 0, io.EOF
+		}
+		d.err = d.open()
+	}
+	n := copy(p, d.plain)
+	d.plain = d.plain[n:]
+	return n, nil
+}
+
+// open reads and opens the next chunk.
+func (d *decryptReader) open() error {
+	n, err := io.ReadFull(d.r, d.buf)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		d.done = true
+	case err != nil:
+		return err
+	default:
+		// a full chunk is the last one if nothing follows it.
+		if _, err := d.r.Peek(1); err == io.EOF {
+			d.done = true
+		}
+	}
+	binary.BigEndian.PutUint32(d.nonce[encryptPrefixSize:], d.chunk)
+	d.chunk++
+	plain, err := d.aead.Open(d.buf[:0], d.nonce, d.buf[:n], chunkAdditionalData(d.done))
+	if err != nil {
+		return fmt.Errorf("can't decrypt content: %s", err)
+	}
+	d.plain = plain
+	return nil
+}
@@ -0,0 +1,98 @@
+package lumberjack
+
+import (
+	"bytes"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func testKeyring() *Keyring {
+	return &Keyring{
+		Keys: map[string][]byte{
+			"2023": bytes.Repeat([]byte{1}, 16),
+			"2024": bytes.Repeat([]byte{2}, 32),
+		},
+		Current: "2023",
+	}
+}
+
+func encrypt(t testing.TB, k *Keyring, content []byte) []byte {
+	buf := &bytes.Buffer{}
+	w, err := k.Encrypt(buf)
+	isNil(err, t)
+	_, err = w.Write(content)
+	isNil(err, t)
+	isNil(w.Close(), t)
+	return buf.Bytes()
+}
+
+func TestEncryptRoundTrip(t *testing.T) {
+	k := testKeyring()
+	for _, size := range []int{0, 10, encryptChunkSize, 3*encryptChunkSize + 7} {
+		content := bytes.Repeat([]byte("x"), size)
+		encrypted := encrypt(t, k, content)
+		assert(!bytes.Contains(encrypted, []byte("xxxxxxxx")), t, "content of size %d not encrypted", size)
+
+		r, err := k.Decrypt(bytes.NewReader(encrypted))
+		isNil(err, t)
+		decrypted, err := ioutil.ReadAll(r)
+		isNil(err, t)
+		equals(size, len(decrypted), t)
+		assert(bytes.Equal(content, decrypted), t, "content of size %d changed", size)
+	}
+}
+
+func TestEncryptKeyRotation(t *testing.T) {
+	k := testKeyring()
+	old := encrypt(t, k, []byte("boo!"))
+
+	k.Current = "2024"
+	current := encrypt(t, k, []byte("baa!"))
+
+	// both are decrypted with the key they were encrypted with.
+	for content, encrypted := range map[string][]byte{"boo!": old, "baa!": current} {
+		r, err := k.Decrypt(bytes.NewReader(encrypted))
+		isNil(err, t)
+		decrypted, err := ioutil.ReadAll(r)
+		isNil(err, t)
+		equals(content, string(decrypted), t)
+	}
+
+	// once the old key is retired, the old content can't be decrypted.
+	delete(k.Keys, "2023")
+	_, err := k.Decrypt(bytes.NewReader(old))
+	notNil(err, t)
+	assert(strings.Contains(err.Error(), `"2023"`), t, "unexpected error: %v", err)
+}
+
+func TestEncryptUnknownCurrentKey(t *testing.T) {
+	k := testKeyring()
+	k.Current = "2025"
+	_, err := k.Encrypt(&bytes.Buffer{})
+	notNil(err, t)
+}
+
+func TestDecryptTampered(t *testing.T) {
+	k := testKeyring()
+	content := bytes.Repeat([]byte("x"), 2*encryptChunkSize)
+	encrypted := encrypt(t, k, content)
+
+	tampered := append([]byte{}, encrypted...)
+	tampered[len(tampered)/2] ^= 1
+	r, err := k.Decrypt(bytes.NewReader(tampered))
+	isNil(err, t)
+	_, err = ioutil.ReadAll(r)
+	notNil(err, t)
+
+	// dropping the last chunk is detected too.
+	// GCM adds a 16 byte tag to each chunk.
+	truncated := encrypted[:len(encrypted)-encryptChunkSize-16]
+	r, err = k.Decrypt(bytes.NewReader(truncated))
+	isNil(err, t)
+	_, err = ioutil.ReadAll(r)
+	notNil(err, t)
+
+	_, err = k.Decrypt(strings.NewReader("not encrypted"))
+	notNil(err, t)
+}
@@ -0,0 +1,179 @@
+package lumberjack
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// encryptSuffix is appended to the name of backups encrypted with a Keyring.
+const encryptSuffix = ".enc"
+
+// Encrypter encrypts backups once they have reached their final form.
+type Encrypter interface {
+	// Suffix is appended to the name of backups encrypted with the
+	// Encrypter, e.g. ".enc".  It must be unique among the registered
+	// Encrypters, as it identifies the Encrypter of a backup.
+	Suffix() string
+
+	// Encrypt returns a writer encrypting everything written to it to w.
+	// Closing the writer must flush the encrypted data, but not close w.
+	Encrypt(w io.Writer) (io.WriteCloser, error)
+}
+
+// Decrypter is implemented by Encrypters that can decrypt the backups they
+// encrypted, so that OpenBackup and Restore can read them.
+type Decrypter interface {
+	// Decrypt returns a reader decrypting the data read from r.
+	Decrypt(r io.Reader) (io.Reader, error)
+}
+
+// Suffix implements Encrypter.
+func (k *Keyring) Suffix() string {
+	return encryptSuffix
+}
+
+var (
+	encryptersMu sync.RWMutex
+	encrypters   = map[string]Encrypter{}
+)
+
+// RegisterEncrypter makes an Encrypter available under the given name, for
+// use as Encryption.  Backups encrypted with any of the registered Encrypters
+// are recognized by their suffix.  It is meant to be called at
+// initialization, with keys loaded from wherever secrets are kept:
+//
+//	lumberjack.RegisterEncrypter("aes", &lumberjack.Keyring{
+//		Keys:    map[string][]byte{"2024-01": key},
+//		Current: "2024-01",
+//	})
+func RegisterEncrypter(name string, e Encrypter) {
+	encryptersMu.Lock()
+	defer encryptersMu.Unlock()
+	encrypters[name] = e
+}
+
+// lookupEncrypter returns the Encrypter registered with the given name.
+func lookupEncrypter(name string) (Encrypter, bool) {
+	encryptersMu.RLock()
+	defer encryptersMu.RUnlock()
+	e, ok := encrypters[name]
+	return e, ok
+}
+
+// encrypterFor returns the Encrypter that encrypted the backup with the given
+// name, going by its suffix, or nil if the backup isn't encrypted.
+func encrypterFor(name string) Encrypter {
+	encryptersMu.RLock()
+	defer encryptersMu.RUnlock()
+	for _, e := range encrypters {
+		if strings.HasSuffix(name, e.Suffix()) {
+			return e
+		}
+	}
+	return nil
+}
+
+// trimEncryptSuffix returns the name of a backup without the suffix of the
+// Encrypter that encrypted it, if any.
+func trimEncryptSuffix(name string) string {
+	if e := encrypterFor(name); e != nil {
+		return strings.TrimSuffix(name, e.Suffix())
+	}
+	return name
+}
+
+// isEncrypted reports whether the backup with the given name is encrypted.
+func isEncrypted(name string) bool {
+	return encrypterFor(name) != nil
+}
+
+// encrypt encrypts the backup with the Encrypter selected by Encryption,
+// replacing it with the encrypted backup, whose name it returns.
+func (l *Logger) encrypt(name string) (string, error) {
+	e, ok := lookupEncrypter(l.Encryption)
+	if !ok {
+		return "", fmt.Errorf("unknown encryption %q", l.Encryption)
+	}
+	dst := name + e.Suffix()
+	if err := encryptFile(name, dst, e, l.BackupFileMode); err != nil {
+		return "", err
+	}
+	// a signature or checksum of the plaintext backup mustn't outlive it.
+	_ = os.Remove(name + signatureSuffix)
+	removeChecksums(name)
+	return dst, nil
+}
+
+// encryptFile encrypts src to dst with the Encrypter, removing src if
+// successful.  dst gets the given mode, or that of src if it is 0.
+func encryptFile(src, dst string, e Encrypter, mode os.FileMode) (err error) {
+	f, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("can't open backup to encrypt: %s", err)
+	}
+	defer f.Close()
+	fi, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("can't stat backup to encrypt: %s", err)
+	}
+	if err := chown(dst, fi); err != nil {
+		return fmt.Errorf("can't chown encrypted backup: %s", err)
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, fi.Mode())
+	if err != nil {
+		return fmt.Errorf("can't create encrypted backup: %s", err)
+	}
+	defer out.Close()
+	if err := out.Chmod(backupMode(mode, fi)); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("can't set mode of encrypted backup: %s", err)
+	}
+	defer func() {
+		if err != nil {
+			// never leave a partially encrypted backup around.
+			os.Remove(dst)
+			err = fmt.Errorf("can't encrypt backup: %s", err)
+		}
+	}()
+
+	w, err := e.Encrypt(out)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(w, f); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Remove(src)
+}
+
+// decryptBackup returns a reader decrypting the backup with the given name,
+// read from r, or r itself if the backup isn't encrypted.
+func decryptBackup(name string, r io.Reader) (io.Reader, error) {
+	e := encrypterFor(name)
+	if e == nil {
+		return r, nil
+	}
+	d, ok := e.(Decrypter)
+	if !ok {
+		return nil, errors.New("can't decrypt backup: its Encrypter isn't a Decrypter")
+	}
+	dec, err := d.Decrypt(r)
+	if err != nil {
+		return nil, fmt.Errorf("can't decrypt backup: %s", err)
+	}
+	return dec, nil
+}
@@ -0,0 +1,152 @@
+package lumberjack
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestEncryption(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestEncryption", t)
+	defer os.RemoveAll(dir)
+
+	k := testKeyring()
+	RegisterEncrypter("test-aes", k)
+	filename := logFile(dir)
+	var finalized []string
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		Compress:   true,
+		Encryption: "test-aes",
+		Checksum:   "sha256",
+		OnFinalize: func(name string) { finalized = append(finalized, name) },
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+
+	// the backup is compressed, then encrypted, and only then checksummed.
+	first := backupFile(dir) + compressSuffix + encryptSuffix
+	exists(first, t)
+	exists(first+".sha256", t)
+	notExist(backupFile(dir), t)
+	notExist(backupFile(dir)+compressSuffix, t)
+	equals([]string{first}, finalized, t)
+
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(1, len(backups), t)
+	equals(first, backups[0].Path, t)
+	equals(true, backups[0].Compressed, t)
+	equals(true, backups[0].Encrypted, t)
+
+	r, err := l.OpenBackup(first)
+	isNil(err, t)
+	content, err := ioutil.ReadAll(r)
+	isNil(err, t)
+	isNil(r.Close(), t)
+	equals(string(b), string(content), t)
+
+	// encrypted backups count towards MaxBackups like any other.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	notExist(first, t)
+	exists(backupFile(dir)+compressSuffix+encryptSuffix, t)
+	fileCount(dir, 3, t)
+}
+
+func TestRestoreEncrypted(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestoreEncrypted", t)
+	defer os.RemoveAll(dir)
+
+	RegisterEncrypter("test-aes", testKeyring())
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		Encryption: "test-aes",
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	backup := backupFile(dir) + encryptSuffix
+	exists(backup, t)
+
+	newFakeTime()
+	isNil(l.Restore(backup), t)
+	existsWithContent(filename, b, t)
+	notExist(backup, t)
+}
+
+func TestEncryptionUnknown(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestEncryptionUnknown", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		Encryption: "rot13",
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+
+	// the backup is kept as it is rather than lost.
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+}
+
+func TestEncryptionRetry(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestEncryptionRetry", t)
+	defer os.RemoveAll(dir)
+
+	// the Encrypter isn't available yet, e.g. because its key failed to load.
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		Compress:   true,
+		Encryption: "test-aes-late",
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+	backup := backupFile(dir) + compressSuffix
+	exists(backup, t)
+
+	// the compressed backup is encrypted once it can be.
+	RegisterEncrypter("test-aes-late", testKeyring())
+	isNil(l.Mill(context.Background()), t)
+	notExist(backup, t)
+	exists(backup+encryptSuffix, t)
+}
@@ -1,6 +1,7 @@
 package lumberjack_test
 
 import (
+	"fmt"
 	"log"
 
 	"github.com/jfrog/lumberjack/v2"
@@ -17,3 +18,19 @@ func Example() {
 		Compress:   true, // disabled by default
 	})
 }
+
+// Tools that ship or audit backups can list them with Backups rather than
+// parsing their names, which depend on the configuration.
+func ExampleLogger_Backups() {
+	l := &lumberjack.Logger{
+		Filename:  "/var/log/myapp/foo.log",
+		BackupDir: "/var/log/myapp/old",
+	}
+	backups, err := l.Backups(lumberjack.CompressedOnly())
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, b := range backups {
+		fmt.Println(b.Path, b.Timestamp, b.Size)
+	}
+}
@@ -0,0 +1,114 @@
+package lumberjack
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// ErrBackupExists is returned when the log file can't be rotated because a
+// backup with the name picked for it already exists, and ExistingBackups is
+// ExistingFail.
+var ErrBackupExists = errors.New("lumberjack: a backup with the same name already exists")
+
+// ExistingBackupPolicy determines what happens when the name picked for a new
+// backup is already taken, e.g. by a backup left by another process or
+// restored from elsewhere.
+type ExistingBackupPolicy string
+
+const (
+	// ExistingOverwrite moves the log file over the existing backup.
+	ExistingOverwrite ExistingBackupPolicy = ""
+
+	// ExistingFail makes the rotation fail with ErrBackupExists, leaving
+	// the log file and the existing backup alone.
+	ExistingFail ExistingBackupPolicy = "fail"
+
+	// ExistingRename gives the new backup the next free sequence number
+	// after the name picked for it, e.g. foo-2016-11-04T18-30-00.000-001.log.
+	// If the NameCodec can't tell the names apart, the rotation fails with
+	// ErrBackupExists instead.
+	ExistingRename ExistingBackupPolicy = "rename"
+)
+
+// maxRenames is the number of sequence numbers tried by ExistingRename.
+const maxRenames = 1000
+
+// valid reports whether p is one of the known policies.
+func (p ExistingBackupPolicy) valid() bool {
+	switch p {
+	case ExistingOverwrite, ExistingFail, ExistingRename:
+		return true
+	}
+	return false
+}
+
+// resolveExisting returns the name to move the log file to instead of
+// newname according to ExistingBackups, and the name of the existing backup
+// in the way, if any.
+func (l *Logger) resolveExisting(newname string) (name, existing string, err error) {
+	if !backupExists(newname) {
+		return newname, "", nil
+	}
+	switch l.ExistingBackups {
+	case ExistingFail:
+		return "", newname, ErrBackupExists
+	case ExistingRename:
+		renamed, ok := l.nextFreeName(newname)
+		if !ok {
+			return "", newname, ErrBackupExists
+		}
+		return renamed, newname, nil
+	}
+	return newname, newname, nil
+}
+
+// nextFreeName returns the name with the next free sequence number after the
+// one of the given backup name.
+func (l *Logger) nextFreeName(name string) (string, bool) {
+	codec := l.codec()
+	dir, base := filepath.Split(name)
+	t, seq, err := codec.Decode(trimCompressSuffix(base))
+	if err != nil || codec.Encode(t, seq) != base {
+		return "", false
+	}
+	for next := seq + 1; next <= seq+maxRenames; next++ {
+		candidate := filepath.Join(dir, codec.Encode(t, next))
+		if t2, seq2, err := codec.Decode(filepath.Base(candidate)); err != nil || !t2.Equal(t) || seq2 != next {
+			// the codec ignores the sequence number.
+			return "", false
+		}
+		if !backupExists(candidate) {
+			// later backups carry on from here.
+			l.lastStamp, l.lastSeq, l.lastKnown = t, next, true
+			return candidate, true
+		}
+	}
+	return "", false
+}
+
+// backupExists reports whether a backup with the given name exists, whether
+// it is compressed or encrypted or not.
+func backupExists(name string) bool {
+	suffixes := []string{""}
+	compressorsMu.RLock()
+	for _, c := range compressors {
+		suffixes = append(suffixes, c.Suffix())
+	}
+	compressorsMu.RUnlock()
+	plain := suffixes
+	encryptersMu.RLock()
+	for _, e := range encrypters {
+		for _, suffix := range plain {
+			suffixes = append(suffixes, suffix+e.Suffix())
+		}
+	}
+	encryptersMu.RUnlock()
+
+	for _, suffix := range suffixes {
+		if _, err := os.Lstat(name + suffix); err == nil {
+			return true
+		}
+	}
+	return false
+}
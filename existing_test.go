@@ -0,0 +1,118 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// rotateOverExisting rotates the log file after another backup appeared under
+// the name the rotation picks, and returns the RotationInfo.
+func rotateOverExisting(t *testing.T, l *Logger, dir string) (RotationInfo, error) {
+	filename := logFile(dir)
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(ioutil.WriteFile(backupFile(dir), []byte("other"), 0644), t)
+	err := l.Rotate()
+	return l.LastRotation(), err
+}
+
+func TestExistingOverwrite(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestExistingOverwrite", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{Filename: logFile(dir), MaxSize: 100}
+	defer l.Close()
+
+	rotation, err := rotateOverExisting(t, l, dir)
+	isNil(err, t)
+	existsWithContent(backupFile(dir), []byte("foo!"), t)
+	equals(backupFile(dir), rotation.NewPath, t)
+	equals(backupFile(dir), rotation.Existing, t)
+}
+
+func TestExistingFail(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestExistingFail", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{Filename: logFile(dir), MaxSize: 100, ExistingBackups: ExistingFail}
+	defer l.Close()
+
+	_, err := rotateOverExisting(t, l, dir)
+	equals(ErrBackupExists, err, t)
+	existsWithContent(backupFile(dir), []byte("other"), t)
+	existsWithContent(logFile(dir), []byte("foo!"), t)
+}
+
+func TestBackupExists(t *testing.T) {
+	dir := makeTempDir("TestBackupExists", t)
+	defer os.RemoveAll(dir)
+
+	// a compressed or encrypted backup takes the name too.
+	RegisterEncrypter("test-aes", testKeyring())
+	name := filepath.Join(dir, "foobar-2016-11-04T18-30-00.000.log")
+	for _, suffix := range []string{"", compressSuffix, encryptSuffix, compressSuffix + encryptSuffix} {
+		isNil(ioutil.WriteFile(name+suffix, []byte("boo!"), 0644), t)
+		equals(true, backupExists(name), t)
+		isNil(os.Remove(name+suffix), t)
+	}
+	equals(false, backupExists(name), t)
+}
+
+func TestExistingRename(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestExistingRename", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{Filename: logFile(dir), MaxSize: 100, ExistingBackups: ExistingRename}
+	defer l.Close()
+
+	rotation, err := rotateOverExisting(t, l, dir)
+	isNil(err, t)
+	existsWithContent(backupFile(dir), []byte("other"), t)
+	existsWithContent(backupFile(dir, withSequence(1)), []byte("foo!"), t)
+	equals(backupFile(dir, withSequence(1)), rotation.NewPath, t)
+	equals(backupFile(dir), rotation.Existing, t)
+
+	// the next backup with the same time carries on from there.
+	writeToCurrentLog(t, l, logFile(dir), []byte("bar!"))
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir, withSequence(2)), []byte("bar!"), t)
+}
+
+func TestRotateSameInstant(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateSameInstant", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{Filename: filename, MaxSize: 100}
+	defer l.Close()
+
+	// rotations with the same timestamp get sequence numbers rather than
+	// overwriting each other, even without ExistingRename.
+	newFakeTime()
+	for _, s := range []string{"boo!", "foo!", "moo!"} {
+		writeToCurrentLog(t, l, filename, []byte(s))
+		isNil(l.Rotate(), t)
+	}
+	existsWithContent(backupFile(dir), []byte("boo!"), t)
+	existsWithContent(backupFile(dir, withSequence(1)), []byte("foo!"), t)
+	existsWithContent(backupFile(dir, withSequence(2)), []byte("moo!"), t)
+	equals("", l.LastRotation().Existing, t)
+}
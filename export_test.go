@@ -0,0 +1,13 @@
+package lumberjack
+
+import (
+	"time"
+)
+
+// RestoreGlobals undoes the mocking of package variables by the tests in this
+// package, for the tests and benchmarks in package lumberjack_test, which run
+// in the same binary.
+func RestoreGlobals() {
+	currentTime = time.Now
+	megabyte = 1024 * 1024
+}
@@ -0,0 +1,82 @@
+package lumberjack
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// writeFiltered passes a write through WriteFilter and MaxBytesPerSecond, and
+// hands what is left of it to write.  It returns what Write returns for p.
+func (l *Logger) writeFiltered(p []byte, write func([]byte) (int, error)) (int, error) {
+	out := p
+	if l.WriteFilter != nil {
+		var keep bool
+		if out, keep = l.WriteFilter(p); !keep {
+			l.stats.Filtered++
+			return len(p), nil
+		}
+	}
+	if !l.rateLimit(len(out)) {
+		if _, err := l.rateLimited(len(out)); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	n, err := write(out)
+	if err == nil {
+		return len(p), nil
+	}
+	if l.WriteFilter != nil {
+		// what was written of the filtered write can't be mapped to p.
+		return 0, err
+	}
+	return n, err
+}
+
+// filterTail returns what WriteFilter holds back for the log file, which is
+// about to be closed.
+func (l *Logger) filterTail() []byte {
+	if l.WriteFilter == nil {
+		return nil
+	}
+	if tail, keep := l.WriteFilter(nil); keep {
+		return tail
+	}
+	return nil
+}
+
+// SuppressRepeats returns a WriteFilter that drops writes identical to the
+// write before them, and writes "last message repeated N times" in their
+// place once a different write comes along, or the log file is rotated or
+// closed.  Each Logger needs a filter of its own.
+func SuppressRepeats() func(p []byte) ([]byte, bool) {
+	var (
+		last    []byte
+		repeats int
+	)
+	summary := func() []byte {
+		if repeats == 0 {
+			return nil
+		}
+		s := fmt.Sprintf("last message repeated %d times\n", repeats)
+		repeats = 0
+		return []byte(s)
+	}
+	return func(p []byte) ([]byte, bool) {
+		if p == nil {
+			// the next log file starts with the message in full.
+			last = nil
+			s := summary()
+			return s, s != nil
+		}
+		if last != nil && bytes.Equal(p, last) {
+			repeats++
+			return nil, false
+		}
+		last = append(last[:0], p...)
+		if s := summary(); s != nil {
+			return append(s, p...), true
+		}
+		return p, true
+	}
+}
@@ -0,0 +1,68 @@
+package lumberjack
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestWriteFilter(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestWriteFilter", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		WriteFilter: func(p []byte) ([]byte, bool) {
+			if bytes.HasPrefix(p, []byte("debug")) {
+				return nil, false
+			}
+			return bytes.Replace(p, []byte("secret"), []byte("******"), -1), true
+		},
+	}
+	defer l.Close()
+
+	for _, s := range []string{"debug: boo!\n", "password=secret\n"} {
+		n, err := l.Write([]byte(s))
+		isNil(err, t)
+		equals(len(s), n, t)
+	}
+	existsWithContent(filename, []byte("password=******\n"), t)
+	equals(int64(1), l.Stats().Filtered, t)
+}
+
+func TestSuppressRepeats(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSuppressRepeats", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		WriteFilter: SuppressRepeats(),
+	}
+	defer l.Close()
+
+	for _, s := range []string{"boo!\n", "boo!\n", "boo!\n", "foo!\n", "foo!\n"} {
+		n, err := l.Write([]byte(s))
+		isNil(err, t)
+		equals(len(s), n, t)
+	}
+	existsWithContent(filename, []byte("boo!\nlast message repeated 2 times\nfoo!\n"), t)
+
+	// the summary of the repeats goes to the log file they were written to,
+	// and the next log file starts with the message in full.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("boo!\nlast message repeated 2 times\nfoo!\nlast message repeated 1 times\n"), t)
+	_, err := l.Write([]byte("foo!\n"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("foo!\n"), t)
+}
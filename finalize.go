@@ -0,0 +1,108 @@
+package lumberjack
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// signatureSuffix is appended to the name of a backup to form the name of its
+// detached signature.
+const signatureSuffix = ".sig"
+
+// queueFinalize records a newly created backup, so the mill can finalize it.
+func (l *Logger) queueFinalize(name string) {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	l.rotated = append(l.rotated, name)
+}
+
+// finalizeRotated finalizes the backups created since the last time it was
+// called, and those whose finalization failed before.  If compression is
+// enabled, backups are finalized once they're compressed instead.
+func (l *Logger) finalizeRotated() error {
+	l.rotatedMu.Lock()
+	rotated := l.unfinalized
+	if !l.Compress {
+		rotated = append(rotated, l.rotated...)
+	}
+	l.rotated, l.unfinalized = nil, nil
+	l.rotatedMu.Unlock()
+
+	var err error
+	for _, name := range rotated {
+		if _, errStat := os.Stat(name); os.IsNotExist(errStat) {
+			// already cleaned up.
+			continue
+		}
+		if errFinal := l.finalize(name); err == nil && errFinal != nil {
+			err = errFinal
+		}
+	}
+	return err
+}
+
+// finalize performs the processing of a backup that has reached its final
+// form, and won't be changed by the Logger any more.  If it fails, the backup
+// is finalized again the next time the mill runs, so a backup isn't left
+// unencrypted, unsigned or unarchived because of a passing error.
+func (l *Logger) finalize(name string) (err error) {
+	defer func() {
+		if err != nil {
+			l.rotatedMu.Lock()
+			l.unfinalized = append(l.unfinalized, name)
+			l.rotatedMu.Unlock()
+		}
+	}()
+	// a backup finalized again may be encrypted already.
+	if l.Encryption != "" && !isEncrypted(name) {
+		encrypted, err := l.encrypt(name)
+		if err != nil {
+			return err
+		}
+		name = encrypted
+	}
+	if l.Signer != nil {
+		if err := l.sign(name); err != nil {
+			return err
+		}
+	}
+	if l.checksum() != "" {
+		if err := l.writeChecksum(name); err != nil {
+			return err
+		}
+	}
+	if l.Archiver != nil {
+		if err := l.archive(name); err != nil {
+			return err
+		}
+	}
+	if l.AuditMode {
+		sealed, err := l.seal(name)
+		if err != nil {
+			return err
+		}
+		name = sealed
+	}
+	if l.OnFinalize != nil {
+		l.OnFinalize(name)
+	}
+	return nil
+}
+
+// sign writes a detached signature of the backup using the Signer.
+func (l *Logger) sign(name string) error {
+	f, err := os.Open(name)
+	if err != nil {
+		return fmt.Errorf("can't open backup to sign: %s", err)
+	}
+	defer f.Close()
+	sig, err := l.Signer(f)
+	if err != nil {
+		return fmt.Errorf("can't sign backup: %s", err)
+	}
+	if err := ioutil.WriteFile(name+signatureSuffix, sig, 0644); err != nil {
+		return fmt.Errorf("can't write backup signature: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package lumberjack
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeSigner "signs" by hashing the content.
+func fakeSigner(r io.Reader) ([]byte, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, err
+	}
+	return h.Sum(nil), nil
+}
+
+func fakeSignature(content []byte) []byte {
+	sum := sha256.Sum256(content)
+	return sum[:]
+}
+
+func TestSigner(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSigner", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		Signer:     fakeSigner,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+
+	// we need to wait a little bit since the files get signed on a different
+	// goroutine.
+	<-time.After(10 * time.Millisecond)
+	existsWithContent(first+signatureSuffix, fakeSignature(b), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-time.After(10 * time.Millisecond)
+
+	// the signature goes along with the backup.
+	notExist(first+signatureSuffix, t)
+	existsWithContent(backupFile(dir)+signatureSuffix, fakeSignature([]byte{}), t)
+	fileCount(dir, 3, t)
+}
+
+func TestSignerCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSignerCompressed", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		Compress: true,
+		Signer:   fakeSigner,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	// we need to wait a little bit since the files get compressed on a
+	// different goroutine.
+	<-time.After(300 * time.Millisecond)
+
+	// only the compressed backup is signed.
+	backup := backupFile(dir)
+	notExist(backup+signatureSuffix, t)
+	compressed, err := os.Open(backup + compressSuffix)
+	isNil(err, t)
+	defer compressed.Close()
+	sig, err := fakeSigner(compressed)
+	isNil(err, t)
+	existsWithContent(backup+compressSuffix+signatureSuffix, sig, t)
+	fileCount(dir, 3, t)
+}
+
+func TestFinalizeRetry(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestFinalizeRetry", t)
+	defer os.RemoveAll(dir)
+
+	// the first signature fails, like a signing service that is down.
+	fails := 1
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		Signer: func(r io.Reader) ([]byte, error) {
+			if fails > 0 {
+				fails--
+				return nil, errors.New("signing service unavailable")
+			}
+			return fakeSigner(r)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+	notExist(backupFile(dir)+signatureSuffix, t)
+
+	// the backup is signed the next time the mill runs.
+	isNil(l.Mill(context.Background()), t)
+	existsWithContent(backupFile(dir)+signatureSuffix, fakeSignature(b), t)
+}
@@ -0,0 +1,11 @@
+// +build !windows
+
+package lumberjack
+
+func fixPath(path string) string {
+	return path
+}
+
+func checkPath(_ string) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package lumberjack
+
+import (
+	"path/filepath"
+)
+
+// fixPath makes path absolute, and adds the \\?\ prefix if it is too long for
+// the Windows API otherwise, so that deep directory trees work.
+func fixPath(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	return longPath(abs)
+}
+
+// checkPath returns an error if path uses the name of a device, such as CON
+// or NUL, which Windows would open instead of a file.
+func checkPath(path string) error {
+	if name := reservedName(path); name != "" {
+		return reservedNameError(path, name)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package lumberjack
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"time"
+)
+
+// ErrFilesystemTimeout is returned when reading a backup directory or removing
+// a backup takes longer than the Logger's FilesystemTimeout, and for such
+// calls made until the one that timed out completes.
+var ErrFilesystemTimeout = errors.New("lumberjack: filesystem call timed out")
+
+// fsCall runs f, giving up after FilesystemTimeout.  A call that times out
+// keeps running in the background, and later calls fail straight away until
+// it completes, so that a hung filesystem doesn't pile up goroutines.
+func (l *Logger) fsCall(f func() error) error {
+	if l.FilesystemTimeout <= 0 {
+		return f()
+	}
+
+	l.fsMu.Lock()
+	if l.fsStalled != nil {
+		select {
+		case <-l.fsStalled:
+			l.fsStalled = nil
+		default:
+			l.fsMu.Unlock()
+			return ErrFilesystemTimeout
+		}
+	}
+	l.fsMu.Unlock()
+
+	done := make(chan struct{})
+	var err error
+	go func() {
+		err = f()
+		close(done)
+	}()
+
+	timer := time.NewTimer(l.FilesystemTimeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return err
+	case <-timer.C:
+		l.fsMu.Lock()
+		l.fsStalled = done
+		l.fsMu.Unlock()
+		return ErrFilesystemTimeout
+	}
+}
+
+// readDir reads the named directory like ioutil.ReadDir, within
+// FilesystemTimeout.
+func (l *Logger) readDir(dir string) ([]os.FileInfo, error) {
+	var files []os.FileInfo
+	err := l.fsCall(func() error {
+		var err error
+		files, err = ioutil.ReadDir(dir)
+		return err
+	})
+	if err == ErrFilesystemTimeout {
+		// files may still be set by the stalled call.
+		return nil, err
+	}
+	return files, err
+}
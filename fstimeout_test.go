@@ -0,0 +1,56 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFilesystemTimeout(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestFilesystemTimeout", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           10,
+		MaxBackups:        1,
+		FilesystemTimeout: 10 * time.Millisecond,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	first := backupFile(dir)
+
+	// removing the first backup hangs, like on an unresponsive NFS server.
+	hung := make(chan struct{})
+	os_Remove = func(name string) error {
+		<-hung
+		return os.Remove(name)
+	}
+	defer func() { os_Remove = os.Remove }()
+
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	err := l.Mill(context.Background())
+	assert(err != nil && strings.Contains(err.Error(), ErrFilesystemTimeout.Error()), t,
+		"expected ErrFilesystemTimeout, got %v", err)
+
+	// further removals fail straight away while the removal hangs.
+	equals(ErrFilesystemTimeout, l.remove(first), t)
+
+	close(hung)
+	<-time.After(10 * time.Millisecond)
+	notExist(first, t)
+	isNil(l.Mill(context.Background()), t)
+	fileCount(dir, 2, t)
+}
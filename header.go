@@ -0,0 +1,30 @@
+package lumberjack
+
+import "fmt"
+
+// writeHeader writes the FileHeader to the log file, which has just been
+// created.
+func (l *Logger) writeHeader() error {
+	if l.FileHeader == nil {
+		return nil
+	}
+	header := l.FileHeader()
+	if len(header) == 0 {
+		return nil
+	}
+	n, err := l.writeFile(header)
+	l.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("can't write header to new logfile: %s", err)
+	}
+	return nil
+}
+
+// footer returns the FileFooter for the log file, which is about to be
+// rotated.
+func (l *Logger) footer() []byte {
+	if l.FileFooter == nil || l.file == nil || l.special {
+		return nil
+	}
+	return l.FileFooter()
+}
@@ -0,0 +1,40 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+)
+
+func TestFileHeaderFooter(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestFileHeaderFooter", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		FileHeader: func() []byte { return []byte("time,msg\n") },
+		FileFooter: func() []byte { return []byte("# end\n") },
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("1,boo!\n"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("time,msg\n1,boo!\n"), t)
+
+	// closing doesn't end the log file, which is appended to when it is
+	// opened again.
+	isNil(l.Close(), t)
+	_, err = l.Write([]byte("2,foo!\n"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("time,msg\n1,boo!\n2,foo!\n"), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("time,msg\n1,boo!\n2,foo!\n# end\n"), t)
+	existsWithContent(filename, []byte("time,msg\n"), t)
+	equals(int64(len("time,msg\n")), l.size, t)
+}
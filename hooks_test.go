@@ -0,0 +1,117 @@
+package lumberjack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+// hookRecorder records the names passed to the lifecycle hooks.
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *hookRecorder) hook(event string) func(string) {
+	return func(name string) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.events = append(r.events, event+" "+name)
+	}
+}
+
+func (r *hookRecorder) get() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+func TestLifecycleHooks(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestLifecycleHooks", t)
+	defer os.RemoveAll(dir)
+
+	r := &hookRecorder{}
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 1,
+		Compress:   true,
+		OnCompress: r.hook("compress"),
+		OnFinalize: r.hook("finalize"),
+		OnRemove:   r.hook("remove"),
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir) + compressSuffix
+	<-time.After(300 * time.Millisecond)
+	equals([]string{"compress " + first, "finalize " + first}, r.get(), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	second := backupFile(dir) + compressSuffix
+	<-time.After(300 * time.Millisecond)
+	equals([]string{
+		"compress " + first,
+		"finalize " + first,
+		"remove " + first,
+		"compress " + second,
+		"finalize " + second,
+	}, r.get(), t)
+}
+
+func TestFinalizeHookUncompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestFinalizeHookUncompressed", t)
+	defer os.RemoveAll(dir)
+
+	r := &hookRecorder{}
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		OnFinalize: r.hook("finalize"),
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-time.After(10 * time.Millisecond)
+	equals([]string{"finalize " + backupFile(dir)}, r.get(), t)
+}
+
+func TestOnError(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOnError", t)
+	defer os.RemoveAll(dir)
+
+	r := &hookRecorder{}
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		Archiver: &fakeArchiver{err: errors.New("unreachable")},
+		OnError:  func(err error) { r.hook("error")(err.Error()) },
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+	equals([]string{"error can't archive backup: unreachable"}, r.get(), t)
+}
@@ -0,0 +1,42 @@
+package lumberjack
+
+// resetIdle restarts the countdown to closing the log file after
+// CloseAfterIdle.
+func (l *Logger) resetIdle() {
+	if l.CloseAfterIdle <= 0 || l.file == nil {
+		return
+	}
+	l.lastActive = l.clock().Now()
+	if l.idleTimer == nil {
+		l.idleTimer = l.scheduleWork(WorkIdle, l.CloseAfterIdle, l.closeIdle)
+	}
+}
+
+// stopIdle cancels the countdown to closing the log file.
+func (l *Logger) stopIdle() {
+	if l.idleTimer != nil {
+		l.idleTimer()
+		l.idleTimer = nil
+	}
+}
+
+// closeIdle closes the log file if nothing has been written to it for
+// CloseAfterIdle, or checks again later otherwise.
+func (l *Logger) closeIdle() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.idleTimer == nil || l.file == nil {
+		return
+	}
+	if remaining := l.CloseAfterIdle - l.clock().Now().Sub(l.lastActive); remaining > 0 {
+		l.idleTimer = l.scheduleWork(WorkIdle, remaining, l.closeIdle)
+		return
+	}
+	if l.stalled != nil {
+		// closing a file with a stalled write would most likely stall too.
+		l.idleTimer = l.scheduleWork(WorkIdle, l.CloseAfterIdle, l.closeIdle)
+		return
+	}
+	// what am I going to do, log this?
+	_ = l.close()
+}
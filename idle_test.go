@@ -0,0 +1,83 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func TestCloseAfterIdle(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestCloseAfterIdle", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		CloseAfterIdle: 10 * time.Millisecond,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	<-time.After(50 * time.Millisecond)
+
+	l.mu.Lock()
+	closed := l.file == nil
+	l.mu.Unlock()
+	assert(closed, t, "expected the idle log file to be closed")
+
+	// the file gets reopened and appended to.
+	b2 := []byte("foo!")
+	n, err = l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+	existsWithContent(filename, append(b, b2...), t)
+	fileCount(dir, 1, t)
+}
+
+func TestCloseAfterIdleFakeClock(t *testing.T) {
+	megabyte = 1
+
+	dir := makeTempDir("TestCloseAfterIdleFakeClock", t)
+	defer os.RemoveAll(dir)
+
+	clock := lumberjacktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		CloseAfterIdle: time.Minute,
+		Clock:          clock,
+	}
+	defer l.Close()
+
+	isOpen := func() bool {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		return l.file != nil
+	}
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+
+	// a write puts off closing the file.
+	clock.Advance(30 * time.Second)
+	_, err = l.Write(b)
+	isNil(err, t)
+	clock.Advance(30 * time.Second)
+	assert(isOpen(), t, "expected the log file to still be open")
+
+	clock.Advance(30 * time.Second)
+	assert(!isOpen(), t, "expected the idle log file to be closed")
+	existsWithContent(filename, []byte("boo!boo!"), t)
+}
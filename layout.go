@@ -0,0 +1,52 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// layoutName moves the given backup name into the subdirectory for its
+// timestamp given by BackupDirLayout, if it is set.
+func (l *Logger) layoutName(name string) string {
+	if l.layoutDepth() == 0 {
+		return name
+	}
+	base := filepath.Base(name)
+	t, _, err := l.codec().Decode(base)
+	if err != nil {
+		t = l.now().In(l.location())
+	}
+	return filepath.Join(filepath.Dir(name), t.Format(l.BackupDirLayout), base)
+}
+
+// layoutDepth returns the number of levels of subdirectories BackupDirLayout
+// puts backups in, which is 0 if it isn't set.
+func (l *Logger) layoutDepth() int {
+	if l.BackupDirLayout == "" || l.SequentialBackups {
+		return 0
+	}
+	return strings.Count(strings.Trim(filepath.ToSlash(l.BackupDirLayout), "/"), "/") + 1
+}
+
+// removeLayoutDirs removes the subdirectories of BackupDirLayout that held
+// the given backup, from the innermost outwards, as long as they are empty.
+func (l *Logger) removeLayoutDirs(name string) {
+	if l.layoutDepth() == 0 {
+		return
+	}
+	bases := make(map[string]bool)
+	for _, p := range l.partitions() {
+		for _, dir := range p.dirs {
+			bases[filepath.Clean(dir)] = true
+		}
+	}
+	dir := filepath.Dir(name)
+	for i := 0; i < l.layoutDepth() && !bases[dir]; i++ {
+		if err := os.Remove(dir); err != nil {
+			// not empty, or already gone.
+			return
+		}
+		dir = filepath.Dir(dir)
+	}
+}
@@ -0,0 +1,52 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBackupDirLayout(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBackupDirLayout", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:        filename,
+		MaxSize:         100,
+		MaxBackups:      1,
+		BackupDirLayout: "2006/01/02",
+	}
+	defer l.Close()
+
+	dayDir := func() string {
+		return filepath.Join(dir, fakeTime().UTC().Format("2006/01/02"))
+	}
+
+	var days []string
+	for _, s := range []string{"boo!", "foo!", "moo!"} {
+		_, err := l.Write([]byte(s))
+		isNil(err, t)
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		isNil(l.Mill(context.Background()), t)
+
+		// each backup goes to the directory of its day.
+		existsWithContent(filepath.Join(dayDir(), filepath.Base(backupFile(dir))), []byte(s), t)
+		days = append(days, dayDir())
+	}
+
+	// the cleanup finds the backups in the subdirectories, and removes the
+	// directories it empties.
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(1, len(backups), t)
+	for _, day := range days[:2] {
+		notExist(day, t)
+	}
+	fileCount(days[2], 1, t)
+}
@@ -0,0 +1,74 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func TestRotateOnLineBoundarySize(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateOnLineBoundarySize", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              10,
+		RotateOnLineBoundary: true,
+	}
+	defer l.Close()
+
+	for _, s := range []string{"abc\n", "lvl=", "info"} {
+		_, err := l.Write([]byte(s))
+		isNil(err, t)
+	}
+	// the unfinished line takes the log file over MaxSize.
+	existsWithContent(filename, []byte("abc\nlvl=info"), t)
+	fileCount(dir, 1, t)
+
+	// the rest of the line goes to the same file, the next line to a new one.
+	newFakeTime()
+	n, err := l.Write([]byte("!\nfoo\n"))
+	isNil(err, t)
+	equals(6, n, t)
+	existsWithContent(backupFile(dir), []byte("abc\nlvl=info!\n"), t)
+	existsWithContent(filename, []byte("foo\n"), t)
+	fileCount(dir, 2, t)
+}
+
+func TestRotateOnLineBoundaryInterval(t *testing.T) {
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateOnLineBoundaryInterval", t)
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := lumberjacktest.NewFakeClock(start)
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		MaxInterval:          time.Hour,
+		RotateOnLineBoundary: true,
+		Clock:                clock,
+	}
+	defer l.Close()
+
+	b := []byte("boo ")
+	writeToCurrentLog(t, l, filename, b)
+
+	// the timer doesn't rotate the log file in the middle of a line.
+	clock.Advance(time.Hour)
+	fileCount(dir, 1, t)
+
+	_, err := l.Write([]byte("foo\nbar\n"))
+	isNil(err, t)
+	existsWithContent(backupFileWithTime(dir, start.Add(time.Hour)), []byte("boo foo\n"), t)
+	existsWithContent(filename, []byte("bar\n"), t)
+	equals(RotationInterval, l.LastRotation().Reason, t)
+}
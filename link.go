@@ -0,0 +1,22 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// linkBackup hard links the backup into LinkDir, if set.
+func (l *Logger) linkBackup(name string) error {
+	if l.LinkDir == "" {
+		return nil
+	}
+	if err := l.mkdirAll(l.LinkDir); err != nil {
+		return fmt.Errorf("can't make link directory: %s", err)
+	}
+	link := filepath.Join(l.LinkDir, filepath.Base(name))
+	if err := os.Link(name, link); err != nil {
+		return fmt.Errorf("can't link backup: %s", err)
+	}
+	return nil
+}
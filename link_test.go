@@ -0,0 +1,43 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLinkDir(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestLinkDir", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	linkDir := filepath.Join(dir, "pickup")
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+		LinkDir:  linkDir,
+		Compress: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	// the link keeps the content as rotated, even once the backup is
+	// compressed.
+	<-time.After(300 * time.Millisecond)
+	backup := backupFile(dir)
+	existsWithContent(backupFile(linkDir), b, t)
+	notExist(backup, t)
+	verifyCompressedFile(backup, b, t)
+
+	// removing the link doesn't affect the backup.
+	isNil(os.Remove(backupFile(linkDir)), t)
+	exists(backup+compressSuffix, t)
+}
@@ -3,7 +3,15 @@
 package lumberjack
 
 import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
 	"syscall"
 	"testing"
 	"time"
@@ -203,3 +211,386 @@ func (fs *fakeFS) Stat(name string) (os.FileInfo, error) {
 	stat.Gid = 666
 	return info, nil
 }
+
+func TestRotateSymlinkTarget(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateSymlinkTarget", t)
+	defer os.RemoveAll(dir)
+	dataDir := makeTempDir("TestRotateSymlinkTargetData", t)
+	defer os.RemoveAll(dataDir)
+
+	target := logFile(dataDir)
+	link := logFile(dir)
+	err := os.Symlink(target, link)
+	isNil(err, t)
+
+	l := &Logger{
+		Filename: link,
+		MaxSize:  10,
+	}
+	defer l.Close()
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	existsWithContent(target, b, t)
+
+	newFakeTime()
+
+	b2 := []byte("foooooo!")
+	n, err = l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+
+	// the link should still point at the target, which has the new content,
+	// and the backup should be next to the target.
+	info, err := os.Lstat(link)
+	isNil(err, t)
+	assert(info.Mode()&os.ModeSymlink != 0, t, "expected %s to still be a symlink", link)
+	existsWithContent(link, b2, t)
+	existsWithContent(target, b2, t)
+	existsWithContent(backupFile(dataDir), b, t)
+	fileCount(dir, 1, t)
+	fileCount(dataDir, 2, t)
+}
+
+func TestSymlinkCurrent(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSymlinkCurrent", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		MaxBackups:     1,
+		SymlinkCurrent: true,
+	}
+	defer l.Close()
+
+	// the log file is named like a backup from the start.
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	first := backupFile(dir)
+	existsWithContent(first, b, t)
+	existsWithContent(filename, b, t)
+	before, err := os.Stat(first)
+	isNil(err, t)
+
+	// rotating leaves the log file where it is, and points the link at a new
+	// one.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	after, err := os.Stat(first)
+	isNil(err, t)
+	assert(os.SameFile(before, after), t, "expected %s not to be moved", first)
+	second := backupFile(dir)
+	existsWithContent(second, []byte{}, t)
+	info, err := os.Lstat(filename)
+	isNil(err, t)
+	assert(info.Mode()&os.ModeSymlink != 0, t, "expected %s to be a symlink", filename)
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(second, []byte("foo!"), t)
+
+	// the log file isn't taken for a backup.
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(1, len(backups), t)
+	equals(first, backups[0].Path, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	notExist(first, t)
+	existsWithContent(second, []byte("foo!"), t)
+	exists(backupFile(dir), t)
+	fileCount(dir, 3, t)
+}
+
+func TestCompressedBackupMode(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer syscall.Umask(syscall.Umask(077))
+
+	for i, backupMode := range []os.FileMode{0, 0640} {
+		dir := makeTempDir("TestCompressedBackupMode"+strconv.Itoa(i), t)
+		defer os.RemoveAll(dir)
+
+		filename := logFile(dir)
+		l := &Logger{
+			Filename:       filename,
+			MaxSize:        100,
+			Compress:       true,
+			FileMode:       0644,
+			BackupFileMode: backupMode,
+		}
+		_, err := l.Write([]byte("boo!"))
+		isNil(err, t)
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		isNil(l.Mill(context.Background()), t)
+		isNil(l.Close(), t)
+
+		// the compressed backup has the mode of the backup, whatever the
+		// umask, unless BackupFileMode says otherwise.
+		want := os.FileMode(0644)
+		if backupMode != 0 {
+			want = backupMode
+		}
+		info, err := os.Stat(backupFile(dir) + compressSuffix)
+		isNil(err, t)
+		equals(want, info.Mode(), t)
+		info, err = os.Stat(filename)
+		isNil(err, t)
+		equals(os.FileMode(0644), info.Mode(), t)
+	}
+}
+
+func TestSharedAppendConcurrent(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSharedAppendConcurrent", t)
+	defer os.RemoveAll(dir)
+
+	// each Logger stands in for a process, writing lines of 10 bytes.
+	const writers, lines = 4, 200
+	filename := logFile(dir)
+	var wg sync.WaitGroup
+	for w := 0; w < writers; w++ {
+		l := &Logger{Filename: filename, MaxSize: 100, SharedAppend: true}
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			defer l.Close()
+			for i := 0; i < lines; i++ {
+				_, err := fmt.Fprintf(l, "%d %07d\n", w, i)
+				isNil(err, t)
+			}
+		}(w)
+	}
+	wg.Wait()
+
+	// no line is lost or overwritten.
+	files, err := ioutil.ReadDir(dir)
+	isNil(err, t)
+	var total int
+	for _, f := range files {
+		if strings.HasSuffix(f.Name(), lockSuffix) {
+			continue
+		}
+		b, err := ioutil.ReadFile(filepath.Join(dir, f.Name()))
+		isNil(err, t)
+		total += bytes.Count(b, []byte("\n"))
+	}
+	equals(writers*lines, total, t)
+}
+
+func TestFileModeUmask(t *testing.T) {
+	tests := []struct {
+		name     string
+		useUmask bool
+		expected os.FileMode
+	}{
+		{name: "force mode", useUmask: false, expected: 0644},
+		{name: "use umask", useUmask: true, expected: 0600},
+	}
+
+	oldMask := syscall.Umask(077)
+	defer syscall.Umask(oldMask)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			currentTime = fakeTime
+			dir := makeTempDir("TestFileModeUmask", t)
+			defer os.RemoveAll(dir)
+
+			filename := logFile(dir)
+			l := &Logger{
+				Filename: filename,
+				FileMode: 0644,
+				UseUmask: test.useUmask,
+			}
+			defer l.Close()
+			b := []byte("boo!")
+			n, err := l.Write(b)
+			isNil(err, t)
+			equals(len(b), n, t)
+
+			info, err := os.Stat(filename)
+			isNil(err, t)
+			equals(test.expected, info.Mode(), t)
+		})
+	}
+}
+
+func TestDefaultModeUmask(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	oldMask := syscall.Umask(077)
+	defer syscall.Umask(oldMask)
+
+	dir := makeTempDir("TestDefaultModeUmask", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	isNil(ioutil.WriteFile(filename, []byte("boo!"), 0644), t)
+	isNil(os.Chmod(filename, 0644), t)
+	l := &Logger{Filename: filename, MaxSize: 100}
+	defer l.Close()
+
+	// without FileMode, the mode copied from the rotated log file is subject
+	// to the umask.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	info, err := os.Stat(filename)
+	isNil(err, t)
+	equals(os.FileMode(0600), info.Mode(), t)
+}
+
+func TestSpecialFilePassThrough(t *testing.T) {
+	megabyte = 1
+	l := &Logger{
+		Filename:   "/dev/null",
+		MaxSize:    1,
+		MaxBackups: 1,
+	}
+	defer l.Close()
+
+	// writes larger than MaxSize are passed through, and never rotate.
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	n, err = l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	isNil(l.Rotate(), t)
+	exists("/dev/null", t)
+	equals(int64(8), l.Stats().BytesWritten, t)
+}
+
+func TestNamedPipePassThrough(t *testing.T) {
+	dir := makeTempDir("TestNamedPipePassThrough", t)
+	defer os.RemoveAll(dir)
+
+	pipe := filepath.Join(dir, "pipe")
+	isNil(syscall.Mkfifo(pipe, 0600), t)
+
+	read := make(chan []byte)
+	go func() {
+		b, _ := ioutil.ReadFile(pipe)
+		read <- b
+	}()
+
+	l := &Logger{
+		Filename: pipe,
+	}
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	// pipes can't be synced, which isn't an error for Sync.
+	isNil(l.Sync(), t)
+	isNil(l.Close(), t)
+
+	equals(b, <-read, t)
+	fileCount(dir, 1, t)
+}
+
+func TestLoadAverage(t *testing.T) {
+	load, ok := loadAverage()
+	assert(ok, t, "load average not available")
+	assert(load >= 0, t, "unexpected load average %v", load)
+}
+
+func TestWatchRenamedInotify(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestWatchRenamedInotify", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	changed := make(chan ChangeReason, 10)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		WatchExternalChanges: true,
+		OnExternalChange:     func(_ string, reason ChangeReason) { changed <- reason },
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	// move the file away, as an external logrotate would.
+	moved := filepath.Join(dir, "moved.log")
+	isNil(os.Rename(filename, moved), t)
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the renamed log file wasn't noticed")
+	}
+
+	b2 := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b2)
+	existsWithContent(moved, b, t)
+}
+
+func TestRotateOnSignal(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateOnSignal", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	rotated := make(chan RotationInfo, 10)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+		OnRotate: func(r RotationInfo) { rotated <- r },
+	}
+	defer l.Close()
+	l.RotateOnSignal(syscall.SIGUSR1)
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(syscall.Kill(os.Getpid(), syscall.SIGUSR1), t)
+
+	// the signal is handled on another goroutine.
+	select {
+	case <-rotated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the signal didn't rotate the log file")
+	}
+	isNil(l.Mill(context.Background()), t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(filename, []byte{}, t)
+
+	// closing stops handling the signal.
+	isNil(l.Close(), t)
+	equals((chan os.Signal)(nil), l.signals, t)
+}
+
+func TestDiskUsage(t *testing.T) {
+	dir := makeTempDir("TestDiskUsage", t)
+	defer os.RemoveAll(dir)
+
+	free, used, ok := diskUsage(dir)
+	assert(ok, t, "expected the disk usage of %s", dir)
+	assert(free > 0 && used > 0, t, "expected space to be free and used, got %d and %d", free, used)
+
+	_, _, ok = diskUsage(filepath.Join(dir, "missing"))
+	assert(!ok, t, "expected no disk usage of a missing directory")
+}
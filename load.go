@@ -0,0 +1,7 @@
+// +build !linux
+
+package lumberjack
+
+func loadAverage() (float64, bool) {
+	return 0, false
+}
@@ -0,0 +1,26 @@
+package lumberjack
+
+import (
+	"bytes"
+	"io/ioutil"
+	"runtime"
+	"strconv"
+)
+
+// loadAverage returns the one minute load average per CPU, as reported by
+// /proc/loadavg.
+func loadAverage() (float64, bool) {
+	b, err := ioutil.ReadFile("/proc/loadavg")
+	if err != nil {
+		return 0, false
+	}
+	fields := bytes.Fields(b)
+	if len(fields) == 0 {
+		return 0, false
+	}
+	load, err := strconv.ParseFloat(string(fields[0]), 64)
+	if err != nil {
+		return 0, false
+	}
+	return load / float64(runtime.NumCPU()), true
+}
@@ -0,0 +1,83 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// lockSuffix is appended to Filename to form the name of the lock file that
+// coordinates the processes using the same Filename with LockRotation or
+// SharedAppend.
+const lockSuffix = ".lock"
+
+// coordinated reports whether the rotations and cleanups of the log file are
+// coordinated with other processes using the same Filename.
+func (l *Logger) coordinated() bool {
+	return l.LockRotation || l.SharedAppend
+}
+
+// lockRotation takes the lock that keeps the processes using the same
+// Filename from rotating the log file at the same time, unless the Logger
+// holds it already, and returns a func releasing it.
+func (l *Logger) lockRotation() (func(), error) {
+	if !l.coordinated() || l.lockHeld {
+		return func() {}, nil
+	}
+	unlock, err := l.lockRotationFile()
+	if err != nil {
+		return nil, err
+	}
+	l.lockHeld = true
+	// backups may have been created by the other processes.
+	l.lastKnown = false
+	return func() {
+		l.lockHeld = false
+		unlock()
+	}, nil
+}
+
+// lockRotationFile takes the lock on the lock file, waiting for the other
+// processes to release it, and returns a func releasing it.
+func (l *Logger) lockRotationFile() (func(), error) {
+	name := l.filename() + lockSuffix
+	if err := l.mkdirAll(filepath.Dir(name)); err != nil {
+		return nil, fmt.Errorf("can't make directories for lock file: %s", err)
+	}
+	unlock, err := lockFile(name)
+	if err != nil {
+		return nil, fmt.Errorf("can't lock log file: %s", err)
+	}
+	return unlock, nil
+}
+
+// lockSteps reports whether the steps of the cleanup that scan, remove or
+// rename backups take the lock on the lock file each, rather than the cleanup
+// holding it throughout.  The lock is only held throughout with
+// SequentialBackups, since a rotation in another process renumbers the
+// backups.
+func (l *Logger) lockSteps() bool {
+	return l.coordinated() && !l.SequentialBackups
+}
+
+// lockCleanup takes the lock on the lock file for a step of the cleanup that
+// scans, removes or renames backups, if lockSteps, and returns a func
+// releasing it.  The slow steps, such as compressing and archiving backups,
+// run without it, so they don't hold up rotations.
+func (l *Logger) lockCleanup() (func(), error) {
+	if !l.lockSteps() {
+		return func() {}, nil
+	}
+	return l.lockRotationFile()
+}
+
+// stepName returns the name of a file that the cleanup writes and then
+// renames to the given name with the lock held, which is unique to the
+// process if lockSteps, since other processes may be writing it too.
+func (l *Logger) stepName(name string) string {
+	if l.lockSteps() {
+		name += "." + strconv.Itoa(os.Getpid())
+	}
+	return name + compactingSuffix
+}
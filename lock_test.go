@@ -0,0 +1,94 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLockRotation(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestLockRotation", t)
+	defer os.RemoveAll(dir)
+
+	// two Loggers stand in for the old and the new instance of a service.
+	filename := logFile(dir)
+	a := &Logger{Filename: filename, MaxSize: 100, LockRotation: true}
+	defer a.Close()
+	b := &Logger{Filename: filename, MaxSize: 100, LockRotation: true}
+	defer b.Close()
+
+	_, err := a.Write([]byte("boo!"))
+	isNil(err, t)
+	_, err = b.Write([]byte("foo!"))
+	isNil(err, t)
+
+	// b finds that a rotated the log file already, and opens the new one
+	// instead of rotating it again.
+	newFakeTime()
+	isNil(a.Rotate(), t)
+	isNil(b.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("boo!foo!"), t)
+	exists(filename+lockSuffix, t)
+	fileCount(dir, 3, t)
+
+	_, err = b.Write([]byte("moo!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("moo!"), t)
+}
+
+// gateArchiver waits for release before archiving, after reporting that it
+// started to.
+type gateArchiver struct {
+	started chan string
+	release chan struct{}
+}
+
+func (a *gateArchiver) Archive(ctx context.Context, path string) error {
+	a.started <- path
+	<-a.release
+	return nil
+}
+
+func TestLockRotationMill(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestLockRotationMill", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	archiver := &gateArchiver{started: make(chan string, 2), release: make(chan struct{})}
+	l := &Logger{Filename: filename, MaxSize: 100, LockRotation: true, Archiver: archiver}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-archiver.started
+
+	// the mill doesn't hold the lock while archiving, so rotating goes ahead.
+	rotated := make(chan error, 1)
+	go func() {
+		_, err := l.Write([]byte("foo!"))
+		if err == nil {
+			newFakeTime()
+			err = l.Rotate()
+		}
+		rotated <- err
+	}()
+	select {
+	case err := <-rotated:
+		isNil(err, t)
+	case <-time.After(5 * time.Second):
+		close(archiver.release)
+		t.Fatal("rotating waited for the archiving")
+	}
+
+	close(archiver.release)
+	isNil(l.Mill(context.Background()), t)
+	fileCount(dir, 4, t)
+}
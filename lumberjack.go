@@ -21,11 +21,10 @@
 package lumberjack
 
 import (
-	"compress/gzip"
-	"errors"
+	"bytes"
 	"fmt"
+	"hash/fnv"
 	"io"
-	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
@@ -73,21 +72,50 @@ var _ io.WriteCloser = (*Logger)(nil)
 // Whenever a new logfile gets created, old log files may be deleted.  The most
 // recent files according to the encoded timestamp will be retained, up to a
 // number equal to MaxBackups (or all of them if MaxBackups is 0).  Any files
-// with an encoded timestamp older than MaxAge days are deleted, regardless of
-// MaxBackups.  Note that the time encoded in the timestamp is the rotation
-// time, which may differ from the last time that file was written to.
+// with an encoded timestamp older than MaxAge days, or MaxAgeDuration, are
+// deleted, regardless of MaxBackups.  Note that the time encoded in the
+// timestamp is the rotation time, which may differ from the last time that
+// file was written to.
 //
-// If MaxBackups and MaxAge are both 0, no old log files will be deleted.
+// If MaxBackups, MaxAge, MaxAgeDuration and MaxTotalSize are all 0, no old log
+// files will be deleted.
 type Logger struct {
 	// Filename is the file to write logs to.  Backup log files will be retained
 	// in the same directory, or where defined by `BackupDir`.
 	// It uses <processname>-lumberjack.log in os.TempDir() if empty.
+	// If Filename is a device or a pipe, such as /dev/stdout, writes are simply
+	// passed through to it, and there is no rotation or cleanup.
+	// If Filename is a symlink, the file it points to is written and rotated,
+	// and the link itself is left in place.  Backups are then retained in the
+	// directory of the link's target.
 	Filename string `json:"filename" yaml:"filename"`
 
 	// MaxSize is the maximum size in megabytes of the log file before it gets
 	// rotated. It defaults to 100 megabytes.
 	MaxSize int `json:"maxsize" yaml:"maxsize"`
 
+	// MaxInterval is the maximum time a log file is written to before it gets
+	// rotated, measured from when the Logger created the log file, or first
+	// opened an existing one.  The log file is rotated when the time is up
+	// even if nothing is being written.  The default (0) is to rotate based on
+	// size only.
+	MaxInterval time.Duration `json:"maxinterval" yaml:"maxinterval"`
+
+	// RotateAt is the time of day at which the log file is rotated, as
+	// "15:04", e.g. "00:00" for daily files.  The time is local time if
+	// LocalTime is set, and UTC otherwise.  The log file is rotated then even
+	// if nothing is being written.  Invalid times are ignored, so check them
+	// with Config.Validate.  The default is not to rotate at a fixed time.
+	RotateAt string `json:"rotateat" yaml:"rotateat"`
+
+	// RotateOnLineBoundary determines if rotation waits for the end of the
+	// current line, so that a log record written with several calls to Write
+	// doesn't end up split across two files.  Once the log file is due to be
+	// rotated, the rest of the line still goes to it, which can take it over
+	// MaxSize, and it is rotated before what follows the newline is written.
+	// Rotate always rotates right away.
+	RotateOnLineBoundary bool `json:"rotateonlineboundary" yaml:"rotateonlineboundary"`
+
 	// MaxAge is the maximum number of days to retain old log files based on the
 	// timestamp encoded in their filename.  Note that a day is defined as 24
 	// hours and may not exactly correspond to calendar days due to daylight
@@ -95,11 +123,89 @@ type Logger struct {
 	// based on age.
 	MaxAge int `json:"maxage" yaml:"maxage"`
 
+	// MaxAgeDuration is the maximum time to retain old log files, like MaxAge
+	// but with finer granularity, e.g. 6 * time.Hour for short-lived
+	// environments.  If both are set, backups are removed once they are
+	// older than either.  The default (0) is not to remove old log files
+	// based on age.
+	MaxAgeDuration time.Duration `json:"maxageduration" yaml:"maxageduration"`
+
+	// UseModTimeFallback makes the cleanup of old log files go by the
+	// modification time of files named like backups, i.e. with the log
+	// file's name and a dash before its extension, whose timestamp can't be
+	// parsed with the TimeFormat, e.g. because the TimeFormat was changed.
+	// Without it such files aren't taken for backups, and accumulate
+	// whatever MaxAge and MaxBackups.  Beware that it also takes in other
+	// log files named like backups, such as foo-access.log next to foo.log.
+	// Metadata, signature and checksum files of backups are never taken in.
+	UseModTimeFallback bool `json:"usemodtimefallback" yaml:"usemodtimefallback"`
+
 	// MaxBackups is the maximum number of old log files to retain.  The default
 	// is to retain all old log files (though MaxAge may still cause them to get
 	// deleted.)
 	MaxBackups int `json:"maxbackups" yaml:"maxbackups"`
 
+	// KeepDaily and KeepWeekly enable grandfather-father-son retention: the
+	// newest backup of each of the last KeepDaily calendar days, and of each
+	// of the last KeepWeekly calendar weeks, starting on Monday, is kept, as
+	// is every backup rotated within KeepAllFor.  Other backups are removed.
+	// Days and weeks are in local time if LocalTime is set, and UTC
+	// otherwise; the current day and week count as the first.  MaxBackups and
+	// MaxAge still apply on top.  The default (0) is not to remove backups
+	// based on the calendar, and KeepAllFor has no effect without either.
+	KeepDaily  int           `json:"keepdaily" yaml:"keepdaily"`
+	KeepWeekly int           `json:"keepweekly" yaml:"keepweekly"`
+	KeepAllFor time.Duration `json:"keepallfor" yaml:"keepallfor"`
+
+	// MaxTotalSize is the maximum size in megabytes of the log file and all
+	// backups together, compressed or not.  The oldest backups are removed
+	// until they fit.  The default (0) is not to limit the total size.
+	MaxTotalSize int `json:"maxtotalsize" yaml:"maxtotalsize"`
+
+	// MinFreeSpace is the space in megabytes to leave free on the filesystem
+	// holding the log file, and MinFreePercent the same as a percentage of
+	// the size of the filesystem.  When less is free, the oldest backups on
+	// that filesystem are removed straight away, before the write or rotation
+	// that found out, until enough is free or no backups are left.  The free
+	// space is checked at most once a second, on platforms that can report it.
+	// The default (0) is not to check the free space.
+	MinFreeSpace   int     `json:"minfreespace" yaml:"minfreespace"`
+	MinFreePercent float64 `json:"minfreepercent" yaml:"minfreepercent"`
+
+	// RejectLowSpaceWrites determines if writes fail with ErrLowDiskSpace
+	// while there is less free space than MinFreeSpace or MinFreePercent even
+	// after removing backups, rather than filling up the disk.
+	RejectLowSpaceWrites bool `json:"rejectlowspacewrites" yaml:"rejectlowspacewrites"`
+
+	// CompactBelow is the size in megabytes below which consecutive backups
+	// are merged into a single backup, to keep the number of files down when
+	// the log file is rotated often, e.g. by frequent calls to Rotate.  The
+	// combined backup is named after the newest of the backups it replaces,
+	// its metadata spans the writes to all of them, and it is no larger than
+	// MaxSize on disk.  Compressed backups are decompressed to be merged, and
+	// the combined backup is compressed and finalized like a newly rotated
+	// one.  Encrypted backups aren't merged, and neither are backups with
+	// SequentialBackups.  The default (0) is not to merge backups.
+	CompactBelow int `json:"compactbelow" yaml:"compactbelow"`
+
+	// DedupBackups determines if a backup that is byte for byte identical to
+	// the backup before it, e.g. because of repeated rotations of an empty log
+	// file by a scheduler, is removed instead of kept.  The time of its
+	// rotation is recorded in the Duplicates of the metadata of the backup it
+	// duplicates.  Compressed backups are compared decompressed.
+	DedupBackups bool `json:"dedupbackups" yaml:"dedupbackups"`
+
+	// AuditMode determines if backups are kept forever, for logs that must
+	// not be lost: no backup is ever removed, whatever MaxBackups, MaxAge,
+	// KeepDaily, KeepWeekly, MaxTotalSize, MinFreeSpace, MinFreePercent,
+	// DeleteArchived or a Manager say, and backups are neither merged by
+	// CompactBelow nor deduplicated by DedupBackups.  Backups are still
+	// compressed and finalized, after which each backup and the files that go
+	// with it are sealed: moved into a subdirectory of its directory for the
+	// day it was rotated, such as 2024/06/05, and made read-only.  Sealed
+	// backups are no longer listed by Backups or opened by OpenBackup.
+	AuditMode bool `json:"auditmode" yaml:"auditmode"`
+
 	// LocalTime determines if the time used for formatting the timestamps in
 	// backup files is the computer's local time.  The default is to use UTC
 	// time.
@@ -109,25 +215,556 @@ type Logger struct {
 	// using gzip. The default is not to perform compression.
 	Compress bool `json:"compress" yaml:"compress"`
 
+	// CompressionCodec is the name of the Compressor used when Compress is
+	// set, which must have been registered with RegisterCompressor.  The
+	// default is DefaultCompression, i.e. gzip.
+	CompressionCodec string `json:"compressioncodec" yaml:"compressioncodec"`
+
 	// KeepLastDecompressed determines the number of rotated logs to keep decompressed.
 	// This is only used if Compress is true. The default (0) is to compress all rotated logs.
 	KeepLastDecompressed int `json:"keeplastdecompressed" yaml:"keeplastdecompressed"`
 
+	// CompressMaxLoad is the system load above which compression is paused,
+	// expressed as the one minute load average divided by the number of CPUs,
+	// so 1 means all CPUs are busy.  Compression resumes once the load drops
+	// below it again.  The default (0) is to compress regardless of the load.
+	// The load is only known on Linux; elsewhere this has no effect.
+	CompressMaxLoad float64 `json:"compressmaxload" yaml:"compressmaxload"`
+
 	// TimeFormat determines the format to use for formatting the timestamp in
 	// backup files. The default format is defined in `DefaultTimeFormat`.
+	// Opening the log file fails if the format produces names that aren't
+	// valid file names, or that can't be parsed back to order the backups.
 	TimeFormat string `json:"timeformat" yaml:"timeformat"`
 
+	// TimePrecision determines the precision of the fractional seconds in the
+	// default time format, for when backups need telling apart at a higher
+	// rate than milliseconds, or not at all.  It is ignored if TimeFormat is
+	// set.  The default is TimePrecisionMilli, which gives DefaultTimeFormat.
+	TimePrecision TimePrecision `json:"timeprecision" yaml:"timeprecision"`
+
 	// BackupDir is the directory where backup files shall be saved to. The
 	// default is empty string which is resolved to where the active log file
 	// is located.
 	BackupDir string `json:"backupdir" yaml:"backupdir"`
 
-	size int64
-	file *os.File
-	mu   sync.Mutex
+	// BackupDirLayout is a time layout, such as "2006/01/02", for the
+	// subdirectories of the backup directory that backups are put in,
+	// according to their timestamps, to keep the number of files in a single
+	// directory down.  The cleanup looks for backups in these subdirectories,
+	// as well as in the backup directory itself, and removes the ones it
+	// empties.  It is ignored with SequentialBackups.  The default is to put
+	// backups in the backup directory itself.
+	BackupDirLayout string `json:"backupdirlayout" yaml:"backupdirlayout"`
+
+	// LinkDir is a directory that each backup is hard linked into when it is
+	// rotated, e.g. the pickup directory of a log shipper, which may remove
+	// the link once done without affecting the backup.  It must be on the
+	// same file system as the backups.  The default is not to link backups.
+	LinkDir string `json:"linkdir" yaml:"linkdir"`
+
+	// SymlinkCurrent determines if the log file is named like a backup from
+	// the start, in the backup directory, with Filename a symlink to it.
+	// Rotating it then means creating a new log file and pointing Filename
+	// at it, rather than renaming the log file while it may still be
+	// written, or read by a collector following Filename.  Backups are then
+	// named after the time they were started rather than the time they were
+	// rotated.  It doesn't work with SequentialBackups or PartitionBackups,
+	// and needs permission to create symlinks, which Windows only grants to
+	// administrators and in developer mode.
+	SymlinkCurrent bool `json:"symlinkcurrent" yaml:"symlinkcurrent"`
+
+	// NameCodec determines the names of backup files, and is used both to
+	// name new backups and to recognize existing ones.  The default names
+	// backups as described above, using TimeFormat.
+	NameCodec NameCodec `json:"-" yaml:"-"`
+
+	// SequentialBackups determines if backups are numbered like with the
+	// classic logrotate, e.g. foo.log.1, foo.log.2 and so on, with 1 for the
+	// newest backup, instead of having a timestamp in their names.  All
+	// backups are renumbered on each rotation, and MaxAge goes by their
+	// modification times.  It is ignored if NameCodec is set, and oversized
+	// log files aren't split even with SplitOversized.
+	SequentialBackups bool `json:"sequentialbackups" yaml:"sequentialbackups"`
+
+	// WriteMetadata determines if a small JSON metadata file is written next to
+	// each backup when it is rotated.  The metadata records the time of the
+	// first and last write to the backup, so time-window queries can be
+	// answered without opening the backups themselves.  The default is not to
+	// write metadata files.
+	WriteMetadata bool `json:"writemetadata" yaml:"writemetadata"`
+
+	// RunID identifies the run of the application writing the log file, such
+	// as a deploy or build ID.  If it is set, it is recorded in the metadata
+	// file of each backup, which is then written even if WriteMetadata isn't
+	// set, and returned by Backups, so backups can be traced back to the run
+	// that wrote them.
+	RunID string `json:"runid" yaml:"runid"`
+
+	// RestartMarker determines if a marker record is written to the log file
+	// when Write or Open first open it, so that gaps in the logs can be
+	// attributed to restarts of the application rather than data loss.  The
+	// marker holds a RestartInfo, formatted by FormatRestartMarker.  The
+	// default is not to write a marker.
+	RestartMarker bool `json:"restartmarker" yaml:"restartmarker"`
+
+	// FormatRestartMarker formats the marker written because of
+	// RestartMarker, which should end with a newline.  The default is a line
+	// of JSON, with "msg" set to "lumberjack: process restarted" and the
+	// fields of RestartInfo.
+	FormatRestartMarker func(RestartInfo) []byte `json:"-" yaml:"-"`
+
+	// FileHeader, if set, is called for the header written at the top of
+	// each new log file, such as the column names of a CSV log or a banner
+	// with the version of the application, so that every backup can be read
+	// on its own.  It isn't written to an existing log file the Logger
+	// appends to.
+	FileHeader func() []byte `json:"-" yaml:"-"`
+
+	// FileFooter, if set, is called for the footer written at the end of each
+	// log file when it is rotated.  It isn't written when the log file is
+	// closed, since the Logger appends to it when it opens it again.  The
+	// header and footer count toward MaxSize, but don't trigger a rotation
+	// themselves.
+	FileFooter func() []byte `json:"-" yaml:"-"`
+
+	// DropJournal, if set, is the name of a file to which a DropRecord is
+	// appended, as a line of JSON, for each window of time in which writes
+	// were lost, because an AsyncLogger's buffer was full or writing to the
+	// log file failed, e.g. because the disk was full.  Put it on another
+	// filesystem than the log file, so that it can be written when the log
+	// file can't.  The default is not to keep such a journal.
+	DropJournal string `json:"dropjournal" yaml:"dropjournal"`
+
+	// FileMode is the permission bits used when creating a new log file.  The
+	// default is to copy the mode of the log file being rotated, or to use 0600
+	// if there is none.
+	FileMode os.FileMode `json:"filemode" yaml:"filemode"`
+
+	// BackupFileMode is the permission bits given to backups when the log
+	// file is rotated, and to the files that replace them, such as the
+	// compressed backup.  The default is to keep the mode of the log file,
+	// including for the compressed and encrypted versions of backups.
+	BackupFileMode os.FileMode `json:"backupfilemode" yaml:"backupfilemode"`
+
+	// UseUmask determines if the process umask is applied to FileMode and
+	// DirMode when they are set.  The default is to force them exactly by
+	// changing the mode after the file or directory is created.  Log files
+	// and directories that go by the default modes are always subject to the
+	// umask, as they were before these settings were added.
+	UseUmask bool `json:"useumask" yaml:"useumask"`
+
+	// SharedFile determines if other processes, such as wrapper scripts, may
+	// append to the log file too.  The log file is always opened for
+	// appending, so their writes are never overwritten, but by default the
+	// Logger only counts its own writes towards MaxSize, and truncates a log
+	// file that someone else created while it was rotating.  With SharedFile,
+	// the Logger checks the size of the log file before each write, at the
+	// cost of an extra system call, and keeps whatever is in a new log file.
+	SharedFile bool `json:"sharedfile" yaml:"sharedfile"`
+
+	// SharedAppend determines if several processes, such as forked workers,
+	// write to the same Filename, each with a Logger of its own.  It implies
+	// SharedFile and LockRotation, and the processes follow the one that
+	// rotated the log file to the new log file, which is checked before each
+	// write, at the cost of two more system calls.
+	SharedAppend bool `json:"sharedappend" yaml:"sharedappend"`
+
+	// LockRotation determines if rotations and cleanups of old log files are
+	// coordinated with other processes using the same Filename, e.g. the old
+	// and new instance of a service during a deployment, with an advisory
+	// lock on a file named after Filename with a ".lock" suffix.  Only one
+	// process rotates the log file at a time, a process finding that another
+	// one rotated it opens the new log file instead of rotating it again, and
+	// the processes take turns scanning, removing and renaming the backups,
+	// so none of them removes or compresses a backup another one is working
+	// on.  Compressing and archiving backups don't hold up rotations, except
+	// with SequentialBackups, where the lock is held for the whole cleanup.
+	// The lock is only taken on Linux; elsewhere the processes aren't
+	// coordinated.
+	LockRotation bool `json:"lockrotation" yaml:"lockrotation"`
+
+	// DirMode is the permission bits used when creating the directories of the
+	// log file and its backups.  Only directories created by the Logger are
+	// affected.  The default is 0755.
+	DirMode os.FileMode `json:"dirmode" yaml:"dirmode"`
+
+	// DirGroup is the name or numeric ID of the group given to the directories
+	// created by the Logger.  The default is to leave the group the operating
+	// system assigns.
+	DirGroup string `json:"dirgroup" yaml:"dirgroup"`
+
+	// SELinuxLabel is the SELinux security context set on the directories
+	// created by the Logger, e.g. "system_u:object_r:var_log_t:s0".  It is
+	// only supported on Linux.  The default is to leave the context the
+	// policy assigns.
+	SELinuxLabel string `json:"selinuxlabel" yaml:"selinuxlabel"`
+
+	// SplitOversized determines if an existing log file that is already larger
+	// than MaxSize when it is opened is split into several backups of at most
+	// MaxSize each, rather than being rotated as a single oversized backup.
+	// The default is not to split files.
+	SplitOversized bool `json:"splitoversized" yaml:"splitoversized"`
+
+	// OversizeWrites determines what happens to a write larger than MaxSize,
+	// such as a large stack trace with a small MaxSize.  The default,
+	// OversizeReject, is for the write to fail.  OversizeFresh writes it to a
+	// log file of its own, and OversizeSplit splits it across log files.
+	OversizeWrites OversizePolicy `json:"oversizewrites" yaml:"oversizewrites"`
+
+	// ExistingBackups determines what happens when the name picked for a new
+	// backup is already taken, which can happen when several processes
+	// rotate the same log file or backups are copied back in from elsewhere.
+	// The default, ExistingOverwrite, is to move the log file over the
+	// existing backup.  Either way, the existing backup is reported in the
+	// Existing field of the RotationInfo.  Backups the Logger makes itself in
+	// the same instant, or within the same TimeFormat timestamp, never clash:
+	// they are given sequence numbers, e.g. foo-2016-11-04-001.log.
+	ExistingBackups ExistingBackupPolicy `json:"existingbackups" yaml:"existingbackups"`
+
+	// BackupDirs is a list of directories to spread backup files across, to
+	// distribute inode and I/O load over several volumes.  When set, it takes
+	// precedence over BackupDir.  Retention considers the backups in all of the
+	// directories together.
+	BackupDirs []string `json:"backupdirs" yaml:"backupdirs"`
+
+	// BackupShardMode determines how a directory is picked from BackupDirs for
+	// each backup.  It is either "roundrobin" (the default), which cycles
+	// through the directories in order, or "hash", which picks a directory
+	// based on a hash of the backup's name.
+	BackupShardMode string `json:"backupshardmode" yaml:"backupshardmode"`
+
+	// BufferSize is the size in bytes of a buffer for writes, so that many
+	// small writes make fewer, larger writes to the log file.  Buffered data
+	// is written out once the buffer is full, by Flush, Sync, Rotate and
+	// Close, and before the log file is rotated or closed for any other
+	// reason, so unlike with a bufio.Writer wrapping the Logger, nothing ends
+	// up in the wrong file.  The default (0) is not to buffer writes.
+	BufferSize int `json:"buffersize" yaml:"buffersize"`
+
+	// WriteFilter, if set, is called with each write before it is written,
+	// and returns what to write instead, or false to drop the write, e.g. to
+	// sample, redact or deduplicate log records; see SuppressRepeats.  Before
+	// the log file is rotated or closed, it is called with nil, and what it
+	// returns is written to the log file, so that a filter holding back a
+	// summary can put it in the log file it belongs to.  It is called while
+	// the Logger is locked, one write at a time, so it must not call back
+	// into the Logger.
+	WriteFilter func(p []byte) (out []byte, keep bool) `json:"-" yaml:"-"`
+
+	// MaxBytesPerSecond is the rate in bytes per second that writes to the
+	// log file are limited to, with bursts of up to a second's worth, so that
+	// a misbehaving component can't flood the disk.  RateLimitPolicy decides
+	// what happens to writes over the rate.  The default (0) is not to limit
+	// the rate.
+	MaxBytesPerSecond int `json:"maxbytespersecond" yaml:"maxbytespersecond"`
+
+	// RateLimitPolicy determines what happens to a write that would exceed
+	// MaxBytesPerSecond.  The default is to wait until it is within the rate.
+	RateLimitPolicy RateLimitPolicy `json:"ratelimitpolicy" yaml:"ratelimitpolicy"`
+
+	// SlowWriteThreshold is the duration after which a single Write is
+	// considered slow and reported to OnSlowWrite.  The default (0) disables
+	// slow write detection.
+	SlowWriteThreshold time.Duration `json:"slowwritethreshold" yaml:"slowwritethreshold"`
+
+	// OnSlowWrite is called when a Write takes longer than SlowWriteThreshold.
+	// It is called while the Logger is locked, so it must not call back into
+	// the Logger.
+	OnSlowWrite func(SlowWrite) `json:"-" yaml:"-"`
+
+	// WriteTimeout is the maximum time a single write to the log file may
+	// block, e.g. on a hung network filesystem.  When it is exceeded, Write
+	// returns ErrWriteTimeout, and further writes and rotations fail straight
+	// away with the same error until the stalled write completes.  The default
+	// (0) is to wait for writes indefinitely.
+	WriteTimeout time.Duration `json:"writetimeout" yaml:"writetimeout"`
+
+	// CloseAfterIdle is the duration after which the log file is closed if
+	// nothing has been written to it, releasing its file handle.  The file is
+	// reopened on the next Write.  The default (0) is to keep the file open
+	// until Close is called.
+	CloseAfterIdle time.Duration `json:"closeafteridle" yaml:"closeafteridle"`
+
+	// SyncPolicy determines when the log file is committed to stable storage,
+	// so that writes survive a power loss, and SyncInterval how long after a
+	// write with SyncPeriodic, one second by default.  The default is to
+	// leave it to the operating system, except when Sync is called.
+	SyncPolicy   SyncPolicy    `json:"syncpolicy" yaml:"syncpolicy"`
+	SyncInterval time.Duration `json:"syncinterval" yaml:"syncinterval"`
+
+	// WatchExternalChanges determines if the log file is watched for being
+	// removed, renamed, replaced or truncated by something else, e.g. an
+	// external logrotate.  When that happens the log file is closed right
+	// away, and the next Write opens the file at Filename afresh.  Changes are
+	// detected with inotify on Linux, and by checking the file every second.
+	// The check also catches Filename resolving to a different file without
+	// the log file itself changing, e.g. when a bind mount is flipped or a
+	// volume is remounted, which inotify doesn't report.  The default is not
+	// to watch the log file.
+	WatchExternalChanges bool `json:"watchexternalchanges" yaml:"watchexternalchanges"`
+
+	// OnExternalChange is called with the name of the log file and what
+	// happened to it when WatchExternalChanges closes it, for diagnostics.
+	// It is called while the Logger is locked, so it must not call back into
+	// the Logger.
+	OnExternalChange func(name string, reason ChangeReason) `json:"-" yaml:"-"`
+
+	// PartitionBackups determines if backups are placed in subdirectories of
+	// the backup directory according to the reason for the rotation: "manual"
+	// for rotations requested by calling Rotate, and "auto" for all others.
+	// Each partition is cleaned up on its own, with MaxBackups applying to
+	// the "auto" partition and MaxManualBackups to the "manual" one, so manual
+	// snapshots don't push automatic backups out.  The default is to keep all
+	// backups together.
+	PartitionBackups bool `json:"partitionbackups" yaml:"partitionbackups"`
+
+	// MaxManualBackups is the maximum number of manual backups to retain when
+	// PartitionBackups is set.  The default is to retain all manual backups
+	// (though MaxAge may still cause them to get deleted.)
+	MaxManualBackups int `json:"maxmanualbackups" yaml:"maxmanualbackups"`
+
+	// KeepUnshipped determines if backups are kept until they have been
+	// marked as shipped by calling MarkShipped, regardless of MaxBackups,
+	// MaxAge and any Manager, so backups aren't lost when whatever ships them
+	// elsewhere lags behind.  The default is to remove backups whether they
+	// have been shipped or not.
+	KeepUnshipped bool `json:"keepunshipped" yaml:"keepunshipped"`
+
+	// OpenRetryBackoff is the time Write waits before trying to open the log
+	// file again after opening it failed, e.g. because of a bad mount.  Until
+	// then, Write returns the error from the failed attempt.  The wait doubles
+	// with each consecutive failure, up to MaxOpenRetryBackoff.  The default
+	// (0) is to try opening the file on every Write.
+	OpenRetryBackoff time.Duration `json:"openretrybackoff" yaml:"openretrybackoff"`
+
+	// MaxOpenRetryBackoff is the longest wait between attempts to open the log
+	// file.  The default is one minute.
+	MaxOpenRetryBackoff time.Duration `json:"maxopenretrybackoff" yaml:"maxopenretrybackoff"`
+
+	// RenameRetries is the number of times rotation tries again to move the
+	// log file aside when renaming it fails because it is busy, e.g. because
+	// a virus scanner or a tailer holds it open on Windows, waiting
+	// RenameRetryBackoff before the first retry, and twice as long before
+	// each retry after that.  Other errors, such as a BackupDir on another
+	// file system, aren't retried.  The Logger is locked while it waits, so
+	// the retries stop once a second has been spent waiting.  The default
+	// (0) is not to retry, and the default RenameRetryBackoff is 10ms.
+	RenameRetries      int           `json:"renameretries" yaml:"renameretries"`
+	RenameRetryBackoff time.Duration `json:"renameretrybackoff" yaml:"renameretrybackoff"`
+
+	// RenameFallbackCopy determines if the log file is copied to the backup
+	// and then truncated when it can't be renamed, even after RenameRetries.
+	// Writes by other processes between the copy and the truncation are
+	// lost, so it is a last resort.  Such rotations are counted in
+	// Stats.RenameCopies.
+	RenameFallbackCopy bool `json:"renamefallbackcopy" yaml:"renamefallbackcopy"`
+
+	// OnOpenError is called each time opening the log file fails, with the
+	// error, the number of consecutive failures and the time until the next
+	// attempt.  It is called while the Logger is locked, so it must not call
+	// back into the Logger.
+	OnOpenError func(err error, failures int, retryIn time.Duration) `json:"-" yaml:"-"`
+
+	// Signer, if set, is called with the content of each backup once it is
+	// finalized, i.e. after it has been compressed if Compress is set, and the
+	// detached signature it returns is written next to the backup with a
+	// ".sig" suffix.  Use e.g. an OpenPGP library to produce signatures that
+	// can be verified with GPG.  It is called from the goroutine that cleans
+	// up old log files.
+	Signer func(r io.Reader) ([]byte, error) `json:"-" yaml:"-"`
+
+	// Checksum, if set, is the name of the hash algorithm used to checksum
+	// each backup once it is finalized, like Signer.  The checksum is written
+	// next to the backup, with the name of the algorithm as suffix, in the
+	// format of sha256sum and the like.  "sha256" and "sha512" are built in,
+	// others can be added with RegisterChecksum.
+	Checksum string `json:"checksum" yaml:"checksum"`
+
+	// WriteChecksums determines if a sha256 checksum is written next to each
+	// backup once it is finalized, as a .sha256 file, so that pipelines
+	// shipping backups can verify them without hashing them themselves.  It
+	// is the same as setting Checksum to "sha256", which takes precedence.
+	WriteChecksums bool `json:"writechecksums" yaml:"writechecksums"`
+
+	// Encryption, if set, is the name of the Encrypter, registered with
+	// RegisterEncrypter, used to encrypt each backup once it is finalized,
+	// before it is signed, checksummed and archived.  The suffix of the
+	// Encrypter is appended to the name of the backup, e.g.
+	// foo-2016-11-04T18-30-00.000.log.gz.enc, and the plaintext backup is
+	// removed.  Encryption happens in the goroutine that cleans up old log
+	// files, so it doesn't race with the removal of old backups.  If it
+	// fails, the error is reported and the backup is encrypted again each
+	// time old log files are cleaned up, until it succeeds.
+	Encryption string `json:"encryption" yaml:"encryption"`
+
+	// OnRotate is called after each rotation of the log file.  It is called
+	// while the Logger is locked, so it must not call back into the Logger.
+	OnRotate func(RotationInfo) `json:"-" yaml:"-"`
+
+	// OnCompress is called with the name of each backup compressed because of
+	// Compress, once it is compressed.  OnFinalize is called with the name of
+	// each backup once it is finalized, i.e. after it has been compressed,
+	// encrypted, signed and archived as configured, so it won't be changed
+	// any more and can be shipped elsewhere.  OnRemove is called with the
+	// name of each backup removed by the cleanup of old log files.  They are
+	// called from the goroutine that cleans up old log files, or from the one
+	// calling Manager.Enforce for OnRemove.
+	OnCompress func(name string) `json:"-" yaml:"-"`
+	OnFinalize func(name string) `json:"-" yaml:"-"`
+	OnRemove   func(name string) `json:"-" yaml:"-"`
+
+	// OnError is called with the first error of each run of the cleanup of
+	// old log files that fails, e.g. because a backup can't be compressed or
+	// removed, or can't be archived.  It is called from the goroutine that
+	// cleans up old log files, which carries on with the next run regardless.
+	OnError func(err error) `json:"-" yaml:"-"`
+
+	// Archiver, if set, is given each backup once it is finalized, i.e. after
+	// it has been compressed and signed as configured, to copy it elsewhere.
+	// It is called from the goroutine that cleans up old log files.
+	Archiver Archiver `json:"-" yaml:"-"`
+
+	// ArchiveTimeout is how long the Archiver may take to archive a backup,
+	// after which the context given to it is canceled.  The default (0) is no
+	// timeout.
+	ArchiveTimeout time.Duration `json:"archivetimeout" yaml:"archivetimeout"`
+
+	// FilesystemTimeout is the maximum time reading a backup directory or
+	// removing a backup may take, so that a hung network filesystem fails the
+	// cleanup of old log files with ErrFilesystemTimeout instead of blocking
+	// it forever.  The call that timed out keeps running in the background,
+	// and further such calls fail straight away until it completes.  The
+	// default (0) is no timeout.
+	FilesystemTimeout time.Duration `json:"filesystemtimeout" yaml:"filesystemtimeout"`
+
+	// DeleteArchived determines if backups are removed from the local disk
+	// once the Archiver has archived them, except for the newest
+	// KeepLocalBackups.  The default is to keep archived backups, subject to
+	// MaxBackups and MaxAge.
+	DeleteArchived bool `json:"deletearchived" yaml:"deletearchived"`
+
+	// KeepLocalBackups is the number of newest backups that are kept on the
+	// local disk for fast access, regardless of DeleteArchived, MaxBackups,
+	// MaxAge and any Manager.  The default (0) is not to keep any backups
+	// beyond what those allow.
+	KeepLocalBackups int `json:"keeplocalbackups" yaml:"keeplocalbackups"`
+
+	// Clock, if set, is used instead of the system clock to tell the time
+	// for backup names and retention, and to run the timers for MaxInterval,
+	// RotateAt, CloseAfterIdle and WatchExternalChanges unless a Scheduler is
+	// set.  It exists so that tests can control time; see
+	// lumberjacktest.FakeClock.
+	Clock Clock `json:"-" yaml:"-"`
+
+	// Scheduler, if set, runs the timed work of the Logger instead of the
+	// Clock: rotations for MaxInterval and RotateAt, the checks for
+	// CloseAfterIdle and WatchExternalChanges, and the syncs of SyncPeriodic.
+	// Sharing a TimerScheduler with Jitter among many Loggers keeps them from
+	// all doing that work at the same moment.
+	Scheduler Scheduler `json:"-" yaml:"-"`
+
+	size         int64
+	file         *os.File
+	firstWrite   time.Time
+	lastWrite    time.Time
+	shard        int
+	stalled      chan writeResult
+	idleTimer    func() bool
+	syncTimer    func() bool
+	lockHeld     bool
+	lastActive   time.Time
+	stats        Stats
+	openErr      error
+	openFails    int
+	openRetry    time.Time
+	special      bool
+	lastRotation RotationInfo
+	watchStop    chan struct{}
+	lastStamp    time.Time
+	lastSeq      int
+	lastKnown    bool
+	rotateDue    time.Time
+	rotateReason RotationReason
+	rotateTimer  func() bool
+	restarted    bool
+	midLine      bool
+	spaceChecked time.Time
+	spaceLow     bool
+	rateTokens   float64
+	rateTime     time.Time
+	mu           sync.Mutex
+
+	// buf holds the writes buffered because of BufferSize.
+	buf []byte
 
 	millCh    chan bool
 	startMill sync.Once
+
+	// millPending is the number of mill runs requested and not finished yet,
+	// and millIdle is closed once it drops to 0.  millErr is the first error
+	// of a run since the last call to Mill.  millQueued reports whether a run
+	// is queued with the Manager's shared mill.  millMu guards them.
+	millMu      sync.Mutex
+	millPending int
+	millIdle    chan struct{}
+	millErr     error
+	millQueued  bool
+
+	// reading holds the readers of the backups opened with OpenBackup, which
+	// the mill leaves alone.  readingMu guards it.
+	readingMu sync.Mutex
+	reading   map[string]*readers
+
+	// fsStalled is closed once a filesystem call that timed out completes.
+	// fsMu guards it.
+	fsMu      sync.Mutex
+	fsStalled chan struct{}
+
+	// drops holds the open windows of lost writes for the DropJournal, by
+	// reason.  dropsMu guards it.
+	dropsMu sync.Mutex
+	drops   map[string]*DropRecord
+
+	// signals receives the signals given to RotateOnSignal, until signalStop
+	// is closed.
+	signals    chan os.Signal
+	signalStop chan struct{}
+
+	// configStop stops the checks of the config file given to
+	// WatchConfigFile.
+	configStop chan struct{}
+
+	// rotated holds the backups created since the mill last ran, which are
+	// finalized by the mill.  rotatedMu guards it, as well as lastRotation and
+	// the statistics kept by the mill, which the mill updates without the
+	// Logger locked.
+	rotated   []string
+	rotatedMu sync.Mutex
+
+	// unfinalized holds the backups whose finalization failed, which the mill
+	// tries again each time it runs.  rotatedMu guards it.
+	unfinalized []string
+
+	// current is the name of the log file created last because of
+	// SymlinkCurrent, which the mill mustn't take for a backup.  rotatedMu
+	// guards it.
+	current string
+
+	// archived holds the backups archived by the mill that are waiting to be
+	// removed because of DeleteArchived.  It is only used by the mill, and
+	// when backups are renumbered.
+	archived map[string]bool
+
+	// seqMu keeps the mill from working on backups while they're renumbered
+	// because of SequentialBackups.
+	seqMu sync.Mutex
+
+	// configMu keeps the mill from working on backups while UpdateConfig
+	// changes the settings.  The mill holds it for reading.
+	configMu sync.RWMutex
+
+	manager *Manager
 }
 
 var (
@@ -137,6 +774,12 @@ var (
 	// os_Stat exists so it can be mocked out by tests.
 	os_Stat = os.Stat
 
+	// file_Write exists so it can be mocked out by tests.
+	file_Write = (*os.File).Write
+
+	// os_Remove exists so it can be mocked out by tests.
+	os_Remove = os.Remove
+
 	// megabyte is the conversion factor between MaxSize and bytes.  It is a
 	// variable so tests can mock it out and not need to write megabytes of data
 	// to disk.
@@ -146,50 +789,260 @@ var (
 // Write implements io.Writer.  If a write would cause the log file to be larger
 // than MaxSize, the file is closed, renamed to include a timestamp of the
 // current time, and a new log file is created using the original log file name.
-// If the length of the write is greater than MaxSize, an error is returned.
+// A write larger than MaxSize is handled according to OversizeWrites: by
+// default an error is returned, OversizeFresh writes it to a log file of its
+// own, and OversizeSplit splits it across log files of at most MaxSize.
 func (l *Logger) Write(p []byte) (n int, err error) {
 	l.mu.Lock()
 	defer l.mu.Unlock()
+	l.stats.Writes++
+	if l.BufferSize > 0 {
+		return l.writeFiltered(p, l.writeBuffered)
+	}
+	return l.writeFiltered(p, l.write)
+}
 
+// write does the work of Write, assuming the Logger is locked.
+func (l *Logger) write(p []byte) (n int, err error) {
+	defer func() {
+		if err == ErrLowDiskSpace {
+			l.stats.WriteErrors++
+			l.recordDrop(DropLowSpace, len(p)-n)
+		} else if err != nil {
+			l.stats.WriteErrors++
+			l.recordDrop(DropWriteError, len(p)-n)
+		} else {
+			l.endDrops(DropWriteError)
+		}
+	}()
+	if l.file == nil {
+		if _, err := l.openSpecial(); err != nil {
+			return 0, err
+		}
+	}
+	if l.special {
+		n, err = l.writeFile(p)
+		l.countBytes(n)
+		return n, err
+	}
+
+	if int64(len(p)) > l.max() {
+		switch l.OversizeWrites {
+		case OversizeSplit:
+			return l.writeSplit(p)
+		case OversizeFresh:
+			// written below, to a log file of its own.
+		default:
+			return 0, fmt.Errorf(
+				"write length %d exceeds maximum file size %d", len(p), l.max(),
+			)
+		}
+	}
+	return l.writeChunk(p)
+}
+
+// writeChunk writes p to the log file, opening and rotating it as needed.
+func (l *Logger) writeChunk(p []byte) (n int, err error) {
 	writeLen := int64(len(p))
-	if writeLen > l.max() {
-		return 0, fmt.Errorf(
-			"write length %d exceeds maximum file size %d", writeLen, l.max(),
-		)
+	if err := l.checkStalled(); err != nil {
+		return 0, err
 	}
+	if err := l.checkSpace(); err != nil {
+		return 0, err
+	}
+
+	timer := l.newWriteTimer()
+	defer timer.report(l)
 
 	if l.file == nil {
-		if err = l.openExistingOrNew(len(p)); err != nil {
+		if err := l.openBackoff(); err != nil {
+			return 0, err
+		}
+		restart := l.restartInfo()
+		start := timer.begin()
+		err = l.openExistingOrNew(len(p))
+		timer.end(SlowWriteOpen, start)
+		l.openResult(err)
+		if err != nil {
+			return 0, err
+		}
+		if restart != nil {
+			if err := l.writeRestartMarker(*restart); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := l.followShared(); err != nil {
+		return 0, err
+	}
+
+	if l.RotateOnLineBoundary && l.midLine && (l.rotationDue() || l.size+writeLen > l.max()) {
+		// the rest of the line goes to the log file before it is rotated.
+		end := len(p)
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			end = i + 1
+		}
+		n, err = l.writeCurrent(p[:end], timer)
+		if err != nil || n == len(p) {
+			return n, err
+		}
+		m, err := l.writeChunk(p[n:])
+		return n + m, err
+	}
+
+	if l.rotationDue() {
+		start := timer.begin()
+		err := l.rotate(l.rotateReason)
+		timer.end(SlowWriteRotate, start)
+		if err != nil {
 			return 0, err
 		}
 	}
 
-	if l.size+writeLen > l.max() {
-		if err := l.rotate(); err != nil {
+	l.syncSize()
+	// a write larger than MaxSize doesn't rotate a log file that is empty.
+	if l.size+writeLen > l.max() && l.size > 0 {
+		start := timer.begin()
+		err := l.rotate(RotationSize)
+		timer.end(SlowWriteRotate, start)
+		if err != nil {
 			return 0, err
 		}
 	}
 
-	n, err = l.file.Write(p)
+	return l.writeCurrent(p, timer)
+}
+
+// writeCurrent writes p to the open log file, without rotating it.
+func (l *Logger) writeCurrent(p []byte, timer *writeTimer) (int, error) {
+	start := timer.begin()
+	n, err := l.writeFile(p)
+	timer.end(SlowWriteWrite, start)
 	l.size += int64(n)
+	l.countBytes(n)
+	if n > 0 {
+		l.recordWrite()
+		l.midLine = p[n-1] != '\n'
+		if errSync := l.syncWritten(); err == nil {
+			err = errSync
+		}
+	}
+	l.resetIdle()
 
 	return n, err
 }
 
+// recordWrite updates the first and last write times of the current file.
+func (l *Logger) recordWrite() {
+	now := l.now()
+	if l.firstWrite.IsZero() {
+		l.firstWrite = now
+	}
+	l.lastWrite = now
+}
+
+// WriteTimes returns the times of the first and last write to the current log
+// file.  The first write time is zero if nothing has been written to the
+// file by this Logger, e.g. when it was opened after a restart.
+func (l *Logger) WriteTimes() (first, last time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.firstWrite, l.lastWrite
+}
+
+// Open opens the logfile, creating it if necessary, without writing to it.
+// This lets applications report problems such as a bad path or missing
+// permissions at startup rather than on the first Write.  Calling Open is
+// optional, since Write opens the logfile as needed, and it does nothing if
+// the logfile is already open.
+func (l *Logger) Open() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != nil {
+		return nil
+	}
+	if special, err := l.openSpecial(); special || err != nil {
+		return err
+	}
+	restart := l.restartInfo()
+	if err := l.openExistingOrNew(0); err != nil {
+		return err
+	}
+	if restart != nil {
+		if err := l.writeRestartMarker(*restart); err != nil {
+			return err
+		}
+	}
+	l.resetIdle()
+	return nil
+}
+
 // Close implements io.Closer, and closes the current logfile.
 func (l *Logger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	return l.close()
+	err := l.flush()
+	l.stopSchedule()
+	l.stopSignals()
+	l.stopConfigWatch()
+	l.endAllDrops()
+	if errClose := l.close(); err == nil {
+		err = errClose
+	}
+	return err
+}
+
+// Sync writes any buffered data to the log file, and commits it to stable
+// storage, like os.File.Sync.
+func (l *Logger) Sync() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flush(); err != nil {
+		return err
+	}
+	return l.sync()
+}
+
+// sync commits the file to stable storage if it is open.  Special files such
+// as stdout are left alone, since most of them can't be synced.
+func (l *Logger) sync() error {
+	if l.file == nil || l.special {
+		return nil
+	}
+	if err := l.file.Sync(); err != nil {
+		return err
+	}
+	l.stats.Syncs++
+	return nil
 }
 
 // close closes the file if it is open.
 func (l *Logger) close() error {
+	return l.closeWith(nil, l.syncOnClose(false))
+}
+
+// closeWith closes the file if it is open, after writing tail to it, and
+// syncing it if sync is set.
+func (l *Logger) closeWith(tail []byte, sync bool) error {
+	l.stopIdle()
+	l.stopWatch()
+	l.stopSync()
 	if l.file == nil {
 		return nil
 	}
+	l.buf = append(l.buf, l.filterTail()...)
+	l.buf = append(l.buf, tail...)
+	errFlush := l.flushClosing()
+	if sync && errFlush == nil && l.stalled == nil {
+		errFlush = l.sync()
+	}
 	err := l.file.Close()
 	l.file = nil
+	l.special = false
+	if err == nil {
+		err = errFlush
+	}
 	return err
 }
 
@@ -201,46 +1054,148 @@ func (l *Logger) close() error {
 func (l *Logger) Rotate() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
-	return l.rotate()
+	if err := l.flush(); err != nil {
+		return err
+	}
+	return l.rotate(RotationManual)
+}
+
+// Reopen closes the log file and opens the file at Filename again, without
+// moving the log file aside.  This is for applications whose log files are
+// rotated by an external tool, such as logrotate, which moves the log file or
+// truncates it and then asks for it to be reopened, e.g. with SIGHUP.  If
+// there is no file at Filename any more, a new one is created.
+func (l *Logger) Reopen() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if err := l.flush(); err != nil {
+		return err
+	}
+	if err := l.checkStalled(); err != nil {
+		return err
+	}
+	if err := l.close(); err != nil {
+		return err
+	}
+	if special, err := l.openSpecial(); special || err != nil {
+		return err
+	}
+	if err := l.openExistingOrNew(0); err != nil {
+		return err
+	}
+	l.resetIdle()
+	return nil
 }
 
 // rotate closes the current file, moves it aside with a timestamp in the name,
 // (if it exists), opens a new file with the original filename, and then runs
-// post-rotation processing and removal.
-func (l *Logger) rotate() error {
-	if err := l.close(); err != nil {
+// post-rotation processing and removal.  The reason determines the partition
+// the backup is placed in.
+func (l *Logger) rotate(reason RotationReason) error {
+	if l.special {
+		return nil
+	}
+	if err := l.checkStalled(); err != nil {
+		return err
+	}
+	unlock, err := l.lockRotation()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if l.coordinated() && l.file != nil && !l.isCurrentFile() {
+		// another process rotated the log file already.
+		return l.reopenShared()
+	}
+	// the log file is kept open if the backup can't be named.
+	if err := l.checkTimeFormat(); err != nil {
+		return err
+	}
+	// a rotation takes no space, so it goes ahead even if space is low.
+	_ = l.checkSpace()
+	if err := l.closeWith(l.footer(), l.syncOnClose(true)); err != nil {
 		return err
 	}
-	if err := l.openNew(); err != nil {
+	if err := l.openNew(reason); err != nil {
 		return err
 	}
+	l.resetIdle()
 	l.mill()
 	return nil
 }
 
 // openNew opens a new log file for writing, moving any old log file out of the
-// way.  This methods assumes the file has already been closed.
-func (l *Logger) openNew() error {
-	err := os.MkdirAll(l.dir(), 0755)
+// way for the given reason.  This methods assumes the file has already been
+// closed.
+func (l *Logger) openNew(reason RotationReason) error {
+	start := time.Now()
+	err := l.mkdirAll(l.dir())
 	if err != nil {
 		return fmt.Errorf("can't make directories for new logfile: %s", err)
 	}
 
-	name := l.filename()
+	name := l.activeFilename()
 	mode := os.FileMode(0600)
+	var rotation *RotationInfo
 	info, err := os_Stat(name)
-	if err == nil {
+	if err == nil && l.isCurrentLink(name) {
+		// the log file already has its backup name.
+		mode = info.Mode()
+		if err := l.chmodBackup(name); err != nil {
+			return err
+		}
+		l.queueFinalize(name)
+		rotation = &RotationInfo{
+			OldPath:    l.filename(),
+			NewPath:    name,
+			Reason:     reason,
+			Time:       l.now(),
+			Bytes:      info.Size(),
+			FirstWrite: l.firstWrite,
+			LastWrite:  l.lastWrite,
+			RunID:      l.RunID,
+		}
+		rotation.BackupDirFree, rotation.BackupDirUsed, _ = statDisk(filepath.Dir(name))
+	} else if err == nil {
 		// Copy the mode off the old logfile.
 		mode = info.Mode()
 		// move the existing file
-		newname := l.backupName(name, l.LocalTime)
-		err := os.MkdirAll(filepath.Dir(newname), 0755)
+		if err := l.checkTimeFormat(); err != nil {
+			return err
+		}
+		newname := l.layoutName(l.partitionName(l.backupName(l.LocalTime), reason))
+		err := l.mkdirAll(filepath.Dir(newname))
 		if err != nil {
 			return fmt.Errorf("can't make directories for backup logfile: %s", err)
 		}
-		if err := os.Rename(name, newname); err != nil {
-			return fmt.Errorf("can't rename log file: %s", err)
+		if l.SequentialBackups {
+			if err := l.shiftBackups(filepath.Dir(newname)); err != nil {
+				return err
+			}
+		}
+		newname, existing, err := l.resolveExisting(newname)
+		if err != nil {
+			return err
+		}
+		if err := l.renameLogFile(name, newname); err != nil {
+			return err
+		}
+		if err := l.chmodBackup(newname); err != nil {
+			return err
+		}
+		l.queueFinalize(newname)
+		rotation = &RotationInfo{
+			OldPath:    name,
+			NewPath:    newname,
+			Reason:     reason,
+			Time:       l.now(),
+			Bytes:      info.Size(),
+			FirstWrite: l.firstWrite,
+			LastWrite:  l.lastWrite,
+			RunID:      l.RunID,
+			Existing:   existing,
 		}
+		rotation.BackupDirFree, rotation.BackupDirUsed, _ = statDisk(filepath.Dir(newname))
 
 		// this is a no-op anywhere but linux
 		if err := chown(name, info); err != nil {
@@ -248,157 +1203,417 @@ func (l *Logger) openNew() error {
 		}
 	}
 
+	if l.FileMode != 0 {
+		mode = l.FileMode
+	}
+
 	// we use truncate here because this should only get called when we've moved
 	// the file ourselves. if someone else creates the file in the meantime,
-	// just wipe out the contents.
-	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	// just wipe out the contents, unless the file is shared.
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if l.SymlinkCurrent {
+		// a new name, which must not be a backup.
+		if name, err = l.newCurrentName(); err != nil {
+			return err
+		}
+		flags |= os.O_EXCL
+	} else if !l.shared() {
+		flags |= os.O_TRUNC
+	}
+	f, err := os.OpenFile(name, flags, mode)
 	if err != nil {
 		return fmt.Errorf("can't open new logfile: %s", err)
 	}
+	if l.FileMode != 0 && !l.UseUmask {
+		if err := f.Chmod(mode.Perm()); err != nil {
+			f.Close()
+			return fmt.Errorf("can't set mode of new logfile: %s", err)
+		}
+	}
+	if l.SymlinkCurrent {
+		if err := l.linkCurrent(name); err != nil {
+			f.Close()
+			return err
+		}
+	}
 	l.file = f
 	l.size = 0
+	l.midLine = false
+	l.syncSize()
+	l.stats.BytesSinceRotation = 0
+	l.firstWrite = time.Time{}
+	l.lastWrite = time.Time{}
+	// the rotation is done even if the header can't be written.
+	errHeader := l.writeHeader()
+	l.startWatch(name)
+	l.schedule(true)
+
+	if rotation != nil {
+		rotation.Duration = time.Since(start)
+		if err := l.recordRotation(*rotation); err != nil {
+			return err
+		}
+	}
+	return errHeader
+}
+
+// recordRotation records a finished rotation in the stats, writes its
+// metadata and links the backup if requested, and reports it to OnRotate.
+func (l *Logger) recordRotation(rotation RotationInfo) error {
+	l.rotatedMu.Lock()
+	l.lastRotation = rotation
+	l.rotatedMu.Unlock()
+	l.stats.Rotations++
+	if l.WriteMetadata || l.RunID != "" {
+		if err := writeMetadata(rotation.NewPath, rotation); err != nil {
+			return err
+		}
+	}
+	if err := l.linkBackup(rotation.NewPath); err != nil {
+		return err
+	}
+	if l.OnRotate != nil {
+		l.OnRotate(rotation)
+	}
 	return nil
 }
 
-// backupName creates a new filename from the given name, inserting a timestamp
-// between the filename and the extension, using the local time if requested
-// (otherwise UTC).
-func (l *Logger) backupName(name string, local bool) string {
-	dir := l.backupDir()
-	filename := filepath.Base(name)
-	ext := filepath.Ext(filename)
-	prefix := filename[:len(filename)-len(ext)]
-	t := currentTime()
+// backupNameAt creates a filename for a backup of the log file, using the
+// NameCodec to put the given time and sequence number in it, using the local
+// time if requested (otherwise UTC).
+func (l *Logger) backupNameAt(t time.Time, seq int, local bool) string {
 	if !local {
 		t = t.UTC()
 	}
-
-	timestamp := t.Format(l.timeFormat())
-	return filepath.Join(dir, fmt.Sprintf("%s-%s%s", prefix, timestamp, ext))
+	name := l.codec().Encode(t, seq)
+	return filepath.Join(l.shardDir(name), name)
 }
 
 func (l *Logger) backupDir() string {
 	if l.BackupDir != "" {
-		return l.BackupDir
+		return fixPath(l.BackupDir)
 	}
 	return l.dir()
 }
 
+// backupDirs returns all the directories that backups are stored in.
+func (l *Logger) backupDirs() []string {
+	if len(l.BackupDirs) > 0 {
+		dirs := make([]string, len(l.BackupDirs))
+		for i, dir := range l.BackupDirs {
+			dirs[i] = fixPath(dir)
+		}
+		return dirs
+	}
+	return []string{l.backupDir()}
+}
+
+// shardDir picks the directory for a new backup with the given name.
+func (l *Logger) shardDir(name string) string {
+	dirs := l.backupDirs()
+	if len(dirs) == 1 {
+		return dirs[0]
+	}
+	if l.BackupShardMode == "hash" {
+		h := fnv.New32a()
+		h.Write([]byte(name))
+		return dirs[h.Sum32()%uint32(len(dirs))]
+	}
+	dir := dirs[l.shard%len(dirs)]
+	l.shard++
+	return dir
+}
+
 func (l *Logger) timeFormat() string {
 	if l.TimeFormat != "" {
 		return l.TimeFormat
 	}
-	return DefaultTimeFormat
+	return l.TimePrecision.format()
 }
 
 // openExistingOrNew opens the logfile if it exists and if the current write
 // would not put it over MaxSize.  If there is no such file or the write would
 // put it over the MaxSize, a new file is created.
 func (l *Logger) openExistingOrNew(writeLen int) error {
+	// a TimeFormat the backups can't be named with fails before anything is
+	// written, rather than at the first rotation.
+	if err := l.checkTimeFormat(); err != nil {
+		return err
+	}
 	l.mill()
 
-	filename := l.filename()
+	filename := l.activeFilename()
 	info, err := os_Stat(filename)
 	if os.IsNotExist(err) {
-		return l.openNew()
+		return l.openNew(RotationSize)
 	}
 	if err != nil {
 		return fmt.Errorf("error getting log file info: %s", err)
 	}
 
-	if info.Size()+int64(writeLen) >= l.max() {
-		return l.rotate()
+	// an empty log file is kept for a write larger than MaxSize.
+	oversize := info.Size() == 0 && int64(writeLen) > l.max()
+	if info.Size()+int64(writeLen) >= l.max() && !oversize {
+		if l.SplitOversized && info.Size() > l.max() {
+			return l.splitOversized(filename, info)
+		}
+		return l.rotate(RotationSize)
 	}
 
 	file, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0644)
 	if err != nil {
 		// if we fail to open the old log file for some reason, just ignore
 		// it and open a new log file.
-		return l.openNew()
+		return l.openNew(RotationSize)
 	}
 	l.file = file
 	l.size = info.Size()
+	l.firstWrite = time.Time{}
+	l.lastWrite = info.ModTime()
+	l.startWatch(filename)
+	l.schedule(false)
 	return nil
 }
 
 // filename generates the name of the logfile from the current time.
 func (l *Logger) filename() string {
 	if l.Filename != "" {
-		return l.Filename
+		return fixPath(l.Filename)
 	}
 	name := filepath.Base(os.Args[0]) + "-lumberjack.log"
 	return filepath.Join(os.TempDir(), name)
 }
 
-// millRunOnce performs compression and removal of stale log files.
-// Log files are compressed if enabled via configuration and old log
-// files are removed, keeping at most l.MaxBackups files, as long as
-// none of them are older than MaxAge.
-func (l *Logger) millRunOnce() error {
-	if l.MaxBackups == 0 && l.MaxAge == 0 && !l.Compress {
-		return nil
+// splitOversized moves an existing log file that is larger than MaxSize aside as
+// a series of backups of at most MaxSize each, and then opens a new log file.
+// The backups are named like any other, so they sort in the order of their
+// content, and each is reported as a rotation of its own.  If the NameCodec
+// can't tell the backups apart, the file is rotated as a single backup instead.
+func (l *Logger) splitOversized(name string, info os.FileInfo) error {
+	if l.SequentialBackups {
+		return l.rotate(RotationSize)
+	}
+	if err := l.checkTimeFormat(); err != nil {
+		return err
+	}
+	codec := l.codec()
+	stamp, _, err := codec.Decode(codec.Encode(l.now(), 0))
+	if err != nil || codec.Encode(stamp, 0) == codec.Encode(stamp, 1) {
+		return l.rotate(RotationSize)
 	}
 
-	files, err := l.oldLogFiles()
+	// all the names are picked before anything is moved, so that a backup
+	// in the way fails the split before it starts.
+	max := l.max()
+	count := int((info.Size() + max - 1) / max)
+	names := make([]string, count)
+	existing := make([]string, count)
+	for i := range names {
+		newname := l.layoutName(l.partitionName(l.backupName(l.LocalTime), RotationSize))
+		if names[i], existing[i], err = l.resolveExisting(newname); err != nil {
+			return err
+		}
+	}
+
+	src, err := os.Open(name)
 	if err != nil {
+		return fmt.Errorf("can't open oversized log file: %s", err)
+	}
+	defer src.Close()
+	for i, chunk := range names {
+		start := time.Now()
+		if err := l.mkdirAll(filepath.Dir(chunk)); err != nil {
+			return fmt.Errorf("can't make directories for backup logfile: %s", err)
+		}
+		if existing[i] == chunk {
+			// ExistingOverwrite: the chunk replaces the backup, as a rename
+			// would.
+			if err := os_Remove(chunk); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("can't remove existing backup: %s", err)
+			}
+		}
+		n, err := copyChunk(src, chunk, max, info)
+		if err != nil {
+			return err
+		}
+		if err := l.chmodBackup(chunk); err != nil {
+			return err
+		}
+		l.queueFinalize(chunk)
+		rotation := RotationInfo{
+			OldPath:   name,
+			NewPath:   chunk,
+			Reason:    RotationSize,
+			Time:      l.now(),
+			Bytes:     n,
+			LastWrite: info.ModTime(),
+			RunID:     l.RunID,
+			Existing:  existing[i],
+		}
+		rotation.BackupDirFree, rotation.BackupDirUsed, _ = statDisk(filepath.Dir(chunk))
+		rotation.Duration = time.Since(start)
+		if err := l.recordRotation(rotation); err != nil {
+			return err
+		}
+	}
+	if err := src.Close(); err != nil {
 		return err
 	}
+	if err := os.Remove(name); err != nil {
+		return fmt.Errorf("can't remove oversized log file: %s", err)
+	}
+	return l.rotate(RotationSize)
+}
 
-	backupDir := l.backupDir()
-	var compress, remove []logInfo
+// copyChunk copies at most size bytes from src into a new file with the given
+// name, using the mode and owner of the original file, and returns the number
+// of bytes copied.  The file must not exist yet.
+func copyChunk(src io.Reader, name string, size int64, info os.FileInfo) (int64, error) {
+	dst, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, info.Mode())
+	if err != nil {
+		return 0, fmt.Errorf("can't create backup logfile: %s", err)
+	}
+	defer dst.Close()
+	// this is a no-op anywhere but linux
+	if err := chown(name, info); err != nil {
+		return 0, err
+	}
+	n, err := io.CopyN(dst, src, size)
+	if err != nil && err != io.EOF {
+		return n, fmt.Errorf("can't split oversized log file: %s", err)
+	}
+	return n, dst.Close()
+}
 
-	if l.MaxBackups > 0 && l.MaxBackups < len(files) {
-		preserved := make(map[string]bool)
-		var remaining []logInfo
-		for _, f := range files {
-			// Only count the uncompressed log file or the
-			// compressed log file, not both.
-			fn := f.Name()
-			if strings.HasSuffix(fn, compressSuffix) {
-				fn = fn[:len(fn)-len(compressSuffix)]
-			}
-			preserved[fn] = true
+// activeFilename returns the name of the file that is actually written to.  If
+// the log file is a symlink, it is resolved so that rotation moves the target
+// aside rather than the link itself.
+func (l *Logger) activeFilename() string {
+	name := l.filename()
+	info, err := os.Lstat(name)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return name
+	}
+	target, err := filepath.EvalSymlinks(name)
+	if err != nil {
+		// dangling links are created through when the file is opened.
+		return name
+	}
+	return target
+}
 
-			if len(preserved) > l.MaxBackups {
-				remove = append(remove, f)
-			} else {
-				remaining = append(remaining, f)
-			}
+// millRunOnce performs compression and removal of stale log files.
+// Log files are compressed if enabled via configuration and old log
+// files are removed, keeping at most l.MaxBackups files, as long as
+// none of them are older than MaxAge, and they fit in MaxTotalSize.
+func (l *Logger) millRunOnce() error {
+	l.configMu.RLock()
+	defer l.configMu.RUnlock()
+	if l.coordinated() && !l.lockSteps() {
+		// renumbering backups in another process moves them under the
+		// cleanup, so the processes using the same Filename take turns.
+		unlock, err := l.lockRotationFile()
+		if err != nil {
+			return err
 		}
-		files = remaining
+		defer unlock()
 	}
-	if l.MaxAge > 0 {
-		diff := time.Duration(int64(24*time.Hour) * int64(l.MaxAge))
-		cutoff := currentTime().Add(-1 * diff)
-
-		var remaining []logInfo
-		for _, f := range files {
-			if f.timestamp.Before(cutoff) {
-				remove = append(remove, f)
-			} else {
-				remaining = append(remaining, f)
+	if l.SequentialBackups {
+		l.seqMu.Lock()
+		defer l.seqMu.Unlock()
+	}
+	var err error
+	if l.DedupBackups && !l.AuditMode {
+		err = l.dedupRotated()
+	}
+	if l.MaxBackups != 0 || l.MaxAge != 0 || l.MaxAgeDuration != 0 || l.Compress || l.MaxManualBackups != 0 || l.CompactBelow != 0 ||
+		l.KeepDaily != 0 || l.KeepWeekly != 0 {
+		for _, p := range l.partitions() {
+			if errMill := l.millPartition(p); err == nil && errMill != nil {
+				err = errMill
 			}
 		}
-		files = remaining
 	}
+	if errSize := l.enforceTotalSize(); err == nil && errSize != nil {
+		err = errSize
+	}
+	if errFinal := l.finalizeRotated(); err == nil && errFinal != nil {
+		err = errFinal
+	}
+	if errPrune := l.pruneArchived(); err == nil && errPrune != nil {
+		err = errPrune
+	}
+	return err
+}
 
-	if l.Compress {
-		for i, f := range files {
-			if shouldCompressFile(l.KeepLastDecompressed, i, f.Name()) {
-				compress = append(compress, f)
+// millPartition performs compression and removal of the stale log files in a
+// single partition of the backups.
+func (l *Logger) millPartition(p partition) error {
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		return err
+	}
+	files, err := l.scanBackups(p.dirs)
+	if err != nil {
+		unlock()
+		return err
+	}
+	if l.CompactBelow > 0 {
+		// compactRun takes the lock to put merged backups in place.
+		unlock()
+		compacted, errCompact := l.compact(files)
+		if unlock, err = l.lockCleanup(); err != nil {
+			return err
+		}
+		if compacted || errCompact != nil || l.lockSteps() {
+			// a failed merge may have merged some of the backups.
+			if files, err = l.scanBackups(p.dirs); err != nil {
+				unlock()
+				return err
 			}
+			err = errCompact
 		}
 	}
 
+	remove, compress := l.retention(p).apply(files, l.now())
+
 	for _, f := range remove {
-		errRemove := os.Remove(filepath.Join(backupDir, f.Name()))
+		errRemove := l.remove(filepath.Join(f.dir, f.Name()))
 		if err == nil && errRemove != nil {
 			err = errRemove
 		}
 	}
+	unlock()
+	if len(compress) == 0 {
+		return err
+	}
+	c, errCodec := l.compressor()
+	if errCodec != nil {
+		if err == nil {
+			err = errCodec
+		}
+		return err
+	}
 	for _, f := range compress {
-		fn := filepath.Join(backupDir, f.Name())
-		errCompress := compressLogFile(fn, fn+compressSuffix)
+		fn := filepath.Join(f.dir, f.Name())
+		if l.isReading(fn) {
+			// it is compressed once it has been read.
+			continue
+		}
+		dst := fn + c.Suffix()
+		start := time.Now()
+		done, errCompress := l.compressBackup(fn, dst, c)
+		if done {
+			l.countCompression(time.Since(start))
+			l.compressed(fn, dst)
+			if l.OnCompress != nil {
+				l.OnCompress(dst)
+			}
+			// a signature or checksum of the uncompressed backup is stale now.
+			_ = os.Remove(fn + signatureSuffix)
+			removeChecksums(fn)
+			errCompress = l.finalize(dst)
+		}
 		if err == nil && errCompress != nil {
 			err = errCompress
 		}
@@ -407,8 +1622,70 @@ func (l *Logger) millRunOnce() error {
 	return err
 }
 
+// compressBackup compresses the backup fn to dst, removing fn, and reports
+// whether it did so.  If lockSteps, other processes may be compressing the same
+// backup, so it is compressed to a file of its own without the lock, which is
+// put in place with the lock held unless another process got there first.
+func (l *Logger) compressBackup(fn, dst string, c Compressor) (bool, error) {
+	if !l.lockSteps() {
+		err := compressLogFile(fn, dst, c, l.CompressMaxLoad, l.BackupFileMode)
+		return err == nil, err
+	}
+	tmp := l.stepName(dst)
+	if err := compressFile(fn, tmp, c, l.CompressMaxLoad, l.BackupFileMode); err != nil {
+		return false, err
+	}
+	unlock, err := l.lockRotationFile()
+	if err != nil {
+		os.Remove(tmp)
+		return false, err
+	}
+	defer unlock()
+	_, errSrc := os.Lstat(fn)
+	if _, errDst := os.Lstat(dst); errSrc != nil || errDst == nil {
+		// another process removed or compressed it meanwhile.
+		os.Remove(tmp)
+		return false, nil
+	}
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return false, fmt.Errorf("failed to rename compressed log file: %v", err)
+	}
+	if err := os.Remove(fn); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// remove removes a backup file along with its metadata, and reports it to
+// OnRemove.
+func (l *Logger) remove(name string) error {
+	if err := l.fsCall(func() error { return removeBackup(name) }); err != nil {
+		return err
+	}
+	l.countRemoved()
+	l.removeLayoutDirs(name)
+	if l.OnRemove != nil {
+		l.OnRemove(name)
+	}
+	return nil
+}
+
+// removeBackup removes a backup file along with its metadata.
+func removeBackup(name string) error {
+	if err := os_Remove(name); err != nil {
+		return err
+	}
+	// metadata and signatures are best effort, most backups won't have any.
+	_ = os.Remove(metadataName(name))
+	_ = os.Remove(name + signatureSuffix)
+	removeChecksums(name)
+	return nil
+}
+
 func shouldCompressFile(keepLastDecompressed int, fileIndex int, filename string) bool {
-	alreadyCompressed := strings.HasSuffix(filename, compressSuffix)
+	// encrypted backups are final, whether they're compressed or not.
+	alreadyCompressed := isCompressed(filename) || isEncrypted(filename)
 	if alreadyCompressed || fileIndex < keepLastDecompressed {
 		return false
 	}
@@ -419,53 +1696,118 @@ func shouldCompressFile(keepLastDecompressed int, fileIndex int, filename string
 // of old log files.
 func (l *Logger) millRun() {
 	for _ = range l.millCh {
-		// what am I going to do, log this?
-		_ = l.millRunOnce()
+		err := l.millRunOnce()
+		if l.manager != nil {
+			if errEnforce := l.manager.Enforce(); err == nil {
+				err = errEnforce
+			}
+		}
+		l.millFinished(err)
 	}
 }
 
 // mill performs post-rotation compression and removal of stale log files,
-// starting the mill goroutine if necessary.
+// starting the mill goroutine if necessary, or leaving it to the Manager's
+// with SharedMill.
 func (l *Logger) mill() {
+	if m := l.manager; m != nil && m.SharedMill {
+		l.millMu.Lock()
+		if !l.millQueued {
+			l.millQueued = true
+			l.millPending++
+		}
+		l.millMu.Unlock()
+		m.mill()
+		return
+	}
 	l.startMill.Do(func() {
 		l.millCh = make(chan bool, 1)
 		go l.millRun()
 	})
+	l.millMu.Lock()
+	defer l.millMu.Unlock()
 	select {
 	case l.millCh <- true:
+		l.millPending++
 	default:
+		// a run is already queued, which will take care of it.
 	}
 }
 
-// oldLogFiles returns the list of backup log files stored in the same
-// directory as the current log file, sorted by ModTime
+// oldLogFiles returns the list of backup log files stored in the backup
+// directories, sorted by the time formatted in their names.
 func (l *Logger) oldLogFiles() ([]logInfo, error) {
-	files, err := ioutil.ReadDir(l.backupDir())
+	var dirs []string
+	for _, p := range l.partitions() {
+		dirs = append(dirs, p.dirs...)
+	}
+	return l.scanBackups(dirs)
+}
+
+// scanBackups returns the list of backup log files stored in the given
+// directories, sorted by the time formatted in their names.
+func (l *Logger) scanBackups(dirs []string) ([]logInfo, error) {
+	logFiles := []logInfo{}
+
+	skip := l.currentFilter()
+	for _, dir := range dirs {
+		var err error
+		if logFiles, err = l.scanDir(dir, l.layoutDepth(), skip, logFiles); err != nil {
+			return nil, err
+		}
+	}
+
+	if l.SequentialBackups {
+		sort.Stable(bySequence(logFiles))
+	} else {
+		sort.Sort(byFormatTime(logFiles))
+	}
+
+	return logFiles, nil
+}
+
+// scanDir appends the backup log files stored in dir to logFiles, and those
+// in its subdirectories down to the given depth, which is that of
+// BackupDirLayout.  Files for which skip, if not nil, returns true are left
+// out.
+func (l *Logger) scanDir(dir string, depth int, skip func(string, os.FileInfo) bool, logFiles []logInfo) ([]logInfo, error) {
+	files, err := l.readDir(dir)
 	if err != nil {
+		// directories that haven't received a backup yet may not exist.
+		if os.IsNotExist(err) {
+			return logFiles, nil
+		}
 		return nil, fmt.Errorf("can't read log file directory: %s", err)
 	}
-	logFiles := []logInfo{}
 
+	codec := l.codec()
 	prefix, ext := l.prefixAndExt()
-
 	for _, f := range files {
 		if f.IsDir() {
+			if depth > 0 {
+				if logFiles, err = l.scanDir(filepath.Join(dir, f.Name()), depth-1, skip, logFiles); err != nil {
+					return nil, err
+				}
+			}
 			continue
 		}
-		if t, err := l.timeFromName(f.Name(), prefix, ext); err == nil {
-			logFiles = append(logFiles, logInfo{t, f})
+		if skip != nil && skip(filepath.Join(dir, f.Name()), f) {
 			continue
 		}
-		if t, err := l.timeFromName(f.Name(), prefix, ext+compressSuffix); err == nil {
-			logFiles = append(logFiles, logInfo{t, f})
-			continue
+		name := trimCompressSuffix(f.Name())
+		if t, seq, err := codec.Decode(name); err == nil {
+			if l.SequentialBackups {
+				t = f.ModTime()
+			}
+			logFiles = append(logFiles, logInfo{t, seq, dir, f})
+		} else if l.UseModTimeFallback && len(name) > len(prefix)+len(ext) &&
+			strings.HasPrefix(name, prefix) && strings.HasSuffix(name, ext) &&
+			!isSidecar(name) {
+			logFiles = append(logFiles, logInfo{f.ModTime(), 0, dir, f})
 		}
-		// error parsing means that the suffix at the end was not generated
-		// by lumberjack, and therefore it's not a backup file.
+		// error parsing means that the name was not generated by
+		// lumberjack, and therefore it's not a backup file.
 	}
-
-	sort.Sort(byFormatTime(logFiles))
-
 	return logFiles, nil
 }
 
@@ -473,14 +1815,8 @@ func (l *Logger) oldLogFiles() ([]logInfo, error) {
 // the filename's prefix and extension. This prevents someone's filename from
 // confusing time.parse.
 func (l *Logger) timeFromName(filename, prefix, ext string) (time.Time, error) {
-	if !strings.HasPrefix(filename, prefix) {
-		return time.Time{}, errors.New("mismatched prefix")
-	}
-	if !strings.HasSuffix(filename, ext) {
-		return time.Time{}, errors.New("mismatched extension")
-	}
-	ts := filename[len(prefix) : len(filename)-len(ext)]
-	return time.Parse(l.timeFormat(), ts)
+	t, _, err := timeFormatCodec{prefix: prefix, ext: ext, format: l.timeFormat()}.Decode(filename)
+	return t, err
 }
 
 // max returns the maximum size in bytes of log files before rolling.
@@ -491,9 +1827,10 @@ func (l *Logger) max() int64 {
 	return int64(l.MaxSize) * int64(megabyte)
 }
 
-// dir returns the directory for the current filename.
+// dir returns the directory for the current filename, following the filename
+// if it is a symlink.
 func (l *Logger) dir() string {
-	return filepath.Dir(l.filename())
+	return filepath.Dir(l.activeFilename())
 }
 
 // prefixAndExt returns the filename part and extension part from the Logger's
@@ -505,9 +1842,24 @@ func (l *Logger) prefixAndExt() (prefix, ext string) {
 	return prefix, ext
 }
 
-// compressLogFile compresses the given log file, removing the
-// uncompressed log file if successful.
-func compressLogFile(src, dst string) (err error) {
+// compressLogFile compresses the given log file with the Compressor, removing
+// the uncompressed log file if successful.  If maxLoad is positive,
+// compression pauses while the system load is above it.  The compressed file
+// gets the given mode, or that of the log file if it is 0.
+func compressLogFile(src, dst string, c Compressor, maxLoad float64, mode os.FileMode) error {
+	if err := compressFile(src, dst, c, maxLoad, mode); err != nil {
+		return err
+	}
+	if err := os.Remove(src); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to compress log file: %v", err)
+	}
+	return nil
+}
+
+// compressFile writes the log file src, compressed with the Compressor, to
+// dst, leaving src alone.
+func compressFile(src, dst string, c Compressor, maxLoad float64, mode os.FileMode) (err error) {
 	f, err := os.Open(src)
 	if err != nil {
 		return fmt.Errorf("failed to open log file: %v", err)
@@ -519,6 +1871,13 @@ func compressLogFile(src, dst string) (err error) {
 		return fmt.Errorf("failed to stat log file: %v", err)
 	}
 
+	var r io.Reader = f
+	if maxLoad > 0 {
+		// don't leave a partial compressed file around while waiting.
+		waitForLoad(maxLoad)
+		r = &loadThrottledReader{r: f, maxLoad: maxLoad, checked: time.Now()}
+	}
+
 	if err := chown(dst, fi); err != nil {
 		return fmt.Errorf("failed to chown compressed log file: %v", err)
 	}
@@ -530,8 +1889,11 @@ func compressLogFile(src, dst string) (err error) {
 		return fmt.Errorf("failed to open compressed log file: %v", err)
 	}
 	defer gzf.Close()
-
-	gz := gzip.NewWriter(gzf)
+	// the umask may have taken permissions away from the compressed file.
+	if err := gzf.Chmod(backupMode(mode, fi)); err != nil {
+		os.Remove(dst)
+		return fmt.Errorf("failed to set mode of compressed log file: %v", err)
+	}
 
 	defer func() {
 		if err != nil {
@@ -540,7 +1902,12 @@ func compressLogFile(src, dst string) (err error) {
 		}
 	}()
 
-	if _, err := io.Copy(gz, f); err != nil {
+	gz, err := c.NewWriter(gzf)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(gz, r); err != nil {
 		return err
 	}
 	if err := gz.Close(); err != nil {
@@ -550,27 +1917,43 @@ func compressLogFile(src, dst string) (err error) {
 		return err
 	}
 
-	if err := f.Close(); err != nil {
-		return err
+	return f.Close()
+}
+
+// isSidecar reports whether name is that of a file kept next to a backup,
+// such as its metadata, signature or checksum, or one being written by the
+// Logger, which a Filename without an extension doesn't tell apart from
+// backups.
+func isSidecar(name string) bool {
+	suffixes := []string{metadataSuffix, signatureSuffix, compactingSuffix, shippedSuffix, lockSuffix, ".tmp"}
+	for _, algorithm := range checksumNames() {
+		suffixes = append(suffixes, "."+algorithm)
 	}
-	if err := os.Remove(src); err != nil {
-		return err
+	for _, suffix := range suffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
 	}
-
-	return nil
+	return false
 }
 
-// logInfo is a convenience struct to return the filename and its embedded
-// timestamp.
+// logInfo is a convenience struct to return the filename, the directory it is
+// stored in and its embedded timestamp and sequence number.
 type logInfo struct {
 	timestamp time.Time
+	seq       int
+	dir       string
 	os.FileInfo
 }
 
-// byFormatTime sorts by newest time formatted in the name.
+// byFormatTime sorts by newest time formatted in the name, and by highest
+// sequence number for equal times.
 type byFormatTime []logInfo
 
 func (b byFormatTime) Less(i, j int) bool {
+	if b[i].timestamp.Equal(b[j].timestamp) {
+		return b[i].seq > b[j].seq
+	}
 	return b[i].timestamp.After(b[j].timestamp)
 }
 
@@ -805,7 +805,65 @@ func TestJson(t *testing.T) {
 	"compress": true,
 	"keeplastdecompressed": 2,
 	"timeformat": "1:2.3",
-	"backupdir": "bar"
+	"backupdir": "bar",
+	"writemetadata": true,
+	"filemode": 420,
+	"useumask": true,
+	"splitoversized": true,
+	"backupdirs": ["a", "b"],
+	"backupshardmode": "hash",
+	"slowwritethreshold": 1000,
+	"writetimeout": 1000,
+	"closeafteridle": 1000,
+	"partitionbackups": true,
+	"maxmanualbackups": 4,
+	"openretrybackoff": 1000,
+	"maxopenretrybackoff": 2000,
+	"compressmaxload": 0.8,
+	"keepunshipped": true,
+	"watchexternalchanges": true,
+	"dirmode": 448,
+	"dirgroup": "adm",
+	"selinuxlabel": "system_u:object_r:var_log_t:s0",
+	"timeprecision": "micro",
+	"deletearchived": true,
+	"keeplocalbackups": 3,
+	"maxinterval": 3600000000000,
+	"linkdir": "pickup",
+	"rotateat": "06:30",
+	"compressioncodec": "zstd",
+	"maxtotalsize": 500,
+	"buffersize": 65536,
+	"runid": "deploy-42",
+	"sequentialbackups": true,
+	"sharedfile": true,
+	"archivetimeout": 5000000000,
+	"filesystemtimeout": 5000000000,
+	"checksum": "sha256",
+	"restartmarker": true,
+	"dropjournal": "/var/log/drops.jsonl",
+	"oversizewrites": "split",
+	"rotateonlineboundary": true,
+	"encryption": "aes",
+	"writechecksums": true,
+	"compactbelow": 2,
+	"dedupbackups": true,
+	"keepdaily": 30,
+	"keepweekly": 8,
+	"keepallfor": 86400000000000,
+	"existingbackups": "rename",
+	"minfreespace": 50,
+	"minfreepercent": 5.5,
+	"rejectlowspacewrites": true,
+	"maxbytespersecond": 1048576,
+	"ratelimitpolicy": "drop",
+	"auditmode": true,
+	"backupdirlayout": "2006/01/02",
+	"symlinkcurrent": true,
+	"syncpolicy": "interval",
+	"syncinterval": 5000000000,
+	"maxageduration": 21600000000000,
+	"usemodtimefallback": true
 }`[1:])
 
 	l := Logger{}
@@ -820,6 +878,64 @@ func TestJson(t *testing.T) {
 	equals(2, l.KeepLastDecompressed, t)
 	equals("1:2.3", l.TimeFormat, t)
 	equals("bar", l.BackupDir, t)
+	equals(true, l.WriteMetadata, t)
+	equals(os.FileMode(0644), l.FileMode, t)
+	equals(true, l.UseUmask, t)
+	equals(true, l.SplitOversized, t)
+	equals([]string{"a", "b"}, l.BackupDirs, t)
+	equals("hash", l.BackupShardMode, t)
+	equals(time.Duration(1000), l.SlowWriteThreshold, t)
+	equals(time.Duration(1000), l.WriteTimeout, t)
+	equals(time.Duration(1000), l.CloseAfterIdle, t)
+	equals(true, l.PartitionBackups, t)
+	equals(4, l.MaxManualBackups, t)
+	equals(time.Duration(1000), l.OpenRetryBackoff, t)
+	equals(time.Duration(2000), l.MaxOpenRetryBackoff, t)
+	equals(0.8, l.CompressMaxLoad, t)
+	equals(true, l.KeepUnshipped, t)
+	equals(true, l.WatchExternalChanges, t)
+	equals(os.FileMode(0700), l.DirMode, t)
+	equals("adm", l.DirGroup, t)
+	equals("system_u:object_r:var_log_t:s0", l.SELinuxLabel, t)
+	equals(TimePrecisionMicro, l.TimePrecision, t)
+	equals(true, l.DeleteArchived, t)
+	equals(3, l.KeepLocalBackups, t)
+	equals(time.Hour, l.MaxInterval, t)
+	equals("pickup", l.LinkDir, t)
+	equals("06:30", l.RotateAt, t)
+	equals("zstd", l.CompressionCodec, t)
+	equals(500, l.MaxTotalSize, t)
+	equals(65536, l.BufferSize, t)
+	equals("deploy-42", l.RunID, t)
+	equals(true, l.SequentialBackups, t)
+	equals(true, l.SharedFile, t)
+	equals(5*time.Second, l.ArchiveTimeout, t)
+	equals(5*time.Second, l.FilesystemTimeout, t)
+	equals("sha256", l.Checksum, t)
+	equals(true, l.RestartMarker, t)
+	equals("/var/log/drops.jsonl", l.DropJournal, t)
+	equals(OversizeSplit, l.OversizeWrites, t)
+	equals(true, l.RotateOnLineBoundary, t)
+	equals("aes", l.Encryption, t)
+	equals(true, l.WriteChecksums, t)
+	equals(2, l.CompactBelow, t)
+	equals(true, l.DedupBackups, t)
+	equals(30, l.KeepDaily, t)
+	equals(8, l.KeepWeekly, t)
+	equals(24*time.Hour, l.KeepAllFor, t)
+	equals(ExistingRename, l.ExistingBackups, t)
+	equals(50, l.MinFreeSpace, t)
+	equals(5.5, l.MinFreePercent, t)
+	equals(true, l.RejectLowSpaceWrites, t)
+	equals(1048576, l.MaxBytesPerSecond, t)
+	equals(RateLimitDrop, l.RateLimitPolicy, t)
+	equals(true, l.AuditMode, t)
+	equals("2006/01/02", l.BackupDirLayout, t)
+	equals(true, l.SymlinkCurrent, t)
+	equals(SyncPeriodic, l.SyncPolicy, t)
+	equals(5 * time.Second, l.SyncInterval, t)
+	equals(6*time.Hour, l.MaxAgeDuration, t)
+	equals(true, l.UseModTimeFallback, t)
 }
 
 func TestYaml(t *testing.T) {
@@ -832,7 +948,65 @@ localtime: true
 compress: true
 keeplastdecompressed: 2
 timeformat: 1:2.3
-backupdir: bar`[1:])
+backupdir: bar
+writemetadata: true
+filemode: 0644
+useumask: true
+splitoversized: true
+backupdirs: [a, b]
+backupshardmode: hash
+slowwritethreshold: 1000
+writetimeout: 1000
+closeafteridle: 1000
+partitionbackups: true
+maxmanualbackups: 4
+openretrybackoff: 1000
+maxopenretrybackoff: 2000
+compressmaxload: 0.8
+keepunshipped: true
+watchexternalchanges: true
+dirmode: 0700
+dirgroup: adm
+selinuxlabel: "system_u:object_r:var_log_t:s0"
+timeprecision: micro
+deletearchived: true
+keeplocalbackups: 3
+maxinterval: 1h
+linkdir: pickup
+rotateat: "06:30"
+compressioncodec: zstd
+maxtotalsize: 500
+buffersize: 65536
+runid: "deploy-42"
+sequentialbackups: true
+sharedfile: true
+archivetimeout: 5s
+filesystemtimeout: 5s
+checksum: sha256
+restartmarker: true
+dropjournal: /var/log/drops.jsonl
+oversizewrites: split
+rotateonlineboundary: true
+encryption: aes
+writechecksums: true
+compactbelow: 2
+dedupbackups: true
+keepdaily: 30
+keepweekly: 8
+keepallfor: 24h
+existingbackups: rename
+minfreespace: 50
+minfreepercent: 5.5
+rejectlowspacewrites: true
+maxbytespersecond: 1048576
+ratelimitpolicy: drop
+auditmode: true
+backupdirlayout: "2006/01/02"
+symlinkcurrent: true
+syncpolicy: interval
+syncinterval: 5s
+maxageduration: 6h
+usemodtimefallback: true`[1:])
 
 	l := Logger{}
 	err := yaml.Unmarshal(data, &l)
@@ -846,6 +1020,64 @@ backupdir: bar`[1:])
 	equals(2, l.KeepLastDecompressed, t)
 	equals("1:2.3", l.TimeFormat, t)
 	equals("bar", l.BackupDir, t)
+	equals(true, l.WriteMetadata, t)
+	equals(os.FileMode(0644), l.FileMode, t)
+	equals(true, l.UseUmask, t)
+	equals(true, l.SplitOversized, t)
+	equals([]string{"a", "b"}, l.BackupDirs, t)
+	equals("hash", l.BackupShardMode, t)
+	equals(time.Duration(1000), l.SlowWriteThreshold, t)
+	equals(time.Duration(1000), l.WriteTimeout, t)
+	equals(time.Duration(1000), l.CloseAfterIdle, t)
+	equals(true, l.PartitionBackups, t)
+	equals(4, l.MaxManualBackups, t)
+	equals(time.Duration(1000), l.OpenRetryBackoff, t)
+	equals(time.Duration(2000), l.MaxOpenRetryBackoff, t)
+	equals(0.8, l.CompressMaxLoad, t)
+	equals(true, l.KeepUnshipped, t)
+	equals(true, l.WatchExternalChanges, t)
+	equals(os.FileMode(0700), l.DirMode, t)
+	equals("adm", l.DirGroup, t)
+	equals("system_u:object_r:var_log_t:s0", l.SELinuxLabel, t)
+	equals(TimePrecisionMicro, l.TimePrecision, t)
+	equals(true, l.DeleteArchived, t)
+	equals(3, l.KeepLocalBackups, t)
+	equals(time.Hour, l.MaxInterval, t)
+	equals("pickup", l.LinkDir, t)
+	equals("06:30", l.RotateAt, t)
+	equals("zstd", l.CompressionCodec, t)
+	equals(500, l.MaxTotalSize, t)
+	equals(65536, l.BufferSize, t)
+	equals("deploy-42", l.RunID, t)
+	equals(true, l.SequentialBackups, t)
+	equals(true, l.SharedFile, t)
+	equals(5*time.Second, l.ArchiveTimeout, t)
+	equals(5*time.Second, l.FilesystemTimeout, t)
+	equals("sha256", l.Checksum, t)
+	equals(true, l.RestartMarker, t)
+	equals("/var/log/drops.jsonl", l.DropJournal, t)
+	equals(OversizeSplit, l.OversizeWrites, t)
+	equals(true, l.RotateOnLineBoundary, t)
+	equals("aes", l.Encryption, t)
+	equals(true, l.WriteChecksums, t)
+	equals(2, l.CompactBelow, t)
+	equals(true, l.DedupBackups, t)
+	equals(30, l.KeepDaily, t)
+	equals(8, l.KeepWeekly, t)
+	equals(24*time.Hour, l.KeepAllFor, t)
+	equals(ExistingRename, l.ExistingBackups, t)
+	equals(50, l.MinFreeSpace, t)
+	equals(5.5, l.MinFreePercent, t)
+	equals(true, l.RejectLowSpaceWrites, t)
+	equals(1048576, l.MaxBytesPerSecond, t)
+	equals(RateLimitDrop, l.RateLimitPolicy, t)
+	equals(true, l.AuditMode, t)
+	equals("2006/01/02", l.BackupDirLayout, t)
+	equals(true, l.SymlinkCurrent, t)
+	equals(SyncPeriodic, l.SyncPolicy, t)
+	equals(5 * time.Second, l.SyncInterval, t)
+	equals(6*time.Hour, l.MaxAgeDuration, t)
+	equals(true, l.UseModTimeFallback, t)
 }
 
 func TestToml(t *testing.T) {
@@ -858,7 +1090,65 @@ localtime = true
 compress = true
 keeplastdecompressed = 2
 timeformat = "1:2.3"
-backupdir = "bar"`[1:]
+backupdir = "bar"
+writemetadata = true
+filemode = 420
+useumask = true
+splitoversized = true
+backupdirs = ["a", "b"]
+backupshardmode = "hash"
+slowwritethreshold = 1000
+writetimeout = 1000
+closeafteridle = 1000
+partitionbackups = true
+maxmanualbackups = 4
+openretrybackoff = 1000
+maxopenretrybackoff = 2000
+compressmaxload = 0.8
+keepunshipped = true
+watchexternalchanges = true
+dirmode = 448
+dirgroup = "adm"
+selinuxlabel = "system_u:object_r:var_log_t:s0"
+timeprecision = "micro"
+deletearchived = true
+keeplocalbackups = 3
+maxinterval = 3600000000000
+linkdir = "pickup"
+rotateat = "06:30"
+compressioncodec = "zstd"
+maxtotalsize = 500
+buffersize = 65536
+runid = "deploy-42"
+sequentialbackups = true
+sharedfile = true
+archivetimeout = 5000000000
+filesystemtimeout = 5000000000
+checksum = "sha256"
+restartmarker = true
+dropjournal = "/var/log/drops.jsonl"
+oversizewrites = "split"
+rotateonlineboundary = true
+encryption = "aes"
+writechecksums = true
+compactbelow = 2
+dedupbackups = true
+keepdaily = 30
+keepweekly = 8
+keepallfor = 86400000000000
+existingbackups = "rename"
+minfreespace = 50
+minfreepercent = 5.5
+rejectlowspacewrites = true
+maxbytespersecond = 1048576
+ratelimitpolicy = "drop"
+auditmode = true
+backupdirlayout = "2006/01/02"
+symlinkcurrent = true
+syncpolicy = "interval"
+syncinterval = 5000000000
+maxageduration = 21600000000000
+usemodtimefallback = true`[1:]
 
 	l := Logger{}
 	md, err := toml.Decode(data, &l)
@@ -872,6 +1162,64 @@ backupdir = "bar"`[1:]
 	equals(2, l.KeepLastDecompressed, t)
 	equals("1:2.3", l.TimeFormat, t)
 	equals("bar", l.BackupDir, t)
+	equals(true, l.WriteMetadata, t)
+	equals(os.FileMode(0644), l.FileMode, t)
+	equals(true, l.UseUmask, t)
+	equals(true, l.SplitOversized, t)
+	equals([]string{"a", "b"}, l.BackupDirs, t)
+	equals("hash", l.BackupShardMode, t)
+	equals(time.Duration(1000), l.SlowWriteThreshold, t)
+	equals(time.Duration(1000), l.WriteTimeout, t)
+	equals(time.Duration(1000), l.CloseAfterIdle, t)
+	equals(true, l.PartitionBackups, t)
+	equals(4, l.MaxManualBackups, t)
+	equals(time.Duration(1000), l.OpenRetryBackoff, t)
+	equals(time.Duration(2000), l.MaxOpenRetryBackoff, t)
+	equals(0.8, l.CompressMaxLoad, t)
+	equals(true, l.KeepUnshipped, t)
+	equals(true, l.WatchExternalChanges, t)
+	equals(os.FileMode(0700), l.DirMode, t)
+	equals("adm", l.DirGroup, t)
+	equals("system_u:object_r:var_log_t:s0", l.SELinuxLabel, t)
+	equals(TimePrecisionMicro, l.TimePrecision, t)
+	equals(true, l.DeleteArchived, t)
+	equals(3, l.KeepLocalBackups, t)
+	equals(time.Hour, l.MaxInterval, t)
+	equals("pickup", l.LinkDir, t)
+	equals("06:30", l.RotateAt, t)
+	equals("zstd", l.CompressionCodec, t)
+	equals(500, l.MaxTotalSize, t)
+	equals(65536, l.BufferSize, t)
+	equals("deploy-42", l.RunID, t)
+	equals(true, l.SequentialBackups, t)
+	equals(true, l.SharedFile, t)
+	equals(5*time.Second, l.ArchiveTimeout, t)
+	equals(5*time.Second, l.FilesystemTimeout, t)
+	equals("sha256", l.Checksum, t)
+	equals(true, l.RestartMarker, t)
+	equals("/var/log/drops.jsonl", l.DropJournal, t)
+	equals(OversizeSplit, l.OversizeWrites, t)
+	equals(true, l.RotateOnLineBoundary, t)
+	equals("aes", l.Encryption, t)
+	equals(true, l.WriteChecksums, t)
+	equals(2, l.CompactBelow, t)
+	equals(true, l.DedupBackups, t)
+	equals(30, l.KeepDaily, t)
+	equals(8, l.KeepWeekly, t)
+	equals(24*time.Hour, l.KeepAllFor, t)
+	equals(ExistingRename, l.ExistingBackups, t)
+	equals(50, l.MinFreeSpace, t)
+	equals(5.5, l.MinFreePercent, t)
+	equals(true, l.RejectLowSpaceWrites, t)
+	equals(1048576, l.MaxBytesPerSecond, t)
+	equals(RateLimitDrop, l.RateLimitPolicy, t)
+	equals(true, l.AuditMode, t)
+	equals("2006/01/02", l.BackupDirLayout, t)
+	equals(true, l.SymlinkCurrent, t)
+	equals(SyncPeriodic, l.SyncPolicy, t)
+	equals(5 * time.Second, l.SyncInterval, t)
+	equals(6*time.Hour, l.MaxAgeDuration, t)
+	equals(true, l.UseModTimeFallback, t)
 	equals(0, len(md.Undecoded()), t)
 }
 
@@ -1007,12 +1355,17 @@ func backupFileWithTime(dir string, currTime time.Time, opts ...backupFileOpt) s
 	if !options.local {
 		currTime = currTime.UTC()
 	}
-	return filepath.Join(dir, "foobar-"+currTime.Format(options.timeFormat)+".log")
+	timestamp := currTime.Format(options.timeFormat)
+	if options.seq > 0 {
+		timestamp += fmt.Sprintf("-%03d", options.seq)
+	}
+	return filepath.Join(dir, "foobar-"+timestamp+".log")
 }
 
 type backupFileOpts struct {
 	local      bool
 	timeFormat string
+	seq        int
 }
 
 type backupFileOpt func(opts *backupFileOpts)
@@ -1029,6 +1382,12 @@ func withTimeFormat(format string) backupFileOpt {
 	}
 }
 
+func withSequence(seq int) backupFileOpt {
+	return func(opts *backupFileOpts) {
+		opts.seq = seq
+	}
+}
+
 // fileCount checks that the number of files in the directory is exp.
 func fileCount(dir string, exp int, t testing.TB) {
 	files, err := ioutil.ReadDir(dir)
@@ -1051,3 +1410,185 @@ func exists(path string, t testing.TB) {
 	_, err := os.Stat(path)
 	assertUp(err == nil, t, 1, "expected file to exist, but got error from os.Stat: %v", err)
 }
+
+func TestSplitOversized(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSplitOversized", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	start := []byte("0123456789abcdefghijKLMNO")
+	err := ioutil.WriteFile(filename, start, 0644)
+	isNil(err, t)
+
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		SplitOversized: true,
+	}
+	defer l.Close()
+
+	newFakeTime()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	existsWithContent(filename, b, t)
+	existsWithContent(backupFile(dir), start[:10], t)
+	existsWithContent(backupFile(dir, withSequence(1)), start[10:20], t)
+	existsWithContent(backupFile(dir, withSequence(2)), start[20:], t)
+	fileCount(dir, 4, t)
+}
+
+func TestSplitOversizedExisting(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSplitOversizedExisting", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	start := []byte("0123456789abcdefghij")
+	err := ioutil.WriteFile(filename, start, 0644)
+	isNil(err, t)
+
+	newFakeTime()
+	old := []byte("old!")
+	err = ioutil.WriteFile(backupFile(dir), old, 0644)
+	isNil(err, t)
+
+	var rotations []RotationInfo
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		SplitOversized: true,
+		OnRotate: func(info RotationInfo) {
+			rotations = append(rotations, info)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	// the backup already there is left alone.
+	existsWithContent(backupFile(dir), old, t)
+	existsWithContent(backupFile(dir, withSequence(1)), start[:10], t)
+	existsWithContent(backupFile(dir, withSequence(2)), start[10:], t)
+	existsWithContent(filename, b, t)
+	fileCount(dir, 4, t)
+
+	equals(2, len(rotations), t)
+	equals(backupFile(dir, withSequence(1)), rotations[0].NewPath, t)
+	equals(int64(10), rotations[0].Bytes, t)
+	equals(backupFile(dir, withSequence(2)), rotations[1].NewPath, t)
+	equals(RotationSize, rotations[1].Reason, t)
+	equals(int64(2), l.Stats().Rotations, t)
+}
+
+func TestBackupDirsRoundRobin(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestBackupDirsRoundRobin", t)
+	defer os.RemoveAll(dir)
+	shard1 := filepath.Join(dir, "shard1")
+	shard2 := filepath.Join(dir, "shard2")
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		MaxBackups: 2,
+		BackupDirs: []string{shard1, shard2},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(shard1)
+	existsWithContent(first, b, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(shard2), []byte{}, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(shard1), []byte{}, t)
+
+	// we need to wait a little bit since the files get deleted on a different
+	// goroutine.
+	<-time.After(10 * time.Millisecond)
+
+	// the oldest backup should have been removed, even though it shares a
+	// directory with the newest one.
+	notExist(first, t)
+	fileCount(shard1, 1, t)
+	fileCount(shard2, 1, t)
+}
+
+func TestBackupDirsHash(t *testing.T) {
+	l := &Logger{
+		BackupDirs:      []string{"a", "b", "c"},
+		BackupShardMode: "hash",
+	}
+	// the same timestamp always lands in the same directory.
+	equals(l.shardDir("2014-05-04T14-44-33.555"), l.shardDir("2014-05-04T14-44-33.555"), t)
+	equals(0, l.shard, t)
+}
+
+func TestOpen(t *testing.T) {
+	currentTime = fakeTime
+	dir := makeTempDir("TestOpen", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+	}
+	defer l.Close()
+	err := l.Open()
+	isNil(err, t)
+	existsWithContent(filename, []byte{}, t)
+
+	// opening again is a no-op.
+	err = l.Open()
+	isNil(err, t)
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	existsWithContent(filename, b, t)
+	fileCount(dir, 1, t)
+}
+
+func TestOpenBadPath(t *testing.T) {
+	currentTime = fakeTime
+	dir := makeTempDir("TestOpenBadPath", t)
+	defer os.RemoveAll(dir)
+
+	// the log file's directory is a file, so it can't be created.
+	notADir := filepath.Join(dir, "notadir")
+	err := ioutil.WriteFile(notADir, []byte("data"), 0644)
+	isNil(err, t)
+
+	l := &Logger{
+		Filename: logFile(notADir),
+	}
+	defer l.Close()
+	err = l.Open()
+	notNil(err, t)
+}
@@ -0,0 +1,105 @@
+package lumberjacktest
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock that only moves when told to, for deterministic tests
+// of time-driven behavior such as MaxInterval, RotateAt and CloseAfterIdle.
+// Set it as the Clock of a lumberjack.Logger:
+//
+//	clock := lumberjacktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+//	l := &lumberjack.Logger{Filename: filename, MaxInterval: time.Hour, Clock: clock}
+//	l.Write([]byte("foo"))
+//	clock.Advance(time.Hour) // rotates the log file
+//
+// It is safe for concurrent use.
+type FakeClock struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*fakeTimer
+}
+
+// fakeTimer is a function waiting for a FakeClock to reach a time.
+type fakeTimer struct {
+	when time.Time
+	f    func()
+}
+
+// NewFakeClock returns a FakeClock set to now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the time the clock is set to.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// AfterFunc arranges for f to be called once the clock has been advanced by d.
+// The returned stop function cancels the call, and reports whether it did so
+// before f was called.
+func (c *FakeClock) AfterFunc(d time.Duration, f func()) (stop func() bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	t := &fakeTimer{when: c.now.Add(d), f: f}
+	c.timers = append(c.timers, t)
+	return func() bool {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		for i, pending := range c.timers {
+			if pending == t {
+				c.timers = append(c.timers[:i], c.timers[i+1:]...)
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Advance moves the clock forward by d.  Functions that become due are called
+// in the order of their due times, with the clock set to that time, before
+// Advance returns.  Functions that they arrange to be called are called too
+// if they become due by the end of d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	end := c.now.Add(d)
+	c.mu.Unlock()
+	c.Set(end)
+}
+
+// Set sets the clock to t, calling the functions that become due like
+// Advance.  If t is before the time the clock is set to, the clock goes back,
+// like a system clock corrected by NTP.
+func (c *FakeClock) Set(t time.Time) {
+	for {
+		c.mu.Lock()
+		sort.SliceStable(c.timers, func(i, j int) bool {
+			return c.timers[i].when.Before(c.timers[j].when)
+		})
+		if len(c.timers) == 0 || c.timers[0].when.After(t) {
+			c.now = t
+			c.mu.Unlock()
+			return
+		}
+		next := c.timers[0]
+		c.timers = c.timers[1:]
+		if next.when.After(c.now) {
+			c.now = next.when
+		}
+		c.mu.Unlock()
+		// called without the lock held, so that f can use the clock.
+		next.f()
+	}
+}
+
+// Pending returns the number of functions waiting to be called.
+func (c *FakeClock) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.timers)
+}
@@ -0,0 +1,47 @@
+package lumberjacktest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := NewFakeClock(start)
+
+	var fired []time.Duration
+	record := func() { fired = append(fired, c.Now().Sub(start)) }
+	c.AfterFunc(2*time.Second, record)
+	c.AfterFunc(time.Second, func() {
+		record()
+		// functions that become due during Advance are called too.
+		c.AfterFunc(time.Second, record)
+	})
+	stop := c.AfterFunc(time.Second, record)
+	if !stop() {
+		t.Fatal("expected stop to cancel the call")
+	}
+	if c.Pending() != 2 {
+		t.Fatalf("expected 2 pending calls, got %d", c.Pending())
+	}
+
+	c.Advance(1500 * time.Millisecond)
+	if exp := []time.Duration{time.Second}; !reflect.DeepEqual(exp, fired) {
+		t.Fatalf("expected calls at %v, got %v", exp, fired)
+	}
+	if now := c.Now(); !now.Equal(start.Add(1500 * time.Millisecond)) {
+		t.Fatalf("expected the clock to be at %v, got %v", start.Add(1500*time.Millisecond), now)
+	}
+
+	c.Advance(time.Minute)
+	if exp := []time.Duration{time.Second, 2 * time.Second, 2 * time.Second}; !reflect.DeepEqual(exp, fired) {
+		t.Fatalf("expected calls at %v, got %v", exp, fired)
+	}
+	if stop() {
+		t.Fatal("expected stop to report the call was already cancelled")
+	}
+	if c.Pending() != 0 {
+		t.Fatalf("expected no pending calls, got %d", c.Pending())
+	}
+}
@@ -0,0 +1,56 @@
+// Package lumberjacktest provides helpers for testing and benchmarking code
+// that uses lumberjack.
+package lumberjacktest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// BenchmarkWrites writes b.N messages of size bytes to w, reporting the
+// throughput and allocations.  Each message ends with a newline, like a log
+// line.
+func BenchmarkWrites(b *testing.B, w io.Writer, size int) {
+	msg := bytes.Repeat([]byte("x"), size)
+	if size > 0 {
+		msg[size-1] = '\n'
+	}
+	b.SetBytes(int64(size))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := w.Write(msg); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// Benchmark benchmarks writes of size bytes to the writer returned by
+// newWriter, which is typically a *lumberjack.Logger writing to filename in a
+// new temporary directory.  The writer is closed and the directory removed
+// afterwards.  It is meant for comparing configurations on the hardware they
+// will run on:
+//
+//	func BenchmarkCompressed(b *testing.B) {
+//		lumberjacktest.Benchmark(b, 100, func(filename string) io.WriteCloser {
+//			return &lumberjack.Logger{Filename: filename, MaxSize: 10, Compress: true}
+//		})
+//	}
+func Benchmark(b *testing.B, size int, newWriter func(filename string) io.WriteCloser) {
+	dir, err := ioutil.TempDir("", "lumberjacktest")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	w := newWriter(filepath.Join(dir, "bench.log"))
+	BenchmarkWrites(b, w, size)
+	b.StopTimer()
+	if err := w.Close(); err != nil {
+		b.Fatal(err)
+	}
+}
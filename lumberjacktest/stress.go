@@ -0,0 +1,293 @@
+package lumberjacktest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordPrefix starts the records written by Stress, so they can be told
+// apart from anything else in the log files, such as restart markers.
+const recordPrefix = "lumberjacktest"
+
+// StressLogger is the part of a *lumberjack.Logger that Stress uses.
+type StressLogger interface {
+	io.WriteCloser
+	Rotate() error
+	Mill(ctx context.Context) error
+	OpenBackup(name string) (io.ReadCloser, error)
+}
+
+// StressConfig describes the load Stress puts on a Logger.
+type StressConfig struct {
+	// Writers is the number of goroutines writing at the same time.  The
+	// default is 1.
+	Writers int
+
+	// Writes is the number of records each writer writes.  The default is
+	// 1000.
+	Writes int
+
+	// Size is the size of each record in bytes, newline included.  The
+	// default is 100, and records are never shorter than their identifier.
+	Size int
+
+	// Rate is the number of records each writer writes per second.  The
+	// default (0) is to write as fast as possible.
+	Rate int
+
+	// Rotations is the number of times Rotate is called during the run, at
+	// even intervals.
+	Rotations int
+
+	// Crashes is the number of times the Logger is abandoned during the run,
+	// at even intervals, like the Logger of a process that was killed: it is
+	// neither flushed nor closed, and a new Logger takes over the log file.
+	// Its cleanup of old log files is left to finish first, and timed work,
+	// such as rotations for MaxInterval, should be off, since there is no
+	// stopping it.
+	Crashes int
+}
+
+// StressResult reports what Stress wrote and found.
+type StressResult struct {
+	// Records is the number of records written, and Bytes their size.
+	Records int
+	Bytes   int64
+
+	// Files is the number of files the records were found in, the log file
+	// and backups together.
+	Files int
+
+	// Lost is the number of records written that weren't found, and
+	// Duplicated the number that were found more than once.
+	Lost       int
+	Duplicated int
+
+	// Elapsed is the time it took to write the records.
+	Elapsed time.Duration
+}
+
+// Stress writes records through the Logger returned by newLogger, which is
+// typically a *lumberjack.Logger writing to filename in a new temporary
+// directory, rotating it and simulating crashes along the way as cfg says.
+// It then reads the log file and every backup, and fails the test if any
+// record is lost or duplicated.  It is meant for gaining confidence in a
+// configuration, such as new retention settings, before rolling it out:
+//
+//	func TestStress(t *testing.T) {
+//		lumberjacktest.Stress(t, lumberjacktest.StressConfig{Writers: 8, Rotations: 50},
+//			func(filename string) lumberjacktest.StressLogger {
+//				return &lumberjack.Logger{Filename: filename, MaxSize: 1, Compress: true}
+//			})
+//	}
+//
+// Backups must be kept in the directory of filename or below it, and must
+// all be kept for the duration of the run, since removed backups show up as
+// lost records.  Records written through a Logger that buffers them are lost
+// by a crash, just as they would be in production.
+func Stress(t testing.TB, cfg StressConfig, newLogger func(filename string) StressLogger) StressResult {
+	if cfg.Writers <= 0 {
+		cfg.Writers = 1
+	}
+	if cfg.Writes <= 0 {
+		cfg.Writes = 1000
+	}
+	if cfg.Size <= 0 {
+		cfg.Size = 100
+	}
+
+	dir, err := ioutil.TempDir("", "lumberjacktest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "stress.log")
+
+	s := &stress{cfg: cfg, l: newLogger(filename)}
+	total := int64(cfg.Writers * cfg.Writes)
+	s.rotateEvery = every(total, cfg.Rotations)
+	s.crashEvery = every(total, cfg.Crashes)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for w := 0; w < cfg.Writers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			s.write(t, w, func() StressLogger { return newLogger(filename) })
+		}(w)
+	}
+	wg.Wait()
+	result := StressResult{Elapsed: time.Since(start)}
+
+	if err := s.l.Close(); err != nil {
+		t.Errorf("can't close logger: %s", err)
+	}
+	if err := s.l.Mill(context.Background()); err != nil {
+		t.Errorf("cleanup of old log files failed: %s", err)
+	}
+
+	seen, files := s.read(t, dir, filename)
+	result.Files = files
+	for w := 0; w < cfg.Writers; w++ {
+		for n := 0; n < cfg.Writes; n++ {
+			result.Records++
+			result.Bytes += int64(len(record(w, n, cfg.Size)))
+			switch count := seen[recordID(w, n)]; {
+			case count == 0:
+				result.Lost++
+			case count > 1:
+				result.Duplicated++
+			}
+		}
+	}
+	if result.Lost > 0 || result.Duplicated > 0 {
+		t.Errorf("of %d records, %d were lost and %d duplicated", result.Records, result.Lost, result.Duplicated)
+	}
+	return result
+}
+
+// stress is the state of a run of Stress.
+type stress struct {
+	cfg         StressConfig
+	rotateEvery int64
+	crashEvery  int64
+	written     int64
+
+	// mu is held for reading to use l, and for writing to replace it.
+	// crashed holds the Loggers that were abandoned, so they aren't
+	// garbage collected, which would close their files.
+	mu      sync.RWMutex
+	l       StressLogger
+	crashed []StressLogger
+}
+
+// every returns the number of writes between n events spread evenly over
+// total writes, or 0 if there are none.
+func every(total int64, n int) int64 {
+	if n <= 0 {
+		return 0
+	}
+	if e := total / int64(n+1); e > 0 {
+		return e
+	}
+	return 1
+}
+
+// write writes the records of writer w, pacing them to cfg.Rate, and rotates
+// and crashes the Logger when it is their turn.
+func (s *stress) write(t testing.TB, w int, newLogger func() StressLogger) {
+	var tick <-chan time.Time
+	if s.cfg.Rate > 0 {
+		ticker := time.NewTicker(time.Second / time.Duration(s.cfg.Rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+	for n := 0; n < s.cfg.Writes; n++ {
+		if tick != nil {
+			<-tick
+		}
+		s.mu.RLock()
+		_, err := s.l.Write(record(w, n, s.cfg.Size))
+		if err != nil {
+			t.Errorf("can't write record %s: %s", recordID(w, n), err)
+		}
+		written := atomic.AddInt64(&s.written, 1)
+		if s.rotateEvery > 0 && written%s.rotateEvery == 0 {
+			if err := s.l.Rotate(); err != nil {
+				t.Errorf("can't rotate: %s", err)
+			}
+		}
+		s.mu.RUnlock()
+
+		if s.crashEvery > 0 && written%s.crashEvery == 0 {
+			s.mu.Lock()
+			// a killed process doesn't go on cleaning up, so the cleanup
+			// is done before the next Logger starts its own.
+			if err := s.l.Mill(context.Background()); err != nil {
+				t.Errorf("cleanup of old log files failed: %s", err)
+			}
+			s.crashed = append(s.crashed, s.l)
+			s.l = newLogger()
+			s.mu.Unlock()
+		}
+	}
+}
+
+// read returns the number of times each record appears in the log file and
+// the backups under dir, and the number of files they appear in.
+func (s *stress) read(t testing.TB, dir, filename string) (map[string]int, int) {
+	seen := make(map[string]int)
+	files := 0
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		var r io.ReadCloser
+		if path == filename {
+			r, err = os.Open(path)
+		} else {
+			// anything that isn't a backup, such as metadata, can't be
+			// opened as one.
+			r, err = s.l.OpenBackup(path)
+		}
+		if err != nil {
+			return nil
+		}
+		defer r.Close()
+		found, err := countRecords(r, seen)
+		if err != nil {
+			t.Errorf("can't read %s: %s", path, err)
+		}
+		if found {
+			files++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("can't read log files: %s", err)
+	}
+	return seen, files
+}
+
+// countRecords counts the records read from r in seen, and reports whether
+// there were any.
+func countRecords(r io.Reader, seen map[string]int) (bool, error) {
+	found := false
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 3 || fields[0] != recordPrefix {
+			continue
+		}
+		seen[fields[1]+" "+fields[2]]++
+		found = true
+	}
+	return found, scanner.Err()
+}
+
+// recordID identifies the record n of writer w.
+func recordID(w, n int) string {
+	return fmt.Sprintf("%d %d", w, n)
+}
+
+// record returns the record n of writer w, padded to size bytes.
+func record(w, n, size int) []byte {
+	b := []byte(recordPrefix + " " + recordID(w, n) + " ")
+	if pad := size - len(b) - 1; pad > 0 {
+		b = append(b, bytes.Repeat([]byte("x"), pad)...)
+	}
+	return append(b, '\n')
+}
@@ -0,0 +1,236 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Manager enforces a disk budget shared by several Loggers, such as the
+// per-tenant Loggers of a multi-tenant service, on top of their own
+// retention settings.  When the Loggers use more space than the budget
+// allows, backups are removed from whichever Logger is using the most space,
+// oldest first, so one busy tenant can't push out everyone else's history.
+//
+// The budget is enforced after each Logger's own cleanup of old log files,
+// and whenever Enforce is called.
+type Manager struct {
+	// MaxTotalSize is the maximum size in megabytes of the log files and
+	// backups of all managed Loggers together.  The default (0) is not to
+	// enforce a budget.
+	MaxTotalSize int
+
+	// SharedMill makes the managed Loggers clean up their old log files in a
+	// single goroutine, one after the other, followed by a single enforcement
+	// of MaxTotalSize, rather than each in its own goroutine followed by its
+	// own enforcement, which scans the backups of every managed Logger.  This
+	// saves work when many Loggers rotate at once, and keeps Loggers sharing
+	// a BackupDir from cleaning it up at the same time.  It must be set before
+	// Loggers are added.
+	SharedMill bool
+
+	mu      sync.Mutex
+	loggers []*Logger
+
+	millCh    chan bool
+	startMill sync.Once
+}
+
+// Add puts the Logger under the Manager's budget.  It must be called before
+// the Logger is first written to.
+func (m *Manager) Add(l *Logger) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	l.manager = m
+	m.loggers = append(m.loggers, l)
+}
+
+// Remove takes the Logger out from under the Manager's budget.  The Logger
+// cleans up its old log files by itself from then on.
+func (m *Manager) Remove(l *Logger) {
+	m.mu.Lock()
+	for i, managed := range m.loggers {
+		if managed == l {
+			m.loggers = append(m.loggers[:i], m.loggers[i+1:]...)
+			break
+		}
+	}
+	m.mu.Unlock()
+
+	// hand a cleanup queued with the shared mill back to the Logger.
+	l.millMu.Lock()
+	queued := l.millQueued
+	if queued {
+		l.millQueued = false
+		l.millPending--
+	}
+	l.manager = nil
+	l.millMu.Unlock()
+	if queued {
+		l.mill()
+	}
+}
+
+// tenant is the disk usage of a single managed Logger.
+type tenant struct {
+	l *Logger
+
+	// backups are the backups that may be removed, newest first.
+	backups []logInfo
+	size    int64
+}
+
+// Enforce removes backups until the managed Loggers fit in MaxTotalSize, or
+// there are no backups left to remove.  It holds the locks each Logger's own
+// cleanup takes while it does, so rotations of the managed Loggers wait for
+// it.
+func (m *Manager) Enforce() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.MaxTotalSize <= 0 {
+		return nil
+	}
+
+	var err error
+	var total int64
+	tenants := make([]*tenant, 0, len(m.loggers))
+	held := make(map[string]bool)
+	for _, l := range m.loggers {
+		unlock, errLock := l.lockTenant(held)
+		if errLock != nil {
+			if err == nil {
+				err = errLock
+			}
+			continue
+		}
+		defer unlock()
+		backups, errScan := l.oldLogFiles()
+		if errScan != nil {
+			if err == nil {
+				err = errScan
+			}
+			continue
+		}
+		t := &tenant{l: l}
+		if info, errStat := os.Stat(l.activeFilename()); errStat == nil {
+			t.size += info.Size()
+		}
+		removable := l.removableFilter()
+		for _, f := range backups {
+			t.size += f.Size()
+			if removable == nil || removable(f) {
+				t.backups = append(t.backups, f)
+			}
+		}
+		total += t.size
+		tenants = append(tenants, t)
+	}
+
+	budget := int64(m.MaxTotalSize) * int64(megabyte)
+	for total > budget {
+		var largest *tenant
+		for _, t := range tenants {
+			if len(t.backups) > 0 && (largest == nil || t.size > largest.size) {
+				largest = t
+			}
+		}
+		if largest == nil {
+			break
+		}
+		// backups are sorted newest first.
+		oldest := largest.backups[len(largest.backups)-1]
+		largest.backups = largest.backups[:len(largest.backups)-1]
+		errRemove := largest.l.remove(filepath.Join(oldest.dir, oldest.Name()))
+		if errRemove != nil {
+			if err == nil {
+				err = errRemove
+			}
+			continue
+		}
+		largest.size -= oldest.Size()
+		total -= oldest.Size()
+	}
+	return err
+}
+
+// lockTenant takes the locks that the Logger's own cleanup takes, so its
+// backups aren't changed by its mill, rotations or other processes while the
+// budget is enforced, and returns a func releasing them.  held has the lock
+// files locked by Enforce already, which Loggers with the same Filename
+// share.
+func (l *Logger) lockTenant(held map[string]bool) (func(), error) {
+	l.configMu.RLock()
+	unlockFile := func() {}
+	if name := l.filename() + lockSuffix; l.coordinated() && !held[name] {
+		var err error
+		if unlockFile, err = l.lockRotationFile(); err != nil {
+			l.configMu.RUnlock()
+			return nil, err
+		}
+		held[name] = true
+	}
+	if l.SequentialBackups {
+		l.seqMu.Lock()
+	}
+	return func() {
+		if l.SequentialBackups {
+			l.seqMu.Unlock()
+		}
+		unlockFile()
+		l.configMu.RUnlock()
+	}, nil
+}
+
+// mill runs the shared mill for the Loggers that queued a run, starting its
+// goroutine if necessary.
+func (m *Manager) mill() {
+	m.startMill.Do(func() {
+		m.millCh = make(chan bool, 1)
+		go m.millRun()
+	})
+	select {
+	case m.millCh <- true:
+	default:
+		// a run is already queued, which will take care of it.
+	}
+}
+
+// millRun runs in a goroutine to clean up the old log files of the managed
+// Loggers with SharedMill.
+func (m *Manager) millRun() {
+	for _ = range m.millCh {
+		m.millRunOnce()
+	}
+}
+
+// millRunOnce cleans up the old log files of the Loggers that queued a run,
+// and then enforces MaxTotalSize once for all of them.
+func (m *Manager) millRunOnce() {
+	m.mu.Lock()
+	loggers := append([]*Logger(nil), m.loggers...)
+	m.mu.Unlock()
+
+	var queued []*Logger
+	var errs []error
+	for _, l := range loggers {
+		l.millMu.Lock()
+		ok := l.millQueued
+		l.millQueued = false
+		l.millMu.Unlock()
+		if ok {
+			queued = append(queued, l)
+			errs = append(errs, l.millRunOnce())
+		}
+	}
+	if len(queued) == 0 {
+		return
+	}
+	errEnforce := m.Enforce()
+	for i, l := range queued {
+		err := errs[i]
+		if err == nil {
+			err = errEnforce
+		}
+		l.millFinished(err)
+	}
+}
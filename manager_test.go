@@ -0,0 +1,190 @@
+package lumberjack
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestManagerEnforce(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestManagerEnforce", t)
+	defer os.RemoveAll(dir)
+	busyDir := filepath.Join(dir, "busy")
+	quietDir := filepath.Join(dir, "quiet")
+	isNil(os.Mkdir(busyDir, 0700), t)
+	isNil(os.Mkdir(quietDir, 0700), t)
+
+	// the busy tenant has three 10 byte backups, the quiet one has one 5 byte
+	// backup.
+	data := []byte("0123456789")
+	var busyBackups []string
+	for i := 0; i < 3; i++ {
+		newFakeTime()
+		busyBackups = append(busyBackups, backupFile(busyDir))
+		isNil(ioutil.WriteFile(busyBackups[i], data, 0644), t)
+	}
+	quietBackup := backupFile(quietDir)
+	isNil(ioutil.WriteFile(quietBackup, data[:5], 0644), t)
+
+	m := &Manager{MaxTotalSize: 20}
+	busy := &Logger{Filename: logFile(busyDir)}
+	quiet := &Logger{Filename: logFile(quietDir)}
+	m.Add(busy)
+	m.Add(quiet)
+
+	err := m.Enforce()
+	isNil(err, t)
+
+	// the oldest backups of the busy tenant are removed until everything fits.
+	notExist(busyBackups[0], t)
+	notExist(busyBackups[1], t)
+	exists(busyBackups[2], t)
+	exists(quietBackup, t)
+
+	m.Remove(busy)
+	equals([]*Logger{quiet}, m.loggers, t)
+}
+
+func TestManagerEnforceLocks(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestManagerEnforceLocks", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	m := &Manager{MaxTotalSize: 5}
+	l := &Logger{Filename: filename, SequentialBackups: true}
+	defer l.Close()
+	m.Add(l)
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	backup := filename + ".1"
+	exists(backup, t)
+
+	// the backups aren't removed while a rotation may be renumbering them.
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	l.seqMu.Lock()
+	done := make(chan error, 1)
+	go func() { done <- m.Enforce() }()
+	select {
+	case <-done:
+		l.seqMu.Unlock()
+		t.Fatal("Enforce didn't wait for the backups to be renumbered")
+	case <-time.After(50 * time.Millisecond):
+	}
+	exists(backup, t)
+	l.seqMu.Unlock()
+	isNil(<-done, t)
+	// the backup no longer fits next to the new log file.
+	notExist(backup, t)
+}
+
+func TestSharedBackupDir(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSharedBackupDir", t)
+	defer os.RemoveAll(dir)
+	backupDir := filepath.Join(dir, "backups")
+
+	// the name of one Logger is a prefix of the other's.
+	foo := &Logger{
+		Filename:   filepath.Join(dir, "foo.log"),
+		BackupDir:  backupDir,
+		MaxBackups: 1,
+		Compress:   true,
+	}
+	defer foo.Close()
+	fooBar := &Logger{
+		Filename:   filepath.Join(dir, "foo-bar.log"),
+		BackupDir:  backupDir,
+		MaxBackups: 2,
+	}
+	defer fooBar.Close()
+
+	for i := 0; i < 3; i++ {
+		newFakeTime()
+		for _, l := range []*Logger{foo, fooBar} {
+			_, err := l.Write([]byte("boo!"))
+			isNil(err, t)
+			isNil(l.Rotate(), t)
+		}
+	}
+	isNil(foo.Mill(context.Background()), t)
+	isNil(fooBar.Mill(context.Background()), t)
+
+	// each Logger only cleans up its own backups.
+	fooBackups, err := foo.Backups()
+	isNil(err, t)
+	equals(1, len(fooBackups), t)
+	equals(true, fooBackups[0].Compressed, t)
+	fooBarBackups, err := fooBar.Backups()
+	isNil(err, t)
+	equals(2, len(fooBarBackups), t)
+	equals(false, fooBarBackups[0].Compressed, t)
+	fileCount(backupDir, 3, t)
+}
+
+func TestManagerSharedMill(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestManagerSharedMill", t)
+	defer os.RemoveAll(dir)
+	backupDir := filepath.Join(dir, "backups")
+
+	m := &Manager{MaxTotalSize: 150, SharedMill: true}
+	var loggers []*Logger
+	for _, name := range []string{"foo.log", "bar.log", "baz.log"} {
+		l := &Logger{
+			Filename:  filepath.Join(dir, name),
+			BackupDir: backupDir,
+			Compress:  true,
+		}
+		defer l.Close()
+		m.Add(l)
+		loggers = append(loggers, l)
+	}
+
+	for i := 0; i < 2; i++ {
+		newFakeTime()
+		for _, l := range loggers {
+			_, err := l.Write([]byte("0123456789"))
+			isNil(err, t)
+			isNil(l.Rotate(), t)
+		}
+	}
+	for _, l := range loggers {
+		isNil(l.Mill(context.Background()), t)
+	}
+
+	// all the backups are compressed, and the budget holds.
+	var total int64
+	for _, l := range loggers {
+		backups, err := l.Backups()
+		isNil(err, t)
+		for _, b := range backups {
+			equals(true, b.Compressed, t)
+			total += b.Size
+		}
+	}
+	assert(total > 0 && total <= 150, t, "expected the backups to fit in the budget, got %d bytes", total)
+
+	// a Logger taken out of the Manager cleans up by itself.
+	m.Remove(loggers[0])
+	newFakeTime()
+	isNil(loggers[0].Rotate(), t)
+	isNil(loggers[0].Mill(context.Background()), t)
+	backups, err := loggers[0].Backups(Limit(1))
+	isNil(err, t)
+	equals(true, backups[0].Compressed, t)
+	equals(fakeTime().UTC().Truncate(time.Millisecond), backups[0].Timestamp.UTC(), t)
+}
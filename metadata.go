@@ -0,0 +1,41 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+)
+
+// metadataSuffix is appended to the name of an uncompressed backup to form
+// the name of its metadata file.
+const metadataSuffix = ".meta"
+
+// metadataName returns the name of the metadata file for the given backup.
+// Compressed backups share the metadata file of their uncompressed original.
+func metadataName(backup string) string {
+	return trimCompressSuffix(backup) + metadataSuffix
+}
+
+// writeMetadata writes the metadata file for the given backup, which holds the
+// RotationInfo of the rotation that created it.
+func writeMetadata(backup string, meta RotationInfo) error {
+	b, err := json.Marshal(meta)
+	if err != nil {
+		return fmt.Errorf("can't encode backup metadata: %s", err)
+	}
+	if err := ioutil.WriteFile(metadataName(backup), b, 0644); err != nil {
+		return fmt.Errorf("can't write backup metadata: %s", err)
+	}
+	return nil
+}
+
+// readMetadata reads the metadata file for the given backup.
+func readMetadata(backup string) (RotationInfo, error) {
+	var meta RotationInfo
+	b, err := ioutil.ReadFile(metadataName(backup))
+	if err != nil {
+		return meta, err
+	}
+	err = json.Unmarshal(b, &meta)
+	return meta, err
+}
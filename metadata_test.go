@@ -0,0 +1,56 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriteMetadata(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestWriteMetadata", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       100,
+		WriteMetadata: true,
+	}
+	defer l.Close()
+
+	first := fakeTime()
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	fakeCurrentTime = fakeCurrentTime.Add(time.Minute)
+	last := fakeTime()
+	n, err = l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	gotFirst, gotLast := l.WriteTimes()
+	equals(first, gotFirst, t)
+	equals(last, gotLast, t)
+
+	newFakeTime()
+	err = l.Rotate()
+	isNil(err, t)
+
+	meta, err := readMetadata(backupFile(dir))
+	isNil(err, t)
+	assert(meta.FirstWrite.Equal(first), t, "expected first write %v, got %v", first, meta.FirstWrite)
+	assert(meta.LastWrite.Equal(last), t, "expected last write %v, got %v", last, meta.LastWrite)
+
+	// the new file hasn't been written to yet.
+	gotFirst, gotLast = l.WriteTimes()
+	assert(gotFirst.IsZero(), t, "expected zero first write, got %v", gotFirst)
+	assert(gotLast.IsZero(), t, "expected zero last write, got %v", gotLast)
+
+	// the log file, the backup and its metadata.
+	fileCount(dir, 3, t)
+}
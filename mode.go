@@ -0,0 +1,28 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+)
+
+// backupMode returns the permission bits for a file replacing the backup
+// described by fi, such as its compressed version: mode if it is set, or the
+// permission bits of the backup otherwise.
+func backupMode(mode os.FileMode, fi os.FileInfo) os.FileMode {
+	if mode != 0 {
+		return mode.Perm()
+	}
+	return fi.Mode().Perm()
+}
+
+// chmodBackup gives the newly rotated backup with the given name the mode of
+// BackupFileMode, if it is set.
+func (l *Logger) chmodBackup(name string) error {
+	if l.BackupFileMode == 0 {
+		return nil
+	}
+	if err := os.Chmod(name, l.BackupFileMode.Perm()); err != nil {
+		return fmt.Errorf("can't set mode of backup: %s", err)
+	}
+	return nil
+}
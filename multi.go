@@ -0,0 +1,115 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// MultiLogger writes several named streams, such as "access" and "error", each
+// to a log file of its own, with the settings of Template, and a single
+// goroutine cleaning up the old log files of all of them:
+//
+//	ml := &lumberjack.MultiLogger{
+//		Template: &lumberjack.Logger{Filename: "/var/log/myapp/app.log", MaxSize: 100, Compress: true},
+//	}
+//	access := log.New(ml.Writer("access"), "", log.LstdFlags)
+//	errors := log.New(ml.Writer("error"), "", log.LstdFlags)
+//
+// This saves each Logger's own cleanup goroutine, and the scans of the backups
+// that go with it, which add up when a process writes many log files.
+type MultiLogger struct {
+	// Template holds the settings of the streams.  Each stream is written to
+	// a log file named after it, in the directory of the Template's
+	// Filename, with its extension: with a Filename of
+	// /var/log/myapp/app.log, the "access" stream is written to
+	// /var/log/myapp/access.log.  Template itself is never written to, and
+	// must not be managed by a Manager.  The default is a Logger with the
+	// default settings.
+	Template *Logger
+
+	// MaxTotalSize is the maximum size in megabytes of the log files and
+	// backups of all streams together, as for a Manager.  The default (0) is
+	// not to limit their total size beyond the Template's own settings.
+	MaxTotalSize int
+
+	mu      sync.Mutex
+	manager *Manager
+	streams map[string]*Logger
+}
+
+// Writer returns the Logger of the named stream, creating it if it doesn't
+// exist yet.  The name is the base name of its log file, so it must not
+// contain a path separator.  The Logger is opened on its first Write, like
+// any other.
+func (m *MultiLogger) Writer(name string) *Logger {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if l, ok := m.streams[name]; ok {
+		return l
+	}
+	if m.manager == nil {
+		m.manager = &Manager{MaxTotalSize: m.MaxTotalSize, SharedMill: true}
+		m.streams = make(map[string]*Logger)
+	}
+	template := m.Template
+	if template == nil {
+		template = &Logger{}
+	}
+	l := template.CloneWith(WithFilename(m.filename(template, name)))
+	m.manager.Add(l)
+	m.streams[name] = l
+	return l
+}
+
+// filename returns the name of the log file of the named stream.
+func (m *MultiLogger) filename(template *Logger, name string) string {
+	if template.Filename == "" {
+		return filepath.Join(os.TempDir(), name+".log")
+	}
+	return filepath.Join(filepath.Dir(template.Filename), name+filepath.Ext(template.Filename))
+}
+
+// Streams returns the names of the streams created so far, sorted.
+func (m *MultiLogger) Streams() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.streams))
+	for name := range m.streams {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Rotate rotates the log files of all streams, like Logger.Rotate, and
+// returns the first error.
+func (m *MultiLogger) Rotate() error {
+	return m.each((*Logger).Rotate)
+}
+
+// Close closes the log files of all streams, like Logger.Close, and returns
+// the first error.  The streams can still be written to afterwards, which
+// opens their log files again.
+func (m *MultiLogger) Close() error {
+	return m.each((*Logger).Close)
+}
+
+// each calls f with the Logger of each stream, and returns the first error.
+func (m *MultiLogger) each(f func(*Logger) error) error {
+	m.mu.Lock()
+	loggers := make([]*Logger, 0, len(m.streams))
+	for _, l := range m.streams {
+		loggers = append(loggers, l)
+	}
+	m.mu.Unlock()
+
+	var err error
+	for _, l := range loggers {
+		if errStream := f(l); err == nil {
+			err = errStream
+		}
+	}
+	return err
+}
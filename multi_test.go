@@ -0,0 +1,46 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMultiLogger(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestMultiLogger", t)
+	defer os.RemoveAll(dir)
+
+	ml := &MultiLogger{
+		Template: &Logger{Filename: filepath.Join(dir, "app.log"), MaxSize: 100, MaxBackups: 1},
+	}
+	defer ml.Close()
+
+	access := ml.Writer("access")
+	_, err := access.Write([]byte("GET /\n"))
+	isNil(err, t)
+	_, err = ml.Writer("error").Write([]byte("boom\n"))
+	isNil(err, t)
+	assert(ml.Writer("access") == access, t, "expected the same Logger for the same stream")
+	equals([]string{"access", "error"}, ml.Streams(), t)
+	existsWithContent(filepath.Join(dir, "access.log"), []byte("GET /\n"), t)
+	existsWithContent(filepath.Join(dir, "error.log"), []byte("boom\n"), t)
+	notExist(filepath.Join(dir, "app.log"), t)
+	equals(100, access.MaxSize, t)
+
+	// the old log files of all streams are cleaned up by the shared mill.
+	for i := 0; i < 2; i++ {
+		newFakeTime()
+		isNil(ml.Rotate(), t)
+	}
+	isNil(access.Mill(context.Background()), t)
+	isNil(ml.Writer("error").Mill(context.Background()), t)
+	fileCount(dir, 4, t)
+	for _, l := range []*Logger{access, ml.Writer("error")} {
+		assert(l.millCh == nil, t, "expected no cleanup goroutine of the stream's own")
+	}
+	exists(filepath.Join(dir, "access-"+fakeTime().UTC().Format(DefaultTimeFormat)+".log"), t)
+}
@@ -0,0 +1,11 @@
+// +build !linux
+
+package lumberjack
+
+import (
+	"errors"
+)
+
+func notifyFile(_ string, _ <-chan struct{}, _ func()) error {
+	return errors.New("watching files is not supported")
+}
@@ -0,0 +1,41 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// notifyFile uses inotify to call changed each time the file with the given
+// name is modified, has its attributes changed, or is moved or deleted, until
+// stop is closed.
+func notifyFile(name string, stop <-chan struct{}, changed func()) error {
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("can't initialize inotify: %s", err)
+	}
+	mask := uint32(syscall.IN_MODIFY | syscall.IN_ATTRIB | syscall.IN_MOVE_SELF | syscall.IN_DELETE_SELF)
+	if _, err := syscall.InotifyAddWatch(fd, name, mask); err != nil {
+		syscall.Close(fd)
+		return fmt.Errorf("can't watch log file: %s", err)
+	}
+
+	// the file is non-blocking, so closing it interrupts a pending Read.
+	f := os.NewFile(uintptr(fd), "inotify")
+	go func() {
+		<-stop
+		f.Close()
+	}()
+	go func() {
+		// a single read returns all the pending events, which only need a
+		// single check.
+		buf := make([]byte, 64*(syscall.SizeofInotifyEvent+syscall.NAME_MAX+1))
+		for {
+			if _, err := f.Read(buf); err != nil {
+				return
+			}
+			changed()
+		}
+	}()
+	return nil
+}
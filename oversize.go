@@ -0,0 +1,45 @@
+package lumberjack
+
+// OversizePolicy determines what a Logger does with a write larger than
+// MaxSize.
+type OversizePolicy string
+
+const (
+	// OversizeReject makes the write fail, without writing anything.
+	OversizeReject OversizePolicy = ""
+
+	// OversizeFresh writes the whole write to a log file of its own, which is
+	// larger than MaxSize, and rotated before the next write.
+	OversizeFresh OversizePolicy = "fresh"
+
+	// OversizeSplit splits the write into chunks of MaxSize, each written to
+	// a log file of its own.
+	OversizeSplit OversizePolicy = "split"
+)
+
+// valid reports whether p is one of the known policies.
+func (p OversizePolicy) valid() bool {
+	switch p {
+	case OversizeReject, OversizeFresh, OversizeSplit:
+		return true
+	}
+	return false
+}
+
+// writeSplit writes p, which is larger than MaxSize, to several log files.
+func (l *Logger) writeSplit(p []byte) (int, error) {
+	max := int(l.max())
+	var n int
+	for n < len(p) {
+		end := n + max
+		if end > len(p) {
+			end = len(p)
+		}
+		m, err := l.writeChunk(p[n:end])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
@@ -0,0 +1,124 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+)
+
+func TestOversizeReject(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOversizeReject", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	n, err := l.Write([]byte("0123456789abcdefghijKLMNO"))
+	notNil(err, t)
+	equals(0, n, t)
+	notExist(filename, t)
+}
+
+func TestOversizeFresh(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOversizeFresh", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		OversizeWrites: OversizeFresh,
+	}
+	defer l.Close()
+
+	b := []byte("foo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	big := []byte("0123456789abcdefghijKLMNO")
+	n, err = l.Write(big)
+	isNil(err, t)
+	equals(len(big), n, t)
+	existsWithContent(filename, big, t)
+	existsWithContent(backupFile(dir), b, t)
+
+	// the next write goes to a new log file.
+	b2 := []byte("bar!")
+	n, err = l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+	existsWithContent(filename, b2, t)
+	existsWithContent(backupFile(dir, withSequence(1)), big, t)
+	fileCount(dir, 3, t)
+}
+
+func TestOversizeFreshEmptyFile(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOversizeFreshEmptyFile", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	f, err := os.Create(filename)
+	isNil(err, t)
+	isNil(f.Close(), t)
+
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		OversizeWrites: OversizeFresh,
+	}
+	defer l.Close()
+
+	big := []byte("0123456789abcdefghijKLMNO")
+	n, err := l.Write(big)
+	isNil(err, t)
+	equals(len(big), n, t)
+
+	// the empty log file is written to rather than rotated.
+	existsWithContent(filename, big, t)
+	fileCount(dir, 1, t)
+}
+
+func TestOversizeSplit(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOversizeSplit", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		OversizeWrites: OversizeSplit,
+	}
+	defer l.Close()
+
+	b := []byte("foo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	big := []byte("0123456789abcdefghijKLMNO")
+	n, err = l.Write(big)
+	isNil(err, t)
+	equals(len(big), n, t)
+
+	existsWithContent(filename, big[20:], t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(backupFile(dir, withSequence(1)), big[:10], t)
+	existsWithContent(backupFile(dir, withSequence(2)), big[10:20], t)
+	fileCount(dir, 4, t)
+}
@@ -0,0 +1,53 @@
+package lumberjack
+
+import (
+	"path/filepath"
+)
+
+// Subdirectories of the backup directory used when PartitionBackups is set.
+const (
+	autoPartition   = "auto"
+	manualPartition = "manual"
+)
+
+// partition is a set of backup directories that are cleaned up together.
+type partition struct {
+	dirs       []string
+	maxBackups int
+}
+
+// partitions returns the partitions the backups are split into.  Unless
+// PartitionBackups is set, all backups are in a single partition.
+func (l *Logger) partitions() []partition {
+	dirs := l.backupDirs()
+	if !l.PartitionBackups {
+		return []partition{{dirs: dirs, maxBackups: l.MaxBackups}}
+	}
+	return []partition{
+		{dirs: subdirs(dirs, autoPartition), maxBackups: l.MaxBackups},
+		{dirs: subdirs(dirs, manualPartition), maxBackups: l.MaxManualBackups},
+	}
+}
+
+// partitionName moves the given backup name into the subdirectory of the
+// partition for the rotation reason, if PartitionBackups is set.  Rotations
+// requested through Rotate and Restore go into the manual partition.
+func (l *Logger) partitionName(name string, reason RotationReason) string {
+	if !l.PartitionBackups {
+		return name
+	}
+	sub := autoPartition
+	if reason == RotationManual || reason == RotationRestore {
+		sub = manualPartition
+	}
+	return filepath.Join(filepath.Dir(name), sub, filepath.Base(name))
+}
+
+// subdirs joins each of the directories with the given subdirectory.
+func subdirs(dirs []string, sub string) []string {
+	joined := make([]string, len(dirs))
+	for i, dir := range dirs {
+		joined[i] = filepath.Join(dir, sub)
+	}
+	return joined
+}
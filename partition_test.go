@@ -0,0 +1,62 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestPartitionBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestPartitionBackups", t)
+	defer os.RemoveAll(dir)
+	autoDir := filepath.Join(dir, autoPartition)
+	manualDir := filepath.Join(dir, manualPartition)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:         filename,
+		MaxSize:          10,
+		MaxBackups:       1,
+		MaxManualBackups: 2,
+		PartitionBackups: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	// a manual rotation goes into the manual partition.
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(manualDir), b, t)
+
+	// rotations due to size go into the auto partition.
+	b2 := []byte("foooooo!")
+	for i := 0; i < 3; i++ {
+		newFakeTime()
+		n, err = l.Write(b2)
+		isNil(err, t)
+		equals(len(b2), n, t)
+	}
+	existsWithContent(backupFile(autoDir), b2, t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(manualDir), b2, t)
+
+	// we need to wait a little bit since the files get deleted on a different
+	// goroutine.
+	<-time.After(10 * time.Millisecond)
+
+	// each partition keeps its own number of backups.
+	fileCount(autoDir, 1, t)
+	fileCount(manualDir, 2, t)
+	// the log file and the two partitions.
+	fileCount(dir, 3, t)
+}
@@ -0,0 +1,6 @@
+// +build !linux
+
+package lumberjack
+
+// platformFeatures are the features supported on this platform.
+var platformFeatures []string
@@ -0,0 +1,4 @@
+package lumberjack
+
+// platformFeatures are the features supported on this platform.
+var platformFeatures = []string{"chown", "diskusage", "inotify", "loadavg", "selinux"}
@@ -0,0 +1,87 @@
+package lumberjack
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrRateLimited is returned by Write when RateLimitPolicy is RateLimitError
+// and the write would exceed MaxBytesPerSecond.
+var ErrRateLimited = errors.New("lumberjack: write exceeds MaxBytesPerSecond")
+
+// RateLimitPolicy determines what a Logger does with a write that would
+// exceed MaxBytesPerSecond.
+type RateLimitPolicy string
+
+const (
+	// RateLimitBlock makes the write wait until it is within the rate.
+	RateLimitBlock RateLimitPolicy = ""
+
+	// RateLimitDrop drops the write, which reports success.  Dropped writes
+	// are counted in Stats.RateLimited, and recorded in the DropJournal.
+	RateLimitDrop RateLimitPolicy = "drop"
+
+	// RateLimitError makes the write fail with ErrRateLimited, without
+	// writing anything.
+	RateLimitError RateLimitPolicy = "error"
+)
+
+// valid reports whether p is one of the known policies.
+func (p RateLimitPolicy) valid() bool {
+	switch p {
+	case RateLimitBlock, RateLimitDrop, RateLimitError:
+		return true
+	}
+	return false
+}
+
+// sleep exists so it can be mocked out by tests.
+var sleep = time.Sleep
+
+// rateLimit takes n bytes from the token bucket of MaxBytesPerSecond, which
+// holds up to a second's worth of bytes.  It reports whether the write may go
+// ahead, waiting for the bucket to fill up first with RateLimitBlock.  A write
+// larger than the bucket goes ahead once the bucket is full, and the bytes it
+// overdraws are made up for by the writes after it.
+func (l *Logger) rateLimit(n int) bool {
+	if l.MaxBytesPerSecond <= 0 {
+		return true
+	}
+	rate := float64(l.MaxBytesPerSecond)
+	now := l.now()
+	if l.rateTime.IsZero() {
+		l.rateTokens = rate
+	} else if elapsed := now.Sub(l.rateTime); elapsed > 0 {
+		l.rateTokens += elapsed.Seconds() * rate
+		if l.rateTokens > rate {
+			l.rateTokens = rate
+		}
+	}
+	l.rateTime = now
+
+	need := float64(n)
+	if need > rate {
+		need = rate
+	}
+	if l.rateTokens < need {
+		if l.RateLimitPolicy != RateLimitBlock {
+			return false
+		}
+		sleep(time.Duration((need - l.rateTokens) / rate * float64(time.Second)))
+		l.rateTokens = need
+		l.rateTime = l.now()
+	}
+	l.rateTokens -= float64(n)
+	return true
+}
+
+// rateLimited accounts for a write of n bytes that was dropped or rejected
+// because of MaxBytesPerSecond, and returns what Write returns for it.
+func (l *Logger) rateLimited(n int) (int, error) {
+	l.stats.RateLimited++
+	l.recordDrop(DropRateLimited, n)
+	if l.RateLimitPolicy == RateLimitError {
+		return 0, ErrRateLimited
+	}
+	return n, nil
+}
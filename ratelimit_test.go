@@ -0,0 +1,113 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestRateLimitBlock(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	var slept []time.Duration
+	sleep = func(d time.Duration) {
+		slept = append(slept, d)
+		fakeCurrentTime = fakeCurrentTime.Add(d)
+	}
+	defer func() { sleep = time.Sleep }()
+
+	dir := makeTempDir("TestRateLimitBlock", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		MaxBytesPerSecond: 10,
+	}
+	defer l.Close()
+
+	// a second's worth goes through straight away.
+	b := []byte("0123456789")
+	_, err := l.Write(b)
+	isNil(err, t)
+	equals(0, len(slept), t)
+
+	// then writes wait for the bucket to fill up again.
+	_, err = l.Write(b[:5])
+	isNil(err, t)
+	equals([]time.Duration{500 * time.Millisecond}, slept, t)
+
+	fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	_, err = l.Write(b[:5])
+	isNil(err, t)
+	equals(1, len(slept), t)
+	existsWithContent(filename, []byte("01234567890123401234"), t)
+	equals(int64(0), l.Stats().RateLimited, t)
+}
+
+func TestRateLimitDrop(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRateLimitDrop", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		MaxBytesPerSecond: 10,
+		RateLimitPolicy:   RateLimitDrop,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	for i := 0; i < 4; i++ {
+		n, err := l.Write(b)
+		isNil(err, t)
+		equals(len(b), n, t)
+	}
+	existsWithContent(filename, []byte("boo!boo!"), t)
+	equals(int64(2), l.Stats().RateLimited, t)
+
+	// the bucket refills over time.
+	fakeCurrentTime = fakeCurrentTime.Add(400 * time.Millisecond)
+	_, err := l.Write(b)
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!boo!boo!"), t)
+}
+
+func TestRateLimitError(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRateLimitError", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		MaxBytesPerSecond: 10,
+		RateLimitPolicy:   RateLimitError,
+	}
+	defer l.Close()
+
+	// a write larger than a second's worth goes through on a full bucket,
+	// and the writes after it make up for it.
+	b := []byte("0123456789abcde")
+	_, err := l.Write(b)
+	isNil(err, t)
+	fakeCurrentTime = fakeCurrentTime.Add(500 * time.Millisecond)
+	n, err := l.Write([]byte("boo!"))
+	equals(ErrRateLimited, err, t)
+	equals(0, n, t)
+	equals(int64(1), l.Stats().RateLimited, t)
+
+	fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	_, err = l.Write([]byte("boo!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("0123456789abcdeboo!"), t)
+}
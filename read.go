@@ -0,0 +1,128 @@
+package lumberjack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// OpenBackup opens the named backup for reading, decompressing it if it is
+// compressed.  The name may be the base name of the backup or its full path,
+// e.g. as returned by Backups.  Until the returned reader is closed, the
+// cleanup of old log files neither removes nor compresses the backup, so that
+// long reads such as exports don't fail midway; the cleanup catches up once
+// the last reader of the backup is closed.
+func (l *Logger) OpenBackup(name string) (io.ReadCloser, error) {
+	f, err := l.findBackup(name)
+	if err != nil {
+		return nil, err
+	}
+	backup := filepath.Join(f.dir, f.Name())
+
+	// the backup is marked as being read before it is opened, so that it
+	// can't be cleaned up in between.
+	reading := l.startReading(backup)
+	src, err := os.Open(backup)
+	if err != nil {
+		l.stopReading(reading)
+		return nil, fmt.Errorf("can't open backup: %s", err)
+	}
+	r := &backupReader{Reader: src, f: src, l: l, reading: reading}
+	if r.Reader, err = decryptBackup(backup, src); err != nil {
+		r.Close()
+		return nil, err
+	}
+	if c := compressorFor(trimEncryptSuffix(backup)); c != nil {
+		dec, err := c.NewReader(r.Reader)
+		if err != nil {
+			r.Close()
+			return nil, fmt.Errorf("can't decompress backup: %s", err)
+		}
+		r.Reader, r.dec = dec, dec
+	}
+	return r, nil
+}
+
+// backupReader reads a backup opened with OpenBackup.
+type backupReader struct {
+	io.Reader
+	f       *os.File
+	dec     io.Closer
+	l       *Logger
+	reading *readers
+	once    sync.Once
+}
+
+// Close closes the backup, and lets the cleanup of old log files handle it
+// again.
+func (r *backupReader) Close() error {
+	var err error
+	if r.dec != nil {
+		err = r.dec.Close()
+	}
+	if errClose := r.f.Close(); err == nil {
+		err = errClose
+	}
+	r.once.Do(func() { r.l.stopReading(r.reading) })
+	return err
+}
+
+// readers counts the readers of a backup.  The name changes when backups are
+// renumbered because of SequentialBackups.
+type readers struct {
+	name string
+	n    int
+}
+
+// startReading records that the named backup is being read.
+func (l *Logger) startReading(name string) *readers {
+	l.readingMu.Lock()
+	defer l.readingMu.Unlock()
+	if l.reading == nil {
+		l.reading = make(map[string]*readers)
+	}
+	r := l.reading[name]
+	if r == nil {
+		r = &readers{name: name}
+		l.reading[name] = r
+	}
+	r.n++
+	return r
+}
+
+// stopReading records that a reader of a backup is done.  Once there are no
+// readers left, the mill runs to do the cleanup it left out.
+func (l *Logger) stopReading(r *readers) {
+	l.readingMu.Lock()
+	r.n--
+	done := r.n == 0
+	if done {
+		delete(l.reading, r.name)
+	}
+	l.readingMu.Unlock()
+	if done {
+		l.mill()
+	}
+}
+
+// isReading reports whether the named backup is being read.
+func (l *Logger) isReading(name string) bool {
+	l.readingMu.Lock()
+	defer l.readingMu.Unlock()
+	return l.reading[name] != nil
+}
+
+// readingFilter returns a func reporting whether a backup is not being read,
+// or nil if no backups are being read.
+func (l *Logger) readingFilter() func(logInfo) bool {
+	l.readingMu.Lock()
+	defer l.readingMu.Unlock()
+	if len(l.reading) == 0 {
+		return nil
+	}
+	return func(f logInfo) bool {
+		return !l.isReading(filepath.Join(f.dir, f.Name()))
+	}
+}
@@ -0,0 +1,64 @@
+package lumberjack
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+)
+
+func TestOpenBackupKeepsBackup(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOpenBackupKeepsBackup", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		MaxBackups: 1,
+		Compress:   true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	first := backupFile(dir) + compressSuffix
+	exists(first, t)
+
+	r, err := l.OpenBackup(first)
+	isNil(err, t)
+
+	// the backup being read isn't removed, despite MaxBackups.
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	exists(first, t)
+	fileCount(dir, 3, t)
+
+	b, err := ioutil.ReadAll(r)
+	isNil(err, t)
+	equals("boo!", string(b), t)
+
+	// it is removed once it has been read.
+	isNil(r.Close(), t)
+	isNil(l.Mill(context.Background()), t)
+	notExist(first, t)
+	fileCount(dir, 2, t)
+}
+
+func TestOpenBackupNotFound(t *testing.T) {
+	dir := makeTempDir("TestOpenBackupNotFound", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{Filename: logFile(dir)}
+	defer l.Close()
+
+	_, err := l.OpenBackup("foobar-2020-01-01T00-00-00.000.log")
+	notNil(err, t)
+}
@@ -0,0 +1,163 @@
+package lumberjack
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// readFromBufferSize is the size of the chunks ReadFrom reads.
+const readFromBufferSize = 32 * 1024
+
+// errReadChunks is returned by readDirect when the settings of the Logger need
+// what is read to be written like a call to Write.
+var errReadChunks = errors.New("read in chunks")
+
+var (
+	_ io.ReaderFrom = (*Logger)(nil)
+	_ io.ReaderFrom = (*AsyncLogger)(nil)
+)
+
+// ReadFrom implements io.ReaderFrom, so that io.Copy to the Logger, e.g. from
+// the stdout of a child process, writes what it reads straight to the log
+// file.  Unlike Write, it accepts any amount of data.  It is read into the
+// log file by the file's own ReadFrom, e.g. with splice(2) on Linux, filling
+// each log file up to MaxSize before rotating it, with the Logger locked while
+// reading from r.  With WriteFilter, BufferSize, RotateOnLineBoundary,
+// MaxBytesPerSecond or WriteTimeout set, or a special file such as /dev/stdout
+// for Filename, what is read at once is written like a call to Write instead,
+// rotating the log file as needed, and split if it is larger than MaxSize,
+// after the last line that fits where possible.  The Logger isn't locked
+// while reading from r then.
+func (l *Logger) ReadFrom(r io.Reader) (int64, error) {
+	var n int64
+	for {
+		m, err := l.readDirect(r)
+		n += m
+		switch err {
+		case nil:
+		case io.EOF:
+			return n, nil
+		case errReadChunks:
+			m, err = readChunks(r, l.max, l.writeRead)
+			return n + m, err
+		default:
+			return n, err
+		}
+	}
+}
+
+// readDirect reads from r into the log file with its ReadFrom, up to what
+// fits in it, after opening or rotating it as needed.  It returns io.EOF once
+// r is at EOF, and errReadChunks if the settings of the Logger need what is
+// read to be written like a call to Write.
+func (l *Logger) readDirect(r io.Reader) (int64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.WriteFilter != nil || l.BufferSize > 0 || l.RotateOnLineBoundary ||
+		l.MaxBytesPerSecond > 0 || l.WriteTimeout > 0 {
+		return 0, errReadChunks
+	}
+	// opens the log file, and rotates it if a rotation is due.
+	if _, err := l.write(nil); err != nil {
+		return 0, err
+	}
+	if l.special {
+		return 0, errReadChunks
+	}
+	l.stats.Writes++
+
+	limit := l.max() - l.size
+	if limit <= 0 {
+		// the log file is full, and only rotated if there is more to write.
+		buf := make([]byte, readFromBufferSize)
+		if int64(len(buf)) > l.max() {
+			buf = buf[:l.max()]
+		}
+		m, errRead := r.Read(buf)
+		n, err := l.write(buf[:m])
+		if err == nil {
+			err = errRead
+		}
+		return int64(n), err
+	}
+
+	n, err := l.file.ReadFrom(io.LimitReader(r, limit))
+	l.size += n
+	l.countPhysical(int(n))
+	l.countBytes(int(n))
+	if n > 0 {
+		l.recordWrite()
+		if errSync := l.syncWritten(); err == nil {
+			err = errSync
+		}
+	}
+	l.resetIdle()
+	if err == nil && n < limit {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// writeRead writes what ReadFrom read like a call to Write.
+func (l *Logger) writeRead(p []byte) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats.Writes++
+	var err error
+	if l.BufferSize > 0 {
+		_, err = l.writeFiltered(p, l.writeBuffered)
+	} else {
+		_, err = l.writeFiltered(p, l.write)
+	}
+	return err
+}
+
+// ReadFrom implements io.ReaderFrom like Logger.ReadFrom, going through the
+// buffer like Write.
+func (a *AsyncLogger) ReadFrom(r io.Reader) (int64, error) {
+	return readChunks(r, a.Logger.maxLocked, func(p []byte) error {
+		_, err := a.Write(p)
+		return err
+	})
+}
+
+// maxLocked returns the maximum size of log files like max, with the Logger
+// locked.
+func (l *Logger) maxLocked() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.max()
+}
+
+// readChunks reads r until EOF, and calls write with what each read returns,
+// in chunks no larger than max bytes, cut after the last line that fits if
+// there is one.  It returns the number of bytes written.
+func readChunks(r io.Reader, max func() int64, write func(p []byte) error) (int64, error) {
+	buf := make([]byte, readFromBufferSize)
+	var n int64
+	for {
+		m, errRead := r.Read(buf)
+		p := buf[:m]
+		for len(p) > 0 {
+			chunk := p
+			if limit := max(); int64(len(chunk)) > limit {
+				chunk = chunk[:limit]
+				if i := bytes.LastIndexByte(chunk, '\n'); i >= 0 {
+					chunk = chunk[:i+1]
+				}
+			}
+			if err := write(chunk); err != nil {
+				return n, err
+			}
+			n += int64(len(chunk))
+			p = p[len(chunk):]
+		}
+		if errRead == io.EOF {
+			return n, nil
+		}
+		if errRead != nil {
+			return n, errRead
+		}
+	}
+}
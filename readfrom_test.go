@@ -0,0 +1,116 @@
+package lumberjack
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestReadFrom(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestReadFrom", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	// more than MaxSize at once, which Write would reject.  The reader hides
+	// the WriteTo method of strings.Reader, like a pipe.
+	n, err := io.Copy(l, struct{ io.Reader }{strings.NewReader("boo!\nfoo!\nbar!\nbaz!\n")})
+	isNil(err, t)
+	equals(int64(20), n, t)
+
+	// cut between lines, with a rotation in between.
+	existsWithContent(backupFile(dir), []byte("boo!\nfoo!\n"), t)
+	existsWithContent(filename, []byte("bar!\nbaz!\n"), t)
+	equals(int64(1), l.Stats().Rotations, t)
+}
+
+func TestReadFromLongLine(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestReadFromLongLine", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	// a line that doesn't fit is cut at MaxSize.
+	n, err := l.ReadFrom(iotest.OneByteReader(strings.NewReader("0123456789abc")))
+	isNil(err, t)
+	equals(int64(13), n, t)
+	existsWithContent(backupFile(dir), []byte("0123456789"), t)
+	existsWithContent(filename, []byte("abc"), t)
+
+	n, err = l.ReadFrom(iotest.TimeoutReader(strings.NewReader("de")))
+	equals(iotest.ErrTimeout, err, t)
+	equals(int64(2), n, t)
+	existsWithContent(filename, []byte("abcde"), t)
+}
+
+func TestReadFromLineBoundary(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestReadFromLineBoundary", t)
+	defer os.RemoveAll(dir)
+
+	// each log file is filled up to MaxSize, unless lines are kept whole.
+	for _, whole := range []bool{false, true} {
+		filename := logFile(dir)
+		l := &Logger{
+			Filename:             filename,
+			MaxSize:              12,
+			RotateOnLineBoundary: whole,
+		}
+		n, err := l.ReadFrom(struct{ io.Reader }{strings.NewReader("boo!\nfoo!\nbar!\n")})
+		isNil(err, t)
+		equals(int64(15), n, t)
+		isNil(l.Close(), t)
+
+		if whole {
+			existsWithContent(backupFile(dir), []byte("boo!\nfoo!\n"), t)
+			existsWithContent(filename, []byte("bar!\n"), t)
+		} else {
+			existsWithContent(backupFile(dir), []byte("boo!\nfoo!\nba"), t)
+			existsWithContent(filename, []byte("r!\n"), t)
+		}
+		isNil(os.RemoveAll(dir), t)
+		newFakeTime()
+	}
+}
+
+func TestAsyncReadFrom(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncReadFrom", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  10,
+	}, 0, Block)
+	defer a.Close()
+
+	n, err := io.Copy(a, struct{ io.Reader }{strings.NewReader("boo!\nfoo!\nbar!\n")})
+	isNil(err, t)
+	equals(int64(15), n, t)
+	isNil(a.Flush(), t)
+	existsWithContent(backupFile(dir), []byte("boo!\nfoo!\n"), t)
+	existsWithContent(filename, []byte("bar!\n"), t)
+}
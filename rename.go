@@ -0,0 +1,100 @@
+package lumberjack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"syscall"
+	"time"
+)
+
+// defaultRenameRetryBackoff is the wait before the first retry of a failed
+// rename of the log file if RenameRetryBackoff isn't set.
+const defaultRenameRetryBackoff = 10 * time.Millisecond
+
+// maxRenameRetryWait is the longest that the retries of a failed rename of
+// the log file wait in all, since the Logger is locked meanwhile.
+const maxRenameRetryWait = time.Second
+
+// os_Rename exists so it can be mocked out by tests.
+var os_Rename = os.Rename
+
+// renameLogFile moves the log file aside to become a backup, retrying as
+// configured by RenameRetries while the file is busy, and copying it if
+// RenameFallbackCopy is set and it can't be moved.
+func (l *Logger) renameLogFile(name, newname string) error {
+	wait := l.RenameRetryBackoff
+	if wait <= 0 {
+		wait = defaultRenameRetryBackoff
+	}
+	left := maxRenameRetryWait
+	err := os_Rename(name, newname)
+	for i := 0; err != nil && retryableRename(err) && i < l.RenameRetries && left > 0; i++ {
+		if wait > left {
+			wait = left
+		}
+		sleep(wait)
+		left -= wait
+		wait *= 2
+		err = os_Rename(name, newname)
+	}
+	if err == nil {
+		return nil
+	}
+	if l.RenameFallbackCopy && !os.IsNotExist(err) {
+		overwrite := l.ExistingBackups == ExistingOverwrite
+		if errCopy := copyTruncate(name, newname, overwrite); errCopy != nil {
+			return fmt.Errorf("can't rename log file: %s, or copy it: %s", err, errCopy)
+		}
+		l.stats.RenameCopies++
+		return nil
+	}
+	return fmt.Errorf("can't rename log file: %s", err)
+}
+
+// renameErrno returns the system error that a rename failed with, or 0.
+func renameErrno(err error) syscall.Errno {
+	if e, ok := err.(*os.LinkError); ok {
+		err = e.Err
+	}
+	errno, _ := err.(syscall.Errno)
+	return errno
+}
+
+// copyTruncate copies the file name to newname, and then truncates it, for
+// when it can't be renamed.  newname must not exist, unless overwrite is
+// set.
+func copyTruncate(name, newname string, overwrite bool) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+	flags := os.O_CREATE | os.O_EXCL | os.O_WRONLY
+	if overwrite {
+		flags = os.O_CREATE | os.O_TRUNC | os.O_WRONLY
+	}
+	dst, err := os.OpenFile(newname, flags, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if errClose := dst.Close(); err == nil {
+			err = errClose
+		}
+		if err != nil {
+			_ = os.Remove(newname)
+		}
+	}()
+	if _, err := io.Copy(dst, src); err != nil {
+		return err
+	}
+	if err := dst.Sync(); err != nil {
+		return err
+	}
+	return os.Truncate(name, 0)
+}
@@ -0,0 +1,116 @@
+package lumberjack
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRenameRetries(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	fails := 2
+	os_Rename = func(name, newname string) error {
+		if fails > 0 {
+			fails--
+			return &os.LinkError{Op: "rename", Old: name, New: newname, Err: errRenameBusy}
+		}
+		return os.Rename(name, newname)
+	}
+	var slept []time.Duration
+	sleep = func(d time.Duration) { slept = append(slept, d) }
+	defer func() {
+		os_Rename = os.Rename
+		sleep = time.Sleep
+	}()
+
+	dir := makeTempDir("TestRenameRetries", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       100,
+		RenameRetries: 3,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(filename, []byte{}, t)
+	equals([]time.Duration{10 * time.Millisecond, 20 * time.Millisecond}, slept, t)
+
+	// without enough retries, the rotation fails.
+	fails = 2
+	l.RenameRetries = 1
+	newFakeTime()
+	notNil(l.Rotate(), t)
+	notExist(backupFile(dir), t)
+
+	// errors that another try won't fix aren't retried.
+	os_Rename = func(name, newname string) error {
+		return &os.LinkError{Op: "rename", Old: name, New: newname, Err: syscall.EXDEV}
+	}
+	slept = nil
+	l.RenameRetries = 3
+	newFakeTime()
+	notNil(l.Rotate(), t)
+	equals(0, len(slept), t)
+
+	// nor do the retries wait more than a second in all.
+	os_Rename = func(name, newname string) error {
+		return &os.LinkError{Op: "rename", Old: name, New: newname, Err: errRenameBusy}
+	}
+	l.RenameRetries = 10
+	l.RenameRetryBackoff = 300 * time.Millisecond
+	newFakeTime()
+	notNil(l.Rotate(), t)
+	equals([]time.Duration{300 * time.Millisecond, 600 * time.Millisecond, 100 * time.Millisecond}, slept, t)
+}
+
+func TestRenameFallbackCopy(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	// another backup takes the name meanwhile, which is overwritten as it
+	// would be by renaming.
+	os_Rename = func(_, newname string) error {
+		if err := ioutil.WriteFile(newname, []byte("other"), 0644); err != nil {
+			return err
+		}
+		return errors.New("sharing violation")
+	}
+	defer func() { os_Rename = os.Rename }()
+
+	dir := makeTempDir("TestRenameFallbackCopy", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:           filename,
+		MaxSize:            100,
+		RenameFallbackCopy: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir), b, t)
+	existsWithContent(filename, []byte{}, t)
+	equals(int64(1), l.Stats().RenameCopies, t)
+
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("foo!"), t)
+}
@@ -0,0 +1,16 @@
+// +build !windows
+
+package lumberjack
+
+import (
+	"syscall"
+)
+
+// errRenameBusy is the error of a rename that may succeed if tried again.
+const errRenameBusy = syscall.EBUSY
+
+// retryableRename reports whether a rename that failed with err may succeed
+// if tried again, which is only the case if the file is busy.
+func retryableRename(err error) bool {
+	return renameErrno(err) == errRenameBusy
+}
@@ -0,0 +1,21 @@
+package lumberjack
+
+import (
+	"syscall"
+)
+
+// errRenameBusy is the error of a rename that may succeed if tried again:
+// ERROR_SHARING_VIOLATION, when another process, such as a virus scanner or
+// a tailer, has the file open without sharing it for deletion.
+const errRenameBusy syscall.Errno = 32
+
+// errorLockViolation is ERROR_LOCK_VIOLATION, when another process has
+// locked part of the file.
+const errorLockViolation syscall.Errno = 33
+
+// retryableRename reports whether a rename that failed with err may succeed
+// if tried again, which is only the case if another process holds the file.
+func retryableRename(err error) bool {
+	errno := renameErrno(err)
+	return errno == errRenameBusy || errno == errorLockViolation
+}
@@ -0,0 +1,67 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReopenAfterMove(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestReopenAfterMove", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+
+	// logrotate moves the log file aside, then asks for it to be reopened.
+	moved := filepath.Join(dir, "foobar.log.1")
+	isNil(os.Rename(filename, moved), t)
+	isNil(l.Reopen(), t)
+	existsWithContent(filename, []byte{}, t)
+
+	b2 := []byte("foo!")
+	_, err = l.Write(b2)
+	isNil(err, t)
+	existsWithContent(filename, b2, t)
+	existsWithContent(moved, b, t)
+	fileCount(dir, 2, t)
+	equals(int64(0), l.Stats().Rotations, t)
+}
+
+func TestReopenAfterCopy(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestReopenAfterCopy", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+
+	// the log file is left in place and appended to.
+	isNil(l.Reopen(), t)
+	b2 := []byte("foo!")
+	_, err = l.Write(b2)
+	isNil(err, t)
+	existsWithContent(filename, append(b, b2...), t)
+	fileCount(dir, 1, t)
+}
@@ -0,0 +1,64 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"os"
+	"time"
+)
+
+// RestartInfo describes a start of the application, recorded in the log file
+// with a marker because of RestartMarker.
+type RestartInfo struct {
+	// Time is when the Logger first opened the log file.
+	Time time.Time `json:"time"`
+
+	// PID is the process ID of the application, and RunID the Logger's RunID.
+	PID   int    `json:"pid"`
+	RunID string `json:"runid,omitempty"`
+
+	// PreviousLastWrite and PreviousSize are the modification time and size
+	// of the log file left by the previous run, if there was one, i.e. when
+	// the previous run last wrote to it.
+	PreviousLastWrite time.Time `json:"previous_last_write,omitempty"`
+	PreviousSize      int64     `json:"previous_size,omitempty"`
+}
+
+// defaultRestartMarker formats a restart marker as a line of JSON.
+func defaultRestartMarker(info RestartInfo) []byte {
+	b, err := json.Marshal(struct {
+		Msg string `json:"msg"`
+		RestartInfo
+	}{"lumberjack: process restarted", info})
+	if err != nil {
+		return nil
+	}
+	return append(b, '\n')
+}
+
+// restartInfo returns the information for the restart marker, or nil if no
+// marker is to be written.  It is called before the log file is first
+// opened, to find out about the log file left by the previous run.
+func (l *Logger) restartInfo() *RestartInfo {
+	if !l.RestartMarker || l.restarted {
+		return nil
+	}
+	info := &RestartInfo{Time: l.now(), PID: os.Getpid(), RunID: l.RunID}
+	if fi, err := os_Stat(l.activeFilename()); err == nil {
+		info.PreviousLastWrite = fi.ModTime()
+		info.PreviousSize = fi.Size()
+	}
+	return info
+}
+
+// writeRestartMarker writes the restart marker to the log file, which has
+// just been opened.
+func (l *Logger) writeRestartMarker(info RestartInfo) error {
+	l.restarted = true
+	format := l.FormatRestartMarker
+	if format == nil {
+		format = defaultRestartMarker
+	}
+	n, err := l.writeFile(format(info))
+	l.size += int64(n)
+	return err
+}
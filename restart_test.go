@@ -0,0 +1,108 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRestartMarker(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestartMarker", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	isNil(ioutil.WriteFile(filename, []byte("boo!\n"), 0644), t)
+	prev, err := os.Stat(filename)
+	isNil(err, t)
+
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       1000,
+		RunID:         "build-42",
+		RestartMarker: true,
+	}
+	defer l.Close()
+
+	_, err = l.Write([]byte("foo!\n"))
+	isNil(err, t)
+	isNil(l.Close(), t)
+	// the marker is only written once.
+	_, err = l.Write([]byte("bar!\n"))
+	isNil(err, t)
+
+	b, err := ioutil.ReadFile(filename)
+	isNil(err, t)
+	lines := strings.Split(string(b), "\n")
+	equals(5, len(lines), t)
+	equals("boo!", lines[0], t)
+	equals("foo!", lines[2], t)
+	equals("bar!", lines[3], t)
+
+	var marker struct {
+		Msg string `json:"msg"`
+		RestartInfo
+	}
+	isNil(json.Unmarshal([]byte(lines[1]), &marker), t)
+	equals("lumberjack: process restarted", marker.Msg, t)
+	equals(os.Getpid(), marker.PID, t)
+	equals("build-42", marker.RunID, t)
+	assert(marker.Time.Equal(fakeTime()), t, "expected the marker at %v, got %v", fakeTime(), marker.Time)
+	assert(marker.PreviousLastWrite.Equal(prev.ModTime()), t,
+		"expected the previous last write at %v, got %v", prev.ModTime(), marker.PreviousLastWrite)
+	equals(int64(5), marker.PreviousSize, t)
+	equals(int64(len(b)), l.Stats().CurrentSize, t)
+}
+
+func TestFormatRestartMarker(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestFormatRestartMarker", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       1000,
+		RestartMarker: true,
+		FormatRestartMarker: func(info RestartInfo) []byte {
+			if !info.PreviousLastWrite.IsZero() {
+				return []byte("restarted after a previous run\n")
+			}
+			return []byte("started\n")
+		},
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("foo!\n"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("started\nfoo!\n"), t)
+}
+
+func TestRestartMarkerOpen(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestartMarkerOpen", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:            filename,
+		MaxSize:             1000,
+		RestartMarker:       true,
+		FormatRestartMarker: func(RestartInfo) []byte { return []byte("started\n") },
+	}
+	defer l.Close()
+
+	isNil(l.Open(), t)
+	existsWithContent(filename, []byte("started\n"), t)
+	_, err := l.Write([]byte("foo!\n"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("started\nfoo!\n"), t)
+}
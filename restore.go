@@ -0,0 +1,126 @@
+package lumberjack
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// Restore reinstates the named backup as the current log file.  The name may
+// be the base name of the backup or its full path.  The current log file is
+// rotated first, as if Rotate had been called, and compressed backups are
+// decompressed.  The backup itself is removed once its content is in the
+// current log file, and subsequent writes are appended to it.  Like a backup
+// opened with OpenBackup, it isn't cleaned up while it is being restored.
+func (l *Logger) Restore(backupName string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := l.findBackup(backupName)
+	if err != nil {
+		return err
+	}
+	backup := filepath.Join(f.dir, f.Name())
+
+	// the backup is marked as being read before it is opened, so that the
+	// mill doesn't remove or compress it while it is copied.
+	reading := l.startReading(backup)
+	defer l.stopReading(reading)
+	src, err := os.Open(backup)
+	if err != nil {
+		return fmt.Errorf("can't open backup: %s", err)
+	}
+	defer src.Close()
+	r, err := decryptBackup(backup, src)
+	if err != nil {
+		return err
+	}
+	c := compressorFor(trimEncryptSuffix(backup))
+	compressed := c != nil
+	if compressed {
+		dec, err := c.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("can't decompress backup: %s", err)
+		}
+		defer dec.Close()
+		r = dec
+	}
+	meta, metaErr := readMetadata(backup)
+
+	// buffered writes go before the restored content.
+	if err := l.flush(); err != nil {
+		return err
+	}
+	if err := l.rotate(RotationRestore); err != nil {
+		return err
+	}
+	// the rotation may have renumbered the backups, this one included.
+	l.readingMu.Lock()
+	backup = reading.name
+	l.readingMu.Unlock()
+	if err := l.restoreFrom(r); err != nil {
+		return fmt.Errorf("can't restore backup: %s", err)
+	}
+	if metaErr == nil {
+		l.firstWrite = meta.FirstWrite
+		l.lastWrite = meta.LastWrite
+	}
+
+	if err := src.Close(); err != nil {
+		return err
+	}
+	if err := os.Remove(backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("can't remove restored backup: %s", err)
+	}
+	if !compressed {
+		// the backup may have been compressed while we were restoring it.
+		if c, err := l.compressor(); err == nil {
+			_ = os.Remove(backup + c.Suffix())
+			_ = os.Remove(backup + c.Suffix() + signatureSuffix)
+			removeChecksums(backup + c.Suffix())
+		}
+	}
+	_ = os.Remove(metadataName(backup))
+	_ = os.Remove(backup + signatureSuffix)
+	removeChecksums(backup)
+	return nil
+}
+
+// restoreFrom writes the content of a backup read from r to the new log file
+// like writes are, but without rotating it.
+func (l *Logger) restoreFrom(r io.Reader) error {
+	if err := l.checkStalled(); err != nil {
+		return err
+	}
+	buf := make([]byte, readFromBufferSize)
+	for {
+		n, errRead := r.Read(buf)
+		if n > 0 {
+			l.stats.Writes++
+			if _, err := l.writeCurrent(buf[:n], nil); err != nil {
+				return err
+			}
+		}
+		if errRead == io.EOF {
+			return nil
+		}
+		if errRead != nil {
+			return errRead
+		}
+	}
+}
+
+// findBackup returns the backup with the given base name or path.
+func (l *Logger) findBackup(name string) (logInfo, error) {
+	files, err := l.oldLogFiles()
+	if err != nil {
+		return logInfo{}, err
+	}
+	for _, f := range files {
+		if f.Name() == name || filepath.Join(f.dir, f.Name()) == name {
+			return f, nil
+		}
+	}
+	return logInfo{}, fmt.Errorf("can't find backup %s", name)
+}
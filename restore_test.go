@@ -0,0 +1,161 @@
+package lumberjack
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRestore(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestore", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+	existsWithContent(first, b, t)
+
+	b2 := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b2)
+
+	newFakeTime()
+	err := l.Restore(filepath.Base(first))
+	isNil(err, t)
+
+	// the restored backup is the current log file again, and what was in the
+	// current log file has been rotated.
+	existsWithContent(filename, b, t)
+	existsWithContent(backupFile(dir), b2, t)
+	notExist(first, t)
+	fileCount(dir, 2, t)
+
+	// writes are appended to the restored content.
+	b3 := []byte("baz!")
+	n, err := l.Write(b3)
+	isNil(err, t)
+	equals(len(b3), n, t)
+	existsWithContent(filename, append(b, b3...), t)
+}
+
+func TestRestoreCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestoreCompressed", t)
+	defer os.RemoveAll(dir)
+
+	content := []byte("compressed!")
+	bc := new(bytes.Buffer)
+	gz := gzip.NewWriter(bc)
+	_, err := gz.Write(content)
+	isNil(err, t)
+	isNil(gz.Close(), t)
+	backup := backupFile(dir) + compressSuffix
+	err = ioutil.WriteFile(backup, bc.Bytes(), 0644)
+	isNil(err, t)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	newFakeTime()
+	err = l.Restore(backup)
+	isNil(err, t)
+	existsWithContent(filename, content, t)
+	notExist(backup, t)
+}
+
+func TestRestoreMissing(t *testing.T) {
+	currentTime = fakeTime
+	dir := makeTempDir("TestRestoreMissing", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{Filename: logFile(dir)}
+	defer l.Close()
+	err := l.Restore("foobar-2014-05-04T14-44-33.555.log")
+	notNil(err, t)
+}
+
+func TestRestoreSequential(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestoreSequential", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		WriteMetadata:     true,
+		SequentialBackups: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("one"))
+	isNil(l.Rotate(), t)
+	writeToCurrentLog(t, l, filename, []byte("two"))
+
+	// the rotation renumbers the backup being restored, and the current log
+	// file takes its number.
+	isNil(l.Restore(filename+".1"), t)
+	existsWithContent(filename, []byte("one"), t)
+	existsWithContent(filename+".1", []byte("two"), t)
+	notExist(filename+".2", t)
+	notExist(filename+".2"+metadataSuffix, t)
+	fileCount(dir, 3, t)
+}
+
+func TestRestoreBuffered(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRestoreBuffered", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		BufferSize: 64,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	first := backupFile(dir)
+
+	// buffered writes go to the rotated log file, before the restored content.
+	_, err = l.Write([]byte("foo!"))
+	isNil(err, t)
+	newFakeTime()
+	isNil(l.Restore(first), t)
+	existsWithContent(filename, b, t)
+	existsWithContent(backupFile(dir), []byte("foo!"), t)
+
+	// the restored content counts towards the size and the statistics.
+	equals(int64(len(b)), l.size, t)
+	equals(int64(len(b)), l.Stats().BytesSinceRotation, t)
+}
@@ -0,0 +1,190 @@
+package lumberjack
+
+import (
+	"sort"
+	"time"
+)
+
+// retention holds the rules deciding which backups are removed and which are
+// compressed.
+type retention struct {
+	maxBackups           int
+	maxAge               time.Duration
+	compress             bool
+	keepLastDecompressed int
+
+	// keepDaily, keepWeekly and keepAllFor are the calendar retention rules,
+	// with days and weeks in loc.
+	keepDaily  int
+	keepWeekly int
+	keepAllFor time.Duration
+	loc        *time.Location
+
+	// removable reports whether a backup may be removed, or is nil if all
+	// backups may be removed.
+	removable func(logInfo) bool
+}
+
+// retention returns the rules for the given partition of the backups.
+func (l *Logger) retention(p partition) retention {
+	return retention{
+		maxBackups:           p.maxBackups,
+		maxAge:               maxAge(l.MaxAge, l.MaxAgeDuration),
+		compress:             l.Compress,
+		keepLastDecompressed: l.KeepLastDecompressed,
+		keepDaily:            l.KeepDaily,
+		keepWeekly:           l.KeepWeekly,
+		keepAllFor:           l.KeepAllFor,
+		loc:                  l.location(),
+		removable:            l.removableFilter(),
+	}
+}
+
+// maxAge returns how long backups are retained with MaxAge in days and
+// MaxAgeDuration, whichever is shorter, or 0 to retain them regardless of age.
+func maxAge(days int, d time.Duration) time.Duration {
+	age := time.Duration(int64(24*time.Hour) * int64(days))
+	if d > 0 && (age <= 0 || d < age) {
+		return d
+	}
+	if age < 0 {
+		return 0
+	}
+	return age
+}
+
+// apply splits the given backups, sorted newest first, into the ones that
+// should be removed and the ones that should be compressed as of now.
+func (r retention) apply(files []logInfo, now time.Time) (remove, compress []logInfo) {
+	if r.maxBackups > 0 && r.maxBackups < len(files) {
+		preserved := make(map[string]bool)
+		var remaining []logInfo
+		for _, f := range files {
+			// Only count the uncompressed log file or the
+			// compressed log file, not both.
+			fn := trimCompressSuffix(f.Name())
+			preserved[fn] = true
+
+			if len(preserved) > r.maxBackups {
+				remove = append(remove, f)
+			} else {
+				remaining = append(remaining, f)
+			}
+		}
+		files = remaining
+	}
+	if r.maxAge > 0 {
+		cutoff := now.Add(-1 * r.maxAge)
+
+		var remaining []logInfo
+		for _, f := range files {
+			if f.timestamp.Before(cutoff) {
+				remove = append(remove, f)
+			} else {
+				remaining = append(remaining, f)
+			}
+		}
+		files = remaining
+	}
+	if r.keepDaily > 0 || r.keepWeekly > 0 {
+		var removed []logInfo
+		files, removed = r.applyCalendar(files, now)
+		remove = append(remove, removed...)
+	}
+
+	if r.removable != nil {
+		var removable []logInfo
+		for _, f := range remove {
+			if r.removable(f) {
+				removable = append(removable, f)
+			} else {
+				files = append(files, f)
+			}
+		}
+		remove = removable
+		sort.Sort(byFormatTime(files))
+	}
+
+	if r.compress {
+		for i, f := range files {
+			if shouldCompressFile(r.keepLastDecompressed, i, f.Name()) {
+				compress = append(compress, f)
+			}
+		}
+	}
+	return remove, compress
+}
+
+// applyCalendar splits the given backups, sorted newest first, into the ones
+// kept by the calendar retention rules and the others.
+func (r retention) applyCalendar(files []logInfo, now time.Time) (keep, remove []logInfo) {
+	today := dayNumber(now, r.loc)
+	// the newest backup of each day and week claims it, under its name
+	// without compression, so that a backup being compressed counts once.
+	days := make(map[int64]string)
+	weeks := make(map[int64]string)
+	claim := func(claimed map[int64]string, n int64, name string) bool {
+		if kept, ok := claimed[n]; ok && kept != name {
+			return false
+		}
+		claimed[n] = name
+		return true
+	}
+
+	for _, f := range files {
+		name := trimCompressSuffix(f.Name())
+		day := dayNumber(f.timestamp, r.loc)
+		week := weekNumber(day)
+		kept := now.Sub(f.timestamp) < r.keepAllFor
+		if today-day < int64(r.keepDaily) && claim(days, day, name) {
+			kept = true
+		}
+		if weekNumber(today)-week < int64(r.keepWeekly) && claim(weeks, week, name) {
+			kept = true
+		}
+		if kept {
+			keep = append(keep, f)
+		} else {
+			remove = append(remove, f)
+		}
+	}
+	return keep, remove
+}
+
+// dayNumber returns the number of the calendar day of t in loc, counted from
+// January 1, 1970.
+func dayNumber(t time.Time, loc *time.Location) int64 {
+	y, m, d := t.In(loc).Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC).Unix() / (24 * 60 * 60)
+}
+
+// weekNumber returns the number of the calendar week, starting on Monday, of
+// the given day number.  January 1, 1970 was a Thursday.
+func weekNumber(day int64) int64 {
+	return (day + 3) / 7
+}
+
+// removableFilter returns a func reporting whether a backup may be removed, or
+// nil if all backups may be removed.
+func (l *Logger) removableFilter() func(logInfo) bool {
+	var filters []func(logInfo) bool
+	for _, filter := range []func(logInfo) bool{l.auditFilter(), l.shippedFilter(), l.pinnedFilter(), l.readingFilter()} {
+		if filter != nil {
+			filters = append(filters, filter)
+		}
+	}
+	switch len(filters) {
+	case 0:
+		return nil
+	case 1:
+		return filters[0]
+	}
+	return func(f logInfo) bool {
+		for _, filter := range filters {
+			if !filter(f) {
+				return false
+			}
+		}
+		return true
+	}
+}
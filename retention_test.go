@@ -0,0 +1,219 @@
+package lumberjack
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepDailyWeekly(t *testing.T) {
+	megabyte = 1
+	// Wednesday.
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = fakeTime }()
+
+	dir := makeTempDir("TestKeepDailyWeekly", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    100,
+		KeepDaily:  3,
+		KeepWeekly: 2,
+		KeepAllFor: 24 * time.Hour,
+	}
+	defer l.Close()
+
+	backups := []struct {
+		t    time.Time
+		keep bool
+	}{
+		{time.Date(2020, 1, 15, 11, 0, 0, 0, time.UTC), true},
+		{time.Date(2020, 1, 15, 1, 0, 0, 0, time.UTC), true},
+		// within KeepAllFor.
+		{time.Date(2020, 1, 14, 20, 0, 0, 0, time.UTC), true},
+		{time.Date(2020, 1, 14, 8, 0, 0, 0, time.UTC), false},
+		// the newest of the day.
+		{time.Date(2020, 1, 13, 9, 0, 0, 0, time.UTC), true},
+		// a Sunday, the newest of the previous week.
+		{time.Date(2020, 1, 12, 10, 0, 0, 0, time.UTC), true},
+		{time.Date(2020, 1, 11, 10, 0, 0, 0, time.UTC), false},
+		// too many weeks ago.
+		{time.Date(2020, 1, 3, 10, 0, 0, 0, time.UTC), false},
+	}
+	for _, b := range backups {
+		isNil(ioutil.WriteFile(backupFileWithTime(dir, b.t), []byte("boo!"), 0644), t)
+	}
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	isNil(l.Mill(context.Background()), t)
+
+	for _, b := range backups {
+		if b.keep {
+			exists(backupFileWithTime(dir, b.t), t)
+		} else {
+			notExist(backupFileWithTime(dir, b.t), t)
+		}
+	}
+}
+
+func TestMaxAgeDuration(t *testing.T) {
+	megabyte = 1
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = fakeTime }()
+
+	dir := makeTempDir("TestMaxAgeDuration", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		MaxAge:         1,
+		MaxAgeDuration: 6 * time.Hour,
+	}
+	defer l.Close()
+
+	backups := []struct {
+		t    time.Time
+		keep bool
+	}{
+		{time.Date(2020, 1, 15, 11, 0, 0, 0, time.UTC), true},
+		{time.Date(2020, 1, 15, 7, 0, 0, 0, time.UTC), true},
+		// the shorter of MaxAge and MaxAgeDuration applies.
+		{time.Date(2020, 1, 15, 5, 0, 0, 0, time.UTC), false},
+		{time.Date(2020, 1, 14, 20, 0, 0, 0, time.UTC), false},
+	}
+	for _, b := range backups {
+		isNil(ioutil.WriteFile(backupFileWithTime(dir, b.t), []byte("boo!"), 0644), t)
+	}
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	isNil(l.Mill(context.Background()), t)
+
+	for _, b := range backups {
+		if b.keep {
+			exists(backupFileWithTime(dir, b.t), t)
+		} else {
+			notExist(backupFileWithTime(dir, b.t), t)
+		}
+	}
+
+	equals(time.Duration(0), maxAge(0, 0), t)
+	equals(48*time.Hour, maxAge(2, 0), t)
+	equals(time.Hour, maxAge(0, time.Hour), t)
+	equals(24*time.Hour, maxAge(1, 48*time.Hour), t)
+}
+
+func TestModTimeFallback(t *testing.T) {
+	megabyte = 1
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = fakeTime }()
+
+	dir := makeTempDir("TestModTimeFallback", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:           filename,
+		MaxSize:            100,
+		MaxAge:             1,
+		TimePrecision:      TimePrecisionNano,
+		UseModTimeFallback: true,
+	}
+	defer l.Close()
+
+	files := []struct {
+		name    string
+		modTime time.Time
+		keep    bool
+	}{
+		// named with another TimePrecision, the timestamp still counts.
+		{"foobar-2020-01-13T10-00-00.123.log", now, false},
+		{"foobar-2020-01-15T10-00-00.log", now.Add(-72 * time.Hour), true},
+		// the modification time counts for names that can't be parsed.
+		{"foobar-15.01.2020.log", now.Add(-time.Hour), true},
+		{"foobar-10.01.2020.log", now.Add(-120 * time.Hour), false},
+		// other files are left alone.
+		{"other-10.01.2020.log", now.Add(-120 * time.Hour), true},
+		{"foobar-10.01.2020.txt", now.Add(-120 * time.Hour), true},
+	}
+	for _, f := range files {
+		name := filepath.Join(dir, f.name)
+		isNil(ioutil.WriteFile(name, []byte("boo!"), 0644), t)
+		isNil(os.Chtimes(name, f.modTime, f.modTime), t)
+	}
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	isNil(l.Mill(context.Background()), t)
+
+	for _, f := range files {
+		if f.keep {
+			exists(filepath.Join(dir, f.name), t)
+		} else {
+			notExist(filepath.Join(dir, f.name), t)
+		}
+	}
+}
+
+func TestModTimeFallbackSidecars(t *testing.T) {
+	megabyte = 1
+	now := time.Date(2020, 1, 15, 12, 0, 0, 0, time.UTC)
+	currentTime = func() time.Time { return now }
+	defer func() { currentTime = fakeTime }()
+
+	dir := makeTempDir("TestModTimeFallbackSidecars", t)
+	defer os.RemoveAll(dir)
+
+	// without an extension, the files next to a backup look like backups too.
+	filename := filepath.Join(dir, "foobar")
+	l := &Logger{
+		Filename:           filename,
+		MaxSize:            100,
+		MaxAge:             1,
+		UseModTimeFallback: true,
+	}
+	defer l.Close()
+
+	old := now.Add(-120 * time.Hour)
+	files := []struct {
+		name    string
+		modTime time.Time
+		keep    bool
+	}{
+		{"foobar-15.01.2020", now, true},
+		{"foobar-15.01.2020" + metadataSuffix, old, true},
+		{"foobar-15.01.2020" + signatureSuffix, old, true},
+		{"foobar-15.01.2020.sha256", old, true},
+		{"foobar-15.01.2020" + compactingSuffix, old, true},
+		{"foobar-10.01.2020", old, false},
+	}
+	for _, f := range files {
+		name := filepath.Join(dir, f.name)
+		isNil(ioutil.WriteFile(name, []byte("boo!"), 0644), t)
+		isNil(os.Chtimes(name, f.modTime, f.modTime), t)
+	}
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	isNil(l.Mill(context.Background()), t)
+
+	for _, f := range files {
+		if f.keep {
+			exists(filepath.Join(dir, f.name), t)
+		} else {
+			notExist(filepath.Join(dir, f.name), t)
+		}
+	}
+}
+
+func TestWeekNumber(t *testing.T) {
+	// weeks start on Monday.
+	sunday := dayNumber(time.Date(2020, 1, 12, 23, 0, 0, 0, time.UTC), time.UTC)
+	monday := dayNumber(time.Date(2020, 1, 13, 1, 0, 0, 0, time.UTC), time.UTC)
+	equals(sunday+1, monday, t)
+	equals(weekNumber(sunday)+1, weekNumber(monday), t)
+	equals(weekNumber(monday), weekNumber(monday+6), t)
+}
@@ -0,0 +1,103 @@
+package lumberjack
+
+import "time"
+
+// RotationReason describes why a log file was rotated.
+type RotationReason string
+
+const (
+	// RotationSize is used when a log file is rotated because a write would
+	// take it over MaxSize, including when an existing log file is already
+	// too large when it is opened.
+	RotationSize RotationReason = "size"
+
+	// RotationManual is used when a log file is rotated by calling Rotate.
+	RotationManual RotationReason = "manual"
+
+	// RotationInterval is used when a log file is rotated because it has been
+	// written to for MaxInterval.
+	RotationInterval RotationReason = "interval"
+
+	// RotationTime is used when a log file is rotated because it is the time
+	// of day set by RotateAt.
+	RotationTime RotationReason = "time"
+
+	// RotationRestore is used when a log file is rotated to make way for a
+	// backup that is restored by calling Restore.
+	RotationRestore RotationReason = "restore"
+)
+
+// RotationInfo describes a rotation of the log file.  It is passed to
+// OnRotate, returned by LastRotation and, if WriteMetadata is enabled or RunID
+// is set, stored in the metadata file of the backup.
+type RotationInfo struct {
+	// OldPath is the path the log file had before it was rotated.
+	OldPath string `json:"oldpath"`
+
+	// NewPath is the path of the backup the log file was moved to.
+	NewPath string `json:"newpath"`
+
+	// Reason is why the log file was rotated.
+	Reason RotationReason `json:"reason"`
+
+	// Time is when the log file was rotated.
+	Time time.Time `json:"time"`
+
+	// Bytes is the size of the log file when it was rotated.
+	Bytes int64 `json:"bytes"`
+
+	// Duration is how long it took to move the log file out of the way and
+	// open a new one.
+	Duration time.Duration `json:"duration"`
+
+	// FirstWrite and LastWrite are the times of the first and last writes to
+	// the log file by the Logger.  FirstWrite is zero if the Logger didn't
+	// write to the log file before it was rotated.
+	FirstWrite time.Time `json:"firstwrite"`
+	LastWrite  time.Time `json:"lastwrite"`
+
+	// CompressedPath is the path of the compressed backup once the backup has
+	// been compressed, and empty until then.
+	CompressedPath string `json:"compressedpath,omitempty"`
+
+	// RunID is the RunID of the Logger that rotated the log file.
+	RunID string `json:"runid,omitempty"`
+
+	// Existing is the path of a backup, compressed or not, that was in the
+	// way of the name picked for the backup.  It is the same as NewPath if
+	// the log file was moved over it, see ExistingBackups.
+	Existing string `json:"existing,omitempty"`
+
+	// BackupDirFree and BackupDirUsed are the space available and the space
+	// used on the filesystem holding the backup right after the rotation, in
+	// bytes.  They are 0 where the platform can't report them.
+	BackupDirFree int64 `json:"backupdirfree,omitempty"`
+	BackupDirUsed int64 `json:"backupdirused,omitempty"`
+
+	// Duplicates are the times of later rotations that produced a backup
+	// identical to this one, which wasn't kept because of DedupBackups.
+	Duplicates []time.Time `json:"duplicates,omitempty"`
+}
+
+// LastRotation returns the RotationInfo of the last rotation of the log file
+// by the Logger, which is zero if it hasn't rotated it yet.
+func (l *Logger) LastRotation() RotationInfo {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	return l.lastRotation
+}
+
+// compressed records that the given backup has been compressed.
+func (l *Logger) compressed(backup, compressedPath string) {
+	l.rotatedMu.Lock()
+	if l.lastRotation.NewPath == backup {
+		l.lastRotation.CompressedPath = compressedPath
+	}
+	l.rotatedMu.Unlock()
+
+	if meta, err := readMetadata(backup); err == nil {
+		meta.CompressedPath = compressedPath
+		// metadata is best effort.
+		_ = writeMetadata(backup, meta)
+	}
+}
@@ -0,0 +1,96 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestOnRotate(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestOnRotate", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	var rotations []RotationInfo
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+		OnRotate: func(info RotationInfo) {
+			rotations = append(rotations, info)
+		},
+	}
+	defer l.Close()
+
+	equals(RotationInfo{}, l.LastRotation(), t)
+
+	first := fakeTime()
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	newFakeTime()
+	b2 := []byte("foooooo!")
+	n, err := l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+
+	equals(1, len(rotations), t)
+	info := rotations[0]
+	equals(filename, info.OldPath, t)
+	equals(backupFile(dir), info.NewPath, t)
+	equals(RotationSize, info.Reason, t)
+	equals(fakeTime(), info.Time, t)
+	equals(int64(len(b)), info.Bytes, t)
+	assert(info.Duration > 0, t, "expected a positive duration, got %v", info.Duration)
+	equals(first, info.FirstWrite, t)
+	equals(first, info.LastWrite, t)
+	equals("", info.CompressedPath, t)
+	equals(info, l.LastRotation(), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	equals(2, len(rotations), t)
+	equals(RotationManual, rotations[1].Reason, t)
+	equals(rotations[1], l.LastRotation(), t)
+}
+
+func TestRotationInfoCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotationInfoCompressed", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       10,
+		Compress:      true,
+		WriteMetadata: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	// we need to wait a little bit since the files get compressed on a
+	// different goroutine.
+	<-time.After(300 * time.Millisecond)
+
+	backup := backupFile(dir)
+	info := l.LastRotation()
+	equals(backup, info.NewPath, t)
+	equals(backup+compressSuffix, info.CompressedPath, t)
+
+	// the metadata describes the rotation too.
+	meta, err := readMetadata(backup + compressSuffix)
+	isNil(err, t)
+	equals(info.NewPath, meta.NewPath, t)
+	equals(info.Reason, meta.Reason, t)
+	equals(info.Bytes, meta.Bytes, t)
+	equals(info.CompressedPath, meta.CompressedPath, t)
+}
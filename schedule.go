@@ -0,0 +1,102 @@
+package lumberjack
+
+import (
+	"time"
+)
+
+// rotateAtFormat is the format of RotateAt.
+const rotateAtFormat = "15:04"
+
+// schedule sets the time the log file is due to be rotated, and arranges for
+// it to be rotated then.  A newly created log file always gets a new due time,
+// while an existing one that is reopened keeps the one it had.
+func (l *Logger) schedule(created bool) {
+	if !created && !l.rotateDue.IsZero() {
+		return
+	}
+	now := l.now()
+	l.rotateDue = time.Time{}
+	if l.MaxInterval > 0 {
+		l.rotateDue, l.rotateReason = now.Add(l.MaxInterval), RotationInterval
+	}
+	if at, ok := l.nextRotateAt(now); ok && (l.rotateDue.IsZero() || !at.After(l.rotateDue)) {
+		l.rotateDue, l.rotateReason = at, RotationTime
+	}
+	if l.rotateDue.IsZero() {
+		return
+	}
+
+	l.startRotateTimer(l.rotateDue.Sub(now))
+}
+
+// startRotateTimer arranges for rotateScheduled to be called after d,
+// replacing any earlier arrangement.
+func (l *Logger) startRotateTimer(d time.Duration) {
+	if l.rotateTimer != nil {
+		l.rotateTimer()
+	}
+	l.rotateTimer = l.scheduleWork(WorkRotate, d, l.rotateScheduled)
+}
+
+// stopSchedule cancels the scheduled rotation.
+func (l *Logger) stopSchedule() {
+	if l.rotateTimer != nil {
+		l.rotateTimer()
+		l.rotateTimer = nil
+	}
+	l.rotateDue = time.Time{}
+}
+
+// rotationDue reports whether the log file is due to be rotated.
+func (l *Logger) rotationDue() bool {
+	return !l.rotateDue.IsZero() && !l.now().Before(l.rotateDue)
+}
+
+// rotateScheduled rotates the log file when it is due.  If the log file isn't
+// open, the next Write rotates it instead.
+func (l *Logger) rotateScheduled() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rotateTimer == nil || l.file == nil {
+		return
+	}
+	if !l.rotationDue() {
+		// the log file was rotated in the meantime.
+		l.startRotateTimer(l.rotateDue.Sub(l.now()))
+		return
+	}
+	if l.RotateOnLineBoundary && l.midLine {
+		// the next write rotates the log file once the line is finished.
+		return
+	}
+	// what am I going to do, log this?
+	_ = l.rotate(l.rotateReason)
+}
+
+// location returns the time zone of calendar times, which is local time if
+// LocalTime is set, and UTC otherwise.
+func (l *Logger) location() *time.Location {
+	if l.LocalTime {
+		return time.Local
+	}
+	return time.UTC
+}
+
+// nextRotateAt returns the first time after now that is the time of day set by
+// RotateAt, if it is set and valid.
+func (l *Logger) nextRotateAt(now time.Time) (time.Time, bool) {
+	if l.RotateAt == "" {
+		return time.Time{}, false
+	}
+	at, err := time.Parse(rotateAtFormat, l.RotateAt)
+	if err != nil {
+		return time.Time{}, false
+	}
+	loc := l.location()
+	now = now.In(loc)
+	next := time.Date(now.Year(), now.Month(), now.Day(), at.Hour(), at.Minute(), 0, 0, loc)
+	if !next.After(now) {
+		next = time.Date(now.Year(), now.Month(), now.Day()+1, at.Hour(), at.Minute(), 0, 0, loc)
+	}
+	return next, true
+}
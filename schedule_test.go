@@ -0,0 +1,198 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func TestMaxIntervalOnWrite(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestMaxIntervalOnWrite", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	var reasons []RotationReason
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		MaxInterval: time.Hour,
+		OnRotate: func(info RotationInfo) {
+			reasons = append(reasons, info.Reason)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	fakeCurrentTime = fakeCurrentTime.Add(59 * time.Minute)
+	_, err := l.Write(b)
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!boo!"), t)
+	equals(0, len(reasons), t)
+
+	fakeCurrentTime = fakeCurrentTime.Add(time.Minute)
+	b2 := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b2)
+	existsWithContent(backupFile(dir), []byte("boo!boo!"), t)
+	equals([]RotationReason{RotationInterval}, reasons, t)
+
+	// the interval starts over with the new file.
+	fakeCurrentTime = fakeCurrentTime.Add(59 * time.Minute)
+	_, err = l.Write(b2)
+	isNil(err, t)
+	existsWithContent(filename, []byte("foo!foo!"), t)
+	equals(1, len(reasons), t)
+}
+
+func TestMaxIntervalTimer(t *testing.T) {
+	currentTime = time.Now
+	defer func() { currentTime = fakeTime }()
+	megabyte = 1
+
+	dir := makeTempDir("TestMaxIntervalTimer", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		MaxInterval: 50 * time.Millisecond,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	// the log file is rotated without any further writes.
+	<-time.After(75 * time.Millisecond)
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(1, len(backups), t)
+	existsWithContent(backups[0].Path, b, t)
+	existsWithContent(filename, []byte{}, t)
+
+	// no more rotations once the Logger is closed.
+	isNil(l.Close(), t)
+	<-time.After(75 * time.Millisecond)
+	fileCount(dir, 2, t)
+}
+
+func TestMaxIntervalFakeClock(t *testing.T) {
+	megabyte = 1
+
+	dir := makeTempDir("TestMaxIntervalFakeClock", t)
+	defer os.RemoveAll(dir)
+
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := lumberjacktest.NewFakeClock(start)
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		MaxInterval: time.Hour,
+		Clock:       clock,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	equals(1, clock.Pending(), t)
+
+	clock.Advance(59 * time.Minute)
+	fileCount(dir, 1, t)
+
+	// the timer rotates the log file, naming the backup with the clock's time.
+	clock.Advance(time.Minute)
+	existsWithContent(backupFileWithTime(dir, start.Add(time.Hour)), b, t)
+	existsWithContent(filename, []byte{}, t)
+	equals(RotationInterval, l.LastRotation().Reason, t)
+
+	// and the next rotations are scheduled.
+	equals(1, clock.Pending(), t)
+	clock.Advance(2 * time.Hour)
+	fileCount(dir, 4, t)
+
+	isNil(l.Close(), t)
+	equals(0, clock.Pending(), t)
+}
+
+func TestRotateAt(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestRotateAt", t)
+	defer os.RemoveAll(dir)
+
+	fakeCurrentTime = time.Date(2020, 1, 1, 23, 50, 0, 0, time.UTC)
+	filename := logFile(dir)
+	var reasons []RotationReason
+	l := &Logger{
+		Filename:    filename,
+		MaxSize:     100,
+		RotateAt:    "00:00",
+		MaxInterval: 24 * time.Hour,
+		OnRotate: func(info RotationInfo) {
+			reasons = append(reasons, info.Reason)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	// midnight comes before the interval is up.
+	fakeCurrentTime = time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+	b2 := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b2)
+	existsWithContent(backupFile(dir), b, t)
+	equals([]RotationReason{RotationTime}, reasons, t)
+
+	// and the next one is a day later.
+	fakeCurrentTime = time.Date(2020, 1, 2, 23, 59, 0, 0, time.UTC)
+	_, err := l.Write(b2)
+	isNil(err, t)
+	equals(1, len(reasons), t)
+	fakeCurrentTime = time.Date(2020, 1, 3, 0, 0, 0, 0, time.UTC)
+	writeToCurrentLog(t, l, filename, b)
+	equals([]RotationReason{RotationTime, RotationTime}, reasons, t)
+}
+
+func TestNextRotateAt(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available")
+	}
+	local := time.Local
+	time.Local = loc
+	defer func() { time.Local = local }()
+
+	tests := []struct {
+		rotateAt  string
+		localTime bool
+		now       time.Time
+		exp       time.Time
+	}{
+		{"00:00", false, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)},
+		{"06:30", false, time.Date(2020, 1, 1, 6, 29, 0, 0, time.UTC), time.Date(2020, 1, 1, 6, 30, 0, 0, time.UTC)},
+		{"06:30", false, time.Date(2020, 1, 1, 6, 30, 0, 0, time.UTC), time.Date(2020, 1, 2, 6, 30, 0, 0, time.UTC)},
+		// midnight in New York is 5 in the morning UTC in winter.
+		{"00:00", true, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2020, 1, 2, 5, 0, 0, 0, time.UTC)},
+		// and 4 in the morning once daylight saving time starts.
+		{"00:00", true, time.Date(2020, 3, 8, 12, 0, 0, 0, time.UTC), time.Date(2020, 3, 9, 4, 0, 0, 0, time.UTC)},
+	}
+	for i, test := range tests {
+		l := &Logger{RotateAt: test.rotateAt, LocalTime: test.localTime}
+		next, ok := l.nextRotateAt(test.now)
+		assert(ok, t, "test %d: no rotation time", i)
+		assert(next.Equal(test.exp), t, "test %d: expected %v, got %v", i, test.exp, next)
+	}
+
+	_, ok := (&Logger{RotateAt: "noon"}).nextRotateAt(time.Now())
+	assert(!ok, t, "invalid RotateAt gave a rotation time")
+}
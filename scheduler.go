@@ -0,0 +1,89 @@
+package lumberjack
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Work is a kind of timed work a Logger hands to its Scheduler.
+type Work string
+
+const (
+	// WorkRotate is the rotation of the log file because of MaxInterval or
+	// RotateAt.
+	WorkRotate Work = "rotate"
+
+	// WorkIdle is the check for the log file having been idle for
+	// CloseAfterIdle.
+	WorkIdle Work = "idle"
+
+	// WorkWatch is the check of the log file for external changes because of
+	// WatchExternalChanges.
+	WorkWatch Work = "watch"
+
+	// WorkSync is the sync of the log file because of SyncPeriodic.
+	WorkSync Work = "sync"
+
+	// WorkConfig is the check of the config file given to WatchConfigFile
+	// for changes.
+	WorkConfig Work = "config"
+)
+
+// Scheduler runs the timed work of a Logger.  Setting the same Scheduler on
+// many Loggers puts their timed work in one place, e.g. to spread it out, or
+// to run it on demand in tests.
+type Scheduler interface {
+	// Schedule calls f in its own goroutine after the duration d has
+	// elapsed, or later, for the given kind of work.  The returned stop
+	// function cancels the call, and reports whether it did so before f was
+	// called.
+	Schedule(work Work, d time.Duration, f func()) (stop func() bool)
+}
+
+// TimerScheduler is a Scheduler that runs work with the timers of a Clock.
+// A random delay of up to Jitter is added to each call, so that the many
+// Loggers of a process sharing it, e.g. all rotating at midnight, don't all
+// do their work at the same moment.
+type TimerScheduler struct {
+	// Clock runs the timers.  The default is the system clock.
+	Clock Clock
+
+	// Jitter is the longest random delay added to each call.  The default
+	// (0) is not to add any.
+	Jitter time.Duration
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// Schedule implements Scheduler.
+func (s *TimerScheduler) Schedule(work Work, d time.Duration, f func()) (stop func() bool) {
+	d += s.jitter()
+	if s.Clock != nil {
+		return s.Clock.AfterFunc(d, f)
+	}
+	return systemClock{}.AfterFunc(d, f)
+}
+
+// jitter returns a random delay of up to Jitter.
+func (s *TimerScheduler) jitter() time.Duration {
+	if s.Jitter <= 0 {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.rand == nil {
+		s.rand = rand.New(rand.NewSource(time.Now().UnixNano()))
+	}
+	return time.Duration(s.rand.Int63n(int64(s.Jitter) + 1))
+}
+
+// scheduleWork calls f after the duration d has elapsed, with the Scheduler
+// if one is set, and with the Clock otherwise.
+func (l *Logger) scheduleWork(work Work, d time.Duration, f func()) (stop func() bool) {
+	if l.Scheduler != nil {
+		return l.Scheduler.Schedule(work, d, f)
+	}
+	return l.clock().AfterFunc(d, f)
+}
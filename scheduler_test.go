@@ -0,0 +1,77 @@
+package lumberjack
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+// recordingScheduler records the work scheduled with it.
+type recordingScheduler struct {
+	TimerScheduler
+	mu   sync.Mutex
+	work []Work
+}
+
+func (s *recordingScheduler) Schedule(work Work, d time.Duration, f func()) func() bool {
+	s.mu.Lock()
+	s.work = append(s.work, work)
+	s.mu.Unlock()
+	return s.TimerScheduler.Schedule(work, d, f)
+}
+
+func TestScheduler(t *testing.T) {
+	megabyte = 1
+
+	dir := makeTempDir("TestScheduler", t)
+	defer os.RemoveAll(dir)
+
+	clock := lumberjacktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	s := &recordingScheduler{TimerScheduler: TimerScheduler{Clock: clock}}
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        100,
+		MaxInterval:    time.Hour,
+		CloseAfterIdle: time.Minute,
+		Clock:          clock,
+		Scheduler:      s,
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	equals([]Work{WorkRotate, WorkIdle}, s.work, t)
+
+	// the idle log file is closed once the scheduler's clock says so.
+	clock.Advance(time.Minute)
+	l.mu.Lock()
+	closed := l.file == nil
+	l.mu.Unlock()
+	assert(closed, t, "expected the idle log file to be closed")
+}
+
+func TestTimerSchedulerJitter(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := lumberjacktest.NewFakeClock(start)
+	s := &TimerScheduler{Clock: clock, Jitter: time.Minute}
+
+	var called []time.Time
+	for i := 0; i < 20; i++ {
+		s.Schedule(WorkRotate, time.Hour, func() { called = append(called, clock.Now()) })
+	}
+	clock.Advance(time.Hour - time.Nanosecond)
+	equals(0, len(called), t)
+	clock.Advance(time.Minute + time.Nanosecond)
+	equals(20, len(called), t)
+
+	spread := false
+	for _, c := range called {
+		assert(!c.After(start.Add(time.Hour+time.Minute)), t, "expected at most a minute of jitter, got %v", c.Sub(start))
+		spread = spread || !c.Equal(called[0])
+	}
+	assert(spread, t, "expected the calls to be spread out, got %v", called)
+}
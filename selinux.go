@@ -0,0 +1,11 @@
+// +build !linux
+
+package lumberjack
+
+import (
+	"errors"
+)
+
+func setSELinuxLabel(_ string, _ string) error {
+	return errors.New("SELinux is not supported")
+}
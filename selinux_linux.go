@@ -0,0 +1,12 @@
+package lumberjack
+
+import (
+	"syscall"
+)
+
+// setSELinuxLabel sets the SELinux security context of the named file.
+func setSELinuxLabel(name string, label string) error {
+	// the kernel expects the context to be NUL terminated, like libselinux
+	// passes it.
+	return syscall.Setxattr(name, "security.selinux", append([]byte(label), 0), 0)
+}
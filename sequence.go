@@ -0,0 +1,141 @@
+package lumberjack
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sequenceCodec is the NameCodec used with SequentialBackups.  It appends a
+// number to the name of the log file, e.g. foo.log.1, with 1 for the newest
+// backup.  Backup names don't hold a time, so it is ignored.
+type sequenceCodec struct {
+	base string
+}
+
+func (c sequenceCodec) Encode(t time.Time, seq int) string {
+	return c.base + "." + strconv.Itoa(seq)
+}
+
+func (c sequenceCodec) Decode(name string) (time.Time, int, error) {
+	n, suffix, ok := c.split(name)
+	if !ok || suffix != "" {
+		return time.Time{}, 0, errors.New("not a numbered backup")
+	}
+	return time.Time{}, n, nil
+}
+
+// split splits the name of a backup, or of a file that goes with it such as a
+// compressed backup or its metadata, into the number of the backup and what
+// follows it.
+func (c sequenceCodec) split(name string) (n int, suffix string, ok bool) {
+	if !strings.HasPrefix(name, c.base+".") {
+		return 0, "", false
+	}
+	rest := name[len(c.base)+1:]
+	end := 0
+	for end < len(rest) && rest[end] >= '0' && rest[end] <= '9' {
+		end++
+	}
+	n, err := strconv.Atoi(rest[:end])
+	if err != nil || n < 1 || rest[0] == '0' {
+		return 0, "", false
+	}
+	return n, rest[end:], true
+}
+
+// bySequence sorts numbered backups newest, i.e. lowest number, first.
+type bySequence []logInfo
+
+func (b bySequence) Less(i, j int) bool { return b[i].seq < b[j].seq }
+func (b bySequence) Swap(i, j int)      { b[i], b[j] = b[j], b[i] }
+func (b bySequence) Len() int           { return len(b) }
+
+// sequenceName returns the name of the new backup with SequentialBackups.
+func (l *Logger) sequenceName() string {
+	return filepath.Join(l.backupDir(), l.codec().Encode(time.Time{}, 1))
+}
+
+// shiftBackups renumbers the backups in dir, along with the files that go
+// with them, to make way for a new backup numbered 1.  The backups the mill
+// knows about by name are renamed too.
+func (l *Logger) shiftBackups(dir string) error {
+	c, ok := l.codec().(sequenceCodec)
+	if !ok {
+		return nil
+	}
+	// the mill mustn't compress or remove backups while they're renumbered.
+	l.seqMu.Lock()
+	defer l.seqMu.Unlock()
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("can't read backup directory: %s", err)
+	}
+
+	type numbered struct {
+		n      int
+		suffix string
+	}
+	var shift []numbered
+	for _, f := range files {
+		if n, suffix, ok := c.split(f.Name()); ok && !f.IsDir() {
+			shift = append(shift, numbered{n, suffix})
+		}
+	}
+	// the highest numbers go first, so nothing is overwritten.
+	sort.Slice(shift, func(i, j int) bool { return shift[i].n > shift[j].n })
+
+	for _, s := range shift {
+		oldname := filepath.Join(dir, c.Encode(time.Time{}, s.n)+s.suffix)
+		newname := filepath.Join(dir, c.Encode(time.Time{}, s.n+1)+s.suffix)
+		if err := os.Rename(oldname, newname); err != nil {
+			return fmt.Errorf("can't renumber backup: %s", err)
+		}
+	}
+
+	renumber := func(name string) string {
+		if filepath.Dir(name) != dir {
+			return name
+		}
+		n, suffix, ok := c.split(filepath.Base(name))
+		if !ok {
+			return name
+		}
+		return filepath.Join(dir, c.Encode(time.Time{}, n+1)+suffix)
+	}
+	l.rotatedMu.Lock()
+	for i, name := range l.rotated {
+		l.rotated[i] = renumber(name)
+	}
+	for i, name := range l.unfinalized {
+		l.unfinalized[i] = renumber(name)
+	}
+	l.rotatedMu.Unlock()
+	if len(l.archived) > 0 {
+		archived := make(map[string]bool, len(l.archived))
+		for name := range l.archived {
+			archived[renumber(name)] = true
+		}
+		l.archived = archived
+	}
+	l.readingMu.Lock()
+	if len(l.reading) > 0 {
+		reading := make(map[string]*readers, len(l.reading))
+		for _, r := range l.reading {
+			r.name = renumber(r.name)
+			reading[r.name] = r
+		}
+		l.reading = reading
+	}
+	l.readingMu.Unlock()
+	return nil
+}
@@ -0,0 +1,106 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSequentialBackups(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSequentialBackups", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		MaxBackups:        2,
+		WriteMetadata:     true,
+		SequentialBackups: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("one"))
+	isNil(l.Rotate(), t)
+	existsWithContent(filename+".1", []byte("one"), t)
+	exists(filename+".1"+metadataSuffix, t)
+
+	// the backups are renumbered on each rotation, metadata and all.
+	writeToCurrentLog(t, l, filename, []byte("two"))
+	isNil(l.Rotate(), t)
+	existsWithContent(filename+".1", []byte("two"), t)
+	existsWithContent(filename+".2", []byte("one"), t)
+	exists(filename+".2"+metadataSuffix, t)
+
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(2, len(backups), t)
+	equals(filename+".1", backups[0].Path, t)
+	equals(filename+".2", backups[1].Path, t)
+
+	// the oldest backup goes once there are more than MaxBackups.
+	writeToCurrentLog(t, l, filename, []byte("three"))
+	isNil(l.Rotate(), t)
+	<-time.After(10 * time.Millisecond)
+	existsWithContent(filename+".1", []byte("three"), t)
+	existsWithContent(filename+".2", []byte("two"), t)
+	notExist(filename+".3", t)
+	notExist(filename+".3"+metadataSuffix, t)
+}
+
+func TestSequentialBackupsCompressed(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSequentialBackupsCompressed", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:          filename,
+		MaxSize:           100,
+		Compress:          true,
+		SequentialBackups: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("one"))
+	isNil(l.Rotate(), t)
+	<-time.After(300 * time.Millisecond)
+	verifyCompressedFile(filename+".1", []byte("one"), t)
+
+	writeToCurrentLog(t, l, filename, []byte("two"))
+	isNil(l.Rotate(), t)
+	<-time.After(300 * time.Millisecond)
+	verifyCompressedFile(filename+".1", []byte("two"), t)
+	verifyCompressedFile(filename+".2", []byte("one"), t)
+	fileCount(dir, 3, t)
+}
+
+func TestSequenceCodec(t *testing.T) {
+	c := sequenceCodec{base: "foo.log"}
+	equals("foo.log.12", c.Encode(time.Now(), 12), t)
+
+	tests := []struct {
+		name string
+		seq  int
+		ok   bool
+	}{
+		{"foo.log.1", 1, true},
+		{"foo.log.12", 12, true},
+		{"foo.log.0", 0, false},
+		{"foo.log.01", 0, false},
+		{"foo.log.", 0, false},
+		{"foo.log.1.meta", 0, false},
+		{"foo.log", 0, false},
+		{"bar.log.1", 0, false},
+	}
+	for _, test := range tests {
+		_, seq, err := c.Decode(test.name)
+		equals(test.ok, err == nil, t)
+		equals(test.seq, seq, t)
+	}
+}
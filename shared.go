@@ -0,0 +1,60 @@
+package lumberjack
+
+import (
+	"os"
+)
+
+// shared reports whether other processes may append to the log file.
+func (l *Logger) shared() bool {
+	return l.SharedFile || l.SharedAppend
+}
+
+// syncSize updates the size of the log file with what is actually in it, if
+// it is shared with other processes that append to it.
+func (l *Logger) syncSize() {
+	if !l.shared() || l.file == nil {
+		return
+	}
+	if info, err := l.file.Stat(); err == nil {
+		l.size = info.Size()
+	}
+}
+
+// isCurrentFile reports whether the open log file is still the one Filename
+// refers to, rather than a backup it was moved to by another process.
+func (l *Logger) isCurrentFile() bool {
+	open, err := l.file.Stat()
+	if err != nil {
+		return true
+	}
+	named, err := os_Stat(l.activeFilename())
+	if err != nil {
+		// moved aside, and not created again yet.
+		return !os.IsNotExist(err)
+	}
+	return os.SameFile(open, named)
+}
+
+// followShared opens the log file again if another process sharing it with
+// SharedAppend rotated it, so that writes go to the new log file rather than
+// to the backup.
+func (l *Logger) followShared() error {
+	if !l.SharedAppend || l.file == nil || l.special || l.isCurrentFile() {
+		return nil
+	}
+	unlock, err := l.lockRotation()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return l.reopenShared()
+}
+
+// reopenShared opens the log file rotated by another process again, with the
+// rotation lock held.
+func (l *Logger) reopenShared() error {
+	if err := l.close(); err != nil {
+		return err
+	}
+	return l.openExistingOrNew(0)
+}
@@ -0,0 +1,103 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+)
+
+// appendExternally appends b to the named file like another process would.
+func appendExternally(name string, b []byte, t testing.TB) {
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	isNilUp(err, t, 1)
+	_, err = f.Write(b)
+	isNilUp(err, t, 1)
+	isNilUp(f.Close(), t, 1)
+}
+
+func TestExternalAppendNotOverwritten(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestExternalAppendNotOverwritten", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	// the Logger creates the file, so it isn't opened by the append path.
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	appendExternally(filename, []byte("ext!"), t)
+	_, err := l.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(filename, []byte("boo!ext!foo!"), t)
+}
+
+func TestSharedFile(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSharedFile", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		SharedFile: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	appendExternally(filename, []byte("ext!"), t)
+
+	// the other process's writes count towards MaxSize.
+	newFakeTime()
+	_, err := l.Write([]byte("foo!"))
+	isNil(err, t)
+	existsWithContent(backupFile(dir), []byte("boo!ext!"), t)
+	existsWithContent(filename, []byte("foo!"), t)
+	equals(int64(4), l.Stats().CurrentSize, t)
+}
+
+func TestSharedAppend(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSharedAppend", t)
+	defer os.RemoveAll(dir)
+
+	// two Loggers stand in for two processes.
+	filename := logFile(dir)
+	a := &Logger{Filename: filename, MaxSize: 10, SharedAppend: true}
+	defer a.Close()
+	b := &Logger{Filename: filename, MaxSize: 10, SharedAppend: true}
+	defer b.Close()
+
+	write := func(l *Logger, s string) {
+		_, err := l.Write([]byte(s))
+		isNilUp(err, t, 1)
+	}
+	write(a, "aaaaaa")
+	write(b, "bbb")
+	existsWithContent(filename, []byte("aaaaaabbb"), t)
+
+	// a rotates the log file, and b follows it to the new one rather than
+	// rotating it again, or writing to the backup.
+	newFakeTime()
+	write(a, "cc")
+	write(b, "dd")
+	existsWithContent(backupFile(dir), []byte("aaaaaabbb"), t)
+	existsWithContent(filename, []byte("ccdd"), t)
+	fileCount(dir, 3, t)
+
+	// a rotation by the other process at the same time doesn't overwrite
+	// the backup.
+	isNil(b.Rotate(), t)
+	isNil(a.Rotate(), t)
+	existsWithContent(backupFile(dir), []byte("aaaaaabbb"), t)
+	fileCount(dir, 4, t)
+}
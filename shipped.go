@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// shippedSuffix is appended to the name of the log file, prefixed with a dot,
+// to form the name of the file holding the shipped watermark.
+const shippedSuffix = ".shipped"
+
+// shippedMark is the content of the shipped watermark file, identifying the
+// newest backup that has been shipped.
+type shippedMark struct {
+	Timestamp time.Time `json:"timestamp"`
+	Seq       int       `json:"seq"`
+}
+
+// MarkShipped records that the named backup, and all the backups older than
+// it, have been shipped, so they may be removed when KeepUnshipped is set.
+// The name may be the base name of the backup or its full path.  The mark is
+// stored next to the log file, so it survives restarts, and it never moves
+// back: marking an older backup than the last one marked does nothing.
+func (l *Logger) MarkShipped(backupName string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := l.findBackup(backupName)
+	if err != nil {
+		return err
+	}
+	mark := shippedMark{Timestamp: f.timestamp, Seq: f.seq}
+	if old, ok := l.shippedMark(); ok && !old.before(mark) {
+		return nil
+	}
+
+	b, err := json.Marshal(mark)
+	if err != nil {
+		return fmt.Errorf("can't encode shipped mark: %s", err)
+	}
+	name := l.shippedName()
+	tmp := name + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0644); err != nil {
+		return fmt.Errorf("can't write shipped mark: %s", err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("can't write shipped mark: %s", err)
+	}
+	return nil
+}
+
+// shippedName returns the name of the shipped watermark file.
+func (l *Logger) shippedName() string {
+	return filepath.Join(l.dir(), "."+filepath.Base(l.filename())+shippedSuffix)
+}
+
+// shippedMark returns the shipped watermark, if there is one.
+func (l *Logger) shippedMark() (shippedMark, bool) {
+	var mark shippedMark
+	b, err := ioutil.ReadFile(l.shippedName())
+	if err != nil {
+		return mark, false
+	}
+	if err := json.Unmarshal(b, &mark); err != nil {
+		return mark, false
+	}
+	return mark, true
+}
+
+// shippedFilter returns a func reporting whether a backup has been shipped, or
+// nil if KeepUnshipped isn't set.
+func (l *Logger) shippedFilter() func(logInfo) bool {
+	if !l.KeepUnshipped {
+		return nil
+	}
+	mark, ok := l.shippedMark()
+	return func(f logInfo) bool {
+		return ok && !mark.before(shippedMark{Timestamp: f.timestamp, Seq: f.seq})
+	}
+}
+
+// before reports whether m identifies an older backup than o.
+func (m shippedMark) before(o shippedMark) bool {
+	if m.Timestamp.Equal(o.Timestamp) {
+		return m.Seq < o.Seq
+	}
+	return m.Timestamp.Before(o.Timestamp)
+}
@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestKeepUnshipped(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestKeepUnshipped", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       10,
+		MaxBackups:    1,
+		KeepUnshipped: true,
+	}
+	defer l.Close()
+
+	var backups []string
+	for i := 0; i < 3; i++ {
+		writeToCurrentLog(t, l, filename, []byte("boo!"))
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		backups = append(backups, backupFile(dir))
+	}
+
+	// we need to wait a little bit since the files get removed on a
+	// different goroutine.
+	<-time.After(10 * time.Millisecond)
+
+	// nothing has been shipped, so nothing is removed.
+	for _, b := range backups {
+		exists(b, t)
+	}
+	fileCount(dir, 4, t)
+
+	isNil(l.MarkShipped(filepath.Base(backups[1])), t)
+	// marking an older backup doesn't move the mark back.
+	isNil(l.MarkShipped(backups[0]), t)
+	notNil(l.MarkShipped("foo.log"), t)
+
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	<-time.After(10 * time.Millisecond)
+
+	// the shipped backups are removed, the others are kept.
+	notExist(backups[0], t)
+	notExist(backups[1], t)
+	exists(backups[2], t)
+	exists(backupFile(dir), t)
+	exists(l.shippedName(), t)
+	fileCount(dir, 4, t)
+}
+
+func TestManagerKeepsUnshipped(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestManagerKeepsUnshipped", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:      filename,
+		MaxSize:       10,
+		KeepUnshipped: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	backup := backupFile(dir)
+
+	m := &Manager{MaxTotalSize: 1}
+	m.Add(l)
+	megabyte = 0
+	defer func() { megabyte = 1 }()
+
+	isNil(m.Enforce(), t)
+	exists(backup, t)
+
+	isNil(l.MarkShipped(backup), t)
+	isNil(m.Enforce(), t)
+	notExist(backup, t)
+}
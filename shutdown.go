@@ -0,0 +1,87 @@
+package lumberjack
+
+import (
+	"context"
+)
+
+// Shutdown closes the log file like Close, and then waits until the cleanup
+// of old log files started so far is finished, i.e. until backups are
+// compressed, finalized and removed as configured, so that the process can
+// exit without leaving e.g. a partly compressed backup behind.  If ctx is done
+// first, Shutdown returns its error, and the cleanup goes on in the
+// background.
+func (l *Logger) Shutdown(ctx context.Context) error {
+	err := l.Close()
+	if errWait := l.waitMill(ctx); err == nil {
+		err = errWait
+	}
+	return err
+}
+
+// Shutdown writes everything written so far to the log file, and then shuts
+// down the Logger like Logger.Shutdown.
+func (a *AsyncLogger) Shutdown(ctx context.Context) error {
+	err := a.Close()
+	if errWait := a.Logger.waitMill(ctx); err == nil {
+		err = errWait
+	}
+	return err
+}
+
+// waitMill waits until the mill has finished the runs requested so far, or
+// until ctx is done.
+func (l *Logger) waitMill(ctx context.Context) error {
+	l.millMu.Lock()
+	if l.millPending == 0 {
+		l.millMu.Unlock()
+		return nil
+	}
+	if l.millIdle == nil {
+		l.millIdle = make(chan struct{})
+	}
+	idle := l.millIdle
+	l.millMu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Mill runs the cleanup of old log files, i.e. the compression, finalization
+// and removal of backups that Rotate and writes trigger in the background,
+// and waits until it is finished or ctx is done.  It returns the error of ctx,
+// or the first error of the cleanup since the last call to Mill.  This makes
+// it possible for tests and scripts to rely on the backups being cleaned up
+// without waiting for an arbitrary time.
+func (l *Logger) Mill(ctx context.Context) error {
+	l.mill()
+	if err := l.waitMill(ctx); err != nil {
+		return err
+	}
+	l.millMu.Lock()
+	defer l.millMu.Unlock()
+	err := l.millErr
+	l.millErr = nil
+	return err
+}
+
+// millFinished records the end of a mill run, which returned err, and reports
+// err to OnError.
+func (l *Logger) millFinished(err error) {
+	if err != nil && l.OnError != nil {
+		l.OnError(err)
+	}
+	l.millMu.Lock()
+	defer l.millMu.Unlock()
+	if l.millErr == nil {
+		l.millErr = err
+	}
+	l.millPending--
+	if l.millPending == 0 && l.millIdle != nil {
+		close(l.millIdle)
+		l.millIdle = nil
+	}
+}
@@ -0,0 +1,126 @@
+package lumberjack
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestShutdownWaitsForCompression(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestShutdownWaitsForCompression", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  10,
+		Compress: true,
+	}
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Shutdown(context.Background()), t)
+
+	// no need to wait, the backup is compressed by now.
+	notExist(backupFile(dir), t)
+	verifyCompressedFile(backupFile(dir), []byte("boo!"), t)
+
+	// with nothing left to do, it returns right away.
+	isNil(l.Shutdown(context.Background()), t)
+}
+
+func TestShutdownContextDone(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestShutdownContextDone", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:       filename,
+		MaxSize:        10,
+		Archiver:       &fakeArchiver{block: true},
+		ArchiveTimeout: 100 * time.Millisecond,
+	}
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	equals(context.DeadlineExceeded, l.Shutdown(ctx), t)
+
+	// the mill goes on, and finishes once the archiving times out.
+	isNil(l.Shutdown(context.Background()), t)
+}
+
+func TestAsyncShutdown(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestAsyncShutdown", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	a := NewAsyncLogger(&Logger{
+		Filename: filename,
+		MaxSize:  10,
+		Compress: true,
+	}, 0, Block)
+
+	_, err := a.Write([]byte("boo!"))
+	isNil(err, t)
+	newFakeTime()
+	isNil(a.Rotate(), t)
+	_, err = a.Write([]byte("foo!"))
+	isNil(err, t)
+	isNil(a.Shutdown(context.Background()), t)
+
+	existsWithContent(filename, []byte("foo!"), t)
+	verifyCompressedFile(backupFile(dir), []byte("boo!"), t)
+}
+
+func TestMill(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestMill", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	archiver := &fakeArchiver{}
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		MaxBackups: 1,
+		Compress:   true,
+		Archiver:   archiver,
+	}
+	defer l.Close()
+
+	// there is nothing to do yet.
+	isNil(l.Mill(context.Background()), t)
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	verifyCompressedFile(backupFile(dir), []byte("boo!"), t)
+	notNil(archiver.content(backupFile(dir)+compressSuffix), t)
+
+	// the error of the cleanup is returned.
+	archiver.err = errors.New("unreachable")
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	notNil(l.Mill(context.Background()), t)
+	fileCount(dir, 2, t)
+}
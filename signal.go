@@ -0,0 +1,48 @@
+package lumberjack
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// RotateOnSignal makes the Logger rotate the log file like Rotate whenever
+// the process receives one of the given signals, or SIGHUP if none are given,
+// which is how logrotate and many daemons ask for log files to be reopened.
+// It replaces the signals of an earlier call.  The signals are no longer
+// handled once Close is called, until RotateOnSignal is called again.  Errors
+// rotating the log file are left to be reported by the next Write.
+func (l *Logger) RotateOnSignal(sigs ...os.Signal) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopSignals()
+
+	c := make(chan os.Signal, 1)
+	stop := make(chan struct{})
+	signal.Notify(c, sigs...)
+	l.signals, l.signalStop = c, stop
+	go func() {
+		for {
+			select {
+			case <-c:
+				_ = l.Rotate()
+			case <-stop:
+				return
+			}
+		}
+	}()
+}
+
+// stopSignals stops handling the signals given to RotateOnSignal.  It is
+// called with the Logger locked.
+func (l *Logger) stopSignals() {
+	if l.signals == nil {
+		return
+	}
+	signal.Stop(l.signals)
+	close(l.signalStop)
+	l.signals, l.signalStop = nil, nil
+}
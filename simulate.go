@@ -0,0 +1,115 @@
+package lumberjack
+
+import (
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Plan is the outcome of applying retention settings to a set of backups, as
+// returned by SimulateRetention.
+type Plan struct {
+	// Keep holds the backups that would be kept, newest first.
+	Keep []BackupInfo
+
+	// Remove holds the backups that would be removed.
+	Remove []BackupInfo
+
+	// Compress holds the kept backups that would be compressed.
+	Compress []BackupInfo
+
+	// Files is the number of backups that would be kept.
+	Files int
+
+	// Bytes is the total size of the backups that would be kept.  Backups
+	// that would be compressed are counted at their current size, so this is
+	// an upper bound when compression is enabled.
+	Bytes int64
+
+	// PeakBytes is Bytes plus the maximum size of the current log file,
+	// which is the most disk space the Logger would use.
+	PeakBytes int64
+}
+
+// SimulateRetention applies the retention settings in cfg to the given
+// history of backups, without touching any files, and returns what would be
+// kept, removed and compressed.  The history is evaluated as of its newest
+// backup, i.e. right after the latest rotation, so feeding it the rotation
+// pattern of an existing deployment projects the effect of new MaxSize,
+// MaxAge, MaxBackups, KeepDaily/KeepWeekly and MaxTotalSize values before
+// rolling them out.
+func SimulateRetention(history []BackupInfo, cfg Config) Plan {
+	files := make([]logInfo, len(history))
+	for i, b := range history {
+		files[i] = logInfo{
+			timestamp: b.Timestamp,
+			dir:       filepath.Dir(b.Path),
+			FileInfo:  backupFileInfo{b},
+		}
+	}
+	sort.Sort(byFormatTime(files))
+
+	var plan Plan
+	if len(files) == 0 {
+		plan.PeakBytes = cfg.max()
+		return plan
+	}
+
+	loc := time.UTC
+	if cfg.LocalTime {
+		loc = time.Local
+	}
+	r := retention{
+		maxBackups:           cfg.MaxBackups,
+		maxAge:               maxAge(cfg.MaxAge, cfg.MaxAgeDuration),
+		compress:             cfg.Compress,
+		keepLastDecompressed: cfg.KeepLastDecompressed,
+		keepDaily:            cfg.KeepDaily,
+		keepWeekly:           cfg.KeepWeekly,
+		keepAllFor:           cfg.KeepAllFor,
+		loc:                  loc,
+	}
+	remove, compress := r.apply(files, files[0].timestamp)
+
+	removed := make(map[string]bool, len(remove))
+	for _, f := range remove {
+		removed[f.Name()] = true
+	}
+	if cfg.MaxTotalSize > 0 {
+		// the oldest backups go until they fit, as in the cleanup right after
+		// the rotation, while the new log file is still empty.
+		var total int64
+		for _, f := range files {
+			if !removed[f.Name()] {
+				total += f.Size()
+			}
+		}
+		budget := int64(cfg.MaxTotalSize) * int64(megabyte)
+		for i := len(files) - 1; i >= 0 && total > budget; i-- {
+			if f := files[i]; !removed[f.Name()] {
+				removed[f.Name()] = true
+				remove = append(remove, f)
+				total -= f.Size()
+			}
+		}
+	}
+	for _, f := range remove {
+		plan.Remove = append(plan.Remove, f.FileInfo.(backupFileInfo).b)
+	}
+	for _, f := range compress {
+		if !removed[f.Name()] {
+			plan.Compress = append(plan.Compress, f.FileInfo.(backupFileInfo).b)
+		}
+	}
+	for _, f := range files {
+		if removed[f.Name()] {
+			continue
+		}
+		b := f.FileInfo.(backupFileInfo).b
+		plan.Keep = append(plan.Keep, b)
+		plan.Files++
+		plan.Bytes += b.Size
+	}
+	plan.PeakBytes = plan.Bytes + cfg.max()
+	return plan
+}
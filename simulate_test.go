@@ -0,0 +1,101 @@
+package lumberjack
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestSimulateRetention(t *testing.T) {
+	megabyte = 1
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// a backup every 12 hours over 5 days.
+	var history []BackupInfo
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * 12 * time.Hour)
+		history = append(history, BackupInfo{
+			Name:      fmt.Sprintf("foo-%s.log", ts.Format(DefaultTimeFormat)),
+			Timestamp: ts,
+			Size:      100,
+		})
+	}
+
+	plan := SimulateRetention(history, Config{MaxSize: 10, MaxBackups: 6})
+	equals(6, plan.Files, t)
+	equals(4, len(plan.Remove), t)
+	equals(int64(600), plan.Bytes, t)
+	equals(int64(610), plan.PeakBytes, t)
+	equals(history[9], plan.Keep[0], t)
+	equals(0, len(plan.Compress), t)
+
+	// two days of history as of the newest backup.
+	plan = SimulateRetention(history, Config{MaxSize: 10, MaxAge: 2, Compress: true, KeepLastDecompressed: 1})
+	equals(5, plan.Files, t)
+	equals(history[5], plan.Keep[4], t)
+	equals(4, len(plan.Compress), t)
+
+	plan = SimulateRetention(nil, Config{MaxSize: 10})
+	equals(0, plan.Files, t)
+	equals(int64(10), plan.PeakBytes, t)
+}
+
+func TestSimulateCalendarRetention(t *testing.T) {
+	megabyte = 1
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// a backup a day over 10 days.
+	var history []BackupInfo
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * 24 * time.Hour)
+		history = append(history, BackupInfo{
+			Name:      fmt.Sprintf("foo-%s.log", ts.Format(DefaultTimeFormat)),
+			Timestamp: ts,
+			Size:      100,
+		})
+	}
+
+	// the newest backup of each of the last 2 days.
+	plan := SimulateRetention(history, Config{MaxSize: 10, KeepDaily: 2})
+	equals(2, plan.Files, t)
+	equals(8, len(plan.Remove), t)
+	equals(history[9], plan.Keep[0], t)
+	equals(history[8], plan.Keep[1], t)
+
+	// Monday 2020-01-06 starts the newest week, and the week before ends on
+	// Sunday 2020-01-05.
+	plan = SimulateRetention(history, Config{MaxSize: 10, KeepWeekly: 2})
+	equals(2, plan.Files, t)
+	equals(history[9], plan.Keep[0], t)
+	equals(history[4], plan.Keep[1], t)
+}
+
+func TestSimulateTotalSize(t *testing.T) {
+	megabyte = 1
+	start := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	var history []BackupInfo
+	for i := 0; i < 10; i++ {
+		ts := start.Add(time.Duration(i) * 24 * time.Hour)
+		history = append(history, BackupInfo{
+			Name:      fmt.Sprintf("foo-%s.log", ts.Format(DefaultTimeFormat)),
+			Timestamp: ts,
+			Size:      100,
+		})
+	}
+
+	// the oldest backups go until the rest fit in MaxTotalSize.
+	plan := SimulateRetention(history, Config{MaxSize: 10, MaxTotalSize: 350, Compress: true})
+	equals(3, plan.Files, t)
+	equals(int64(300), plan.Bytes, t)
+	equals(int64(310), plan.PeakBytes, t)
+	equals(7, len(plan.Remove), t)
+	equals(history[7], plan.Keep[2], t)
+	equals(3, len(plan.Compress), t)
+
+	// on top of the other settings.
+	plan = SimulateRetention(history, Config{MaxSize: 10, MaxTotalSize: 150, KeepDaily: 2})
+	equals(1, plan.Files, t)
+	equals(history[9], plan.Keep[0], t)
+	equals(9, len(plan.Remove), t)
+}
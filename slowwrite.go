@@ -0,0 +1,80 @@
+package lumberjack
+
+import (
+	"time"
+)
+
+// The steps of a Write reported in SlowWrite.
+const (
+	SlowWriteOpen   = "open"
+	SlowWriteRotate = "rotate"
+	SlowWriteWrite  = "write"
+)
+
+// SlowWrite describes a Write that took longer than the Logger's
+// SlowWriteThreshold.
+type SlowWrite struct {
+	// Filename is the log file that was written to.
+	Filename string
+
+	// Op is the step of the Write that took the longest, one of
+	// SlowWriteOpen, SlowWriteRotate or SlowWriteWrite.
+	Op string
+
+	// Duration is the total time taken by the Write.
+	Duration time.Duration
+
+	// Steps holds the time taken by each step of the Write, keyed by Op.
+	Steps map[string]time.Duration
+}
+
+// writeTimer measures the steps of a single Write.  A nil writeTimer measures
+// nothing, so slow write detection costs nothing when it is disabled.
+type writeTimer struct {
+	start time.Time
+	steps map[string]time.Duration
+}
+
+// newWriteTimer returns a timer for a Write, or nil if slow write detection is
+// disabled.
+func (l *Logger) newWriteTimer() *writeTimer {
+	if l.SlowWriteThreshold <= 0 || l.OnSlowWrite == nil {
+		return nil
+	}
+	return &writeTimer{start: time.Now(), steps: make(map[string]time.Duration, 3)}
+}
+
+// begin returns the start time of a step.
+func (w *writeTimer) begin() time.Time {
+	if w == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// end records the time taken by the given step since start.
+func (w *writeTimer) end(op string, start time.Time) {
+	if w == nil {
+		return
+	}
+	w.steps[op] += time.Since(start)
+}
+
+// report calls the Logger's OnSlowWrite if the Write took longer than its
+// SlowWriteThreshold.
+func (w *writeTimer) report(l *Logger) {
+	if w == nil {
+		return
+	}
+	d := time.Since(w.start)
+	if d < l.SlowWriteThreshold {
+		return
+	}
+	ev := SlowWrite{Filename: l.filename(), Duration: d, Steps: w.steps}
+	for op, stepDuration := range w.steps {
+		if ev.Op == "" || stepDuration > w.steps[ev.Op] {
+			ev.Op = op
+		}
+	}
+	l.OnSlowWrite(ev)
+}
@@ -0,0 +1,54 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSlowWrite(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSlowWrite", t)
+	defer os.RemoveAll(dir)
+
+	var events []SlowWrite
+	l := &Logger{
+		Filename:           logFile(dir),
+		MaxSize:            10,
+		SlowWriteThreshold: time.Nanosecond,
+		OnSlowWrite: func(ev SlowWrite) {
+			events = append(events, ev)
+		},
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+
+	equals(1, len(events), t)
+	equals(logFile(dir), events[0].Filename, t)
+	_, opened := events[0].Steps[SlowWriteOpen]
+	assert(opened, t, "expected the open step to be timed")
+	_, wrote := events[0].Steps[SlowWriteWrite]
+	assert(wrote, t, "expected the write step to be timed")
+	assert(events[0].Op != "", t, "expected the slowest step to be reported")
+
+	newFakeTime()
+	b2 := []byte("foooooo!")
+	n, err = l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+
+	equals(2, len(events), t)
+	_, rotated := events[1].Steps[SlowWriteRotate]
+	assert(rotated, t, "expected the rotate step to be timed")
+}
+
+func TestSlowWriteDisabled(t *testing.T) {
+	l := &Logger{OnSlowWrite: func(SlowWrite) {}}
+	assert(l.newWriteTimer() == nil, t, "expected no timer without a threshold")
+}
@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLowDiskSpace is returned by Write when RejectLowSpaceWrites is set and
+// there is less free space than MinFreeSpace or MinFreePercent.
+var ErrLowDiskSpace = errors.New("lumberjack: not enough free disk space")
+
+// spaceCheckInterval is the minimum time between checks of the free space.
+const spaceCheckInterval = time.Second
+
+// checkSpace checks the free space on the filesystem holding the log file, at
+// most once every spaceCheckInterval, and removes the oldest backups if there
+// isn't enough.  It returns ErrLowDiskSpace if there still isn't and writes
+// are to be rejected.
+func (l *Logger) checkSpace() error {
+	if l.MinFreeSpace <= 0 && l.MinFreePercent <= 0 {
+		return nil
+	}
+	now := l.now()
+	if elapsed := now.Sub(l.spaceChecked); elapsed >= spaceCheckInterval || elapsed < 0 {
+		l.spaceChecked = now
+		l.spaceLow = l.lowSpace(l.dir()) && l.freeSpace()
+	}
+	if l.spaceLow && l.RejectLowSpaceWrites {
+		return ErrLowDiskSpace
+	}
+	return nil
+}
+
+// lowSpace reports whether there is less free space than MinFreeSpace or
+// MinFreePercent on the filesystem holding dir.  It reports false if the free
+// space can't be found out.
+func (l *Logger) lowSpace(dir string) bool {
+	free, used, ok := statDisk(dir)
+	if !ok {
+		return false
+	}
+	if l.MinFreeSpace > 0 && free < int64(l.MinFreeSpace)*int64(megabyte) {
+		return true
+	}
+	total := free + used
+	return l.MinFreePercent > 0 && total > 0 && float64(free)*100 < l.MinFreePercent*float64(total)
+}
+
+// freeSpace removes the oldest backups until there is enough free space on
+// the filesystem holding the log file, and reports whether there still isn't.
+// Backups in directories with enough free space are on another filesystem,
+// so they are left alone.  Errors are reported to OnError, since this
+// happens on the way to writing to the log file.
+func (l *Logger) freeSpace() bool {
+	if l.AuditMode {
+		return true
+	}
+	if l.SequentialBackups {
+		l.seqMu.Lock()
+		defer l.seqMu.Unlock()
+	}
+	files, err := l.oldLogFiles()
+	if err != nil {
+		l.spaceError(err)
+		return true
+	}
+	// backups are sorted newest first.
+	for i := len(files) - 1; i >= 0; i-- {
+		f := files[i]
+		name := filepath.Join(f.dir, f.Name())
+		if l.isReading(name) || !l.lowSpace(f.dir) {
+			continue
+		}
+		if err := l.remove(name); err != nil && !os.IsNotExist(err) {
+			l.spaceError(err)
+			continue
+		}
+		if !l.lowSpace(l.dir()) {
+			return false
+		}
+	}
+	return true
+}
+
+// spaceError reports an error freeing space to OnError.
+func (l *Logger) spaceError(err error) {
+	if l.OnError != nil {
+		l.OnError(fmt.Errorf("can't free disk space: %s", err))
+	}
+}
@@ -0,0 +1,106 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+// fakeDisk mocks statDisk with a filesystem of the given capacity holding
+// nothing but the files in dir.
+func fakeDisk(dir string, capacity int64) {
+	statDisk = func(string) (int64, int64, bool) {
+		var used int64
+		infos, _ := ioutil.ReadDir(dir)
+		for _, info := range infos {
+			used += info.Size()
+		}
+		return capacity - used, used, true
+	}
+}
+
+func TestMinFreeSpace(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer func() { statDisk = diskUsage }()
+
+	dir := makeTempDir("TestMinFreeSpace", t)
+	defer os.RemoveAll(dir)
+
+	var backups []string
+	for i := 0; i < 3; i++ {
+		backup := backupFile(dir)
+		isNil(ioutil.WriteFile(backup, []byte("0123456789"), 0644), t)
+		backups = append(backups, backup)
+		newFakeTime()
+	}
+	fakeDisk(dir, 45)
+
+	filename := logFile(dir)
+	var removed []string
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      10,
+		MinFreeSpace: 20,
+		OnRemove:     func(name string) { removed = append(removed, name) },
+	}
+	defer l.Close()
+
+	// 15 bytes are free, so the oldest backup makes way.
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	existsWithContent(filename, b, t)
+	notExist(backups[0], t)
+	exists(backups[1], t)
+	exists(backups[2], t)
+	equals([]string{backups[0]}, removed, t)
+	equals(int64(1), l.Stats().Removed, t)
+}
+
+func TestRejectLowSpaceWrites(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer func() { statDisk = diskUsage }()
+
+	dir := makeTempDir("TestRejectLowSpaceWrites", t)
+	defer os.RemoveAll(dir)
+
+	backup := backupFile(dir)
+	isNil(ioutil.WriteFile(backup, []byte("0123456789"), 0644), t)
+	newFakeTime()
+	fakeDisk(dir, 100)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              10,
+		MinFreePercent:       95,
+		RejectLowSpaceWrites: true,
+	}
+	defer l.Close()
+
+	// removing the backup frees enough space.
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	notExist(backup, t)
+
+	// writes are rejected once there is nothing left to remove, and the
+	// free space isn't checked again for a second.
+	fakeDisk(dir, 10)
+	_, err = l.Write(b)
+	isNil(err, t)
+	fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	_, err = l.Write(b)
+	equals(ErrLowDiskSpace, err, t)
+	existsWithContent(filename, append(b, b...), t)
+	equals(int64(1), l.Stats().WriteErrors, t)
+
+	fakeDisk(dir, 1000)
+	fakeCurrentTime = fakeCurrentTime.Add(time.Second)
+	_, err = l.Write(b)
+	isNil(err, t)
+}
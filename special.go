@@ -0,0 +1,34 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+)
+
+// specialModes are the file types that are written to as they are, without
+// any rotation.
+const specialModes = os.ModeDevice | os.ModeCharDevice | os.ModeNamedPipe | os.ModeSocket
+
+// openSpecial opens the log file if it is a device, a pipe or a socket, such
+// as /dev/stdout in a container.  Such files can't be rotated, so the Logger
+// just passes writes through to them.  It reports whether the log file is
+// special.
+func (l *Logger) openSpecial() (bool, error) {
+	// the names of devices on Windows are rejected rather than opened.
+	if err := l.checkPaths(); err != nil {
+		return false, err
+	}
+	name := l.filename()
+	info, err := os_Stat(name)
+	if err != nil || info.Mode()&specialModes == 0 {
+		return false, nil
+	}
+	f, err := os.OpenFile(name, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		return true, fmt.Errorf("can't open special log file: %s", err)
+	}
+	l.file = f
+	l.size = 0
+	l.special = true
+	return true, nil
+}
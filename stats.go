@@ -0,0 +1,199 @@
+package lumberjack
+
+import (
+	"time"
+)
+
+// statDisk is a var so we can mock it out during tests.
+var statDisk = diskUsage
+
+// Stats holds statistics about the writes made by a Logger, and the work it
+// does to manage its log files.  The counters only ever go up, so they can be
+// exported as e.g. Prometheus counters by a collector calling Stats when it is
+// scraped.  The summary of the backups reflects the backups on the local disk
+// when Stats is called, and can be exported as gauges to alert on retention
+// that doesn't work as expected.
+type Stats struct {
+	// Writes is the number of calls to Write since the Logger was created.
+	Writes int64
+
+	// BytesWritten is the number of bytes written since the Logger was
+	// created.
+	BytesWritten int64
+
+	// BytesSinceRotation is the number of bytes written since the current log
+	// file was created, either by a rotation or because there was no log file
+	// yet.  It doesn't include anything in the log file from before it was
+	// opened by this Logger.
+	BytesSinceRotation int64
+
+	// PhysicalWrites and PhysicalBytes are the number of writes made to the
+	// log file and the number of bytes they wrote since the Logger was
+	// created.  Comparing them to Writes and BytesWritten shows how much
+	// buffering reduces the I/O on the storage.
+	PhysicalWrites int64
+	PhysicalBytes  int64
+
+	// Dropped is the number of writes an AsyncLogger dropped because its
+	// buffer was full.
+	Dropped int64
+
+	// WriteErrors is the number of writes to the log file that failed.
+	WriteErrors int64
+
+	// Syncs is the number of times the log file was committed to stable
+	// storage, by Sync or because of SyncPolicy.
+	Syncs int64
+
+	// Filtered is the number of writes dropped by WriteFilter.
+	Filtered int64
+
+	// RateLimited is the number of writes dropped or rejected because of
+	// MaxBytesPerSecond.
+	RateLimited int64
+
+	// RenameCopies is the number of rotations that copied and truncated the
+	// log file because of RenameFallbackCopy.
+	RenameCopies int64
+
+	// CurrentSize is the size of the current log file as far as the Logger
+	// knows, including anything in it from before it was opened.
+	CurrentSize int64
+
+	// Rotations is the number of times the log file was rotated.
+	Rotations int64
+
+	// Compressions is the number of backups compressed, and CompressionTime
+	// the total time it took to compress them.
+	Compressions    int64
+	CompressionTime time.Duration
+
+	// Removed is the number of backups removed by the cleanup of old log
+	// files.
+	Removed int64
+
+	// Compacted is the number of backups merged into combined backups
+	// because of CompactBelow.
+	Compacted int64
+
+	// Deduplicated is the number of backups removed because of DedupBackups
+	// for being identical to the backup before them.
+	Deduplicated int64
+
+	// Backups is the number of backups, of which CompressedBackups are
+	// compressed, and BackupBytes their total size.
+	Backups           int
+	CompressedBackups int
+	BackupBytes       int64
+
+	// OldestBackupAge and NewestBackupAge are the time since the oldest and
+	// the newest backups were rotated out, or 0 if there are no backups.
+	OldestBackupAge time.Duration
+	NewestBackupAge time.Duration
+
+	// BackupDirFree and BackupDirUsed are the space available and the space
+	// used on the filesystem holding the backups, or of the first of
+	// BackupDirs, in bytes.  They are 0 where the platform can't report them.
+	BackupDirFree int64
+	BackupDirUsed int64
+}
+
+// WriteAmplification returns the number of writes made to the log file per
+// call to Write, or 0 if Write hasn't been called.  Values below 1 mean that
+// writes were coalesced.
+func (s Stats) WriteAmplification() float64 {
+	if s.Writes == 0 {
+		return 0
+	}
+	return float64(s.PhysicalWrites) / float64(s.Writes)
+}
+
+// CompressedFraction returns the fraction of the backups that are compressed,
+// or 0 if there are no backups.
+func (s Stats) CompressedFraction() float64 {
+	if s.Backups == 0 {
+		return 0
+	}
+	return float64(s.CompressedBackups) / float64(s.Backups)
+}
+
+// Stats returns statistics about the writes made by the Logger, and a summary
+// of its backups.
+func (l *Logger) Stats() Stats {
+	l.mu.Lock()
+	l.rotatedMu.Lock()
+	s := l.stats
+	s.CurrentSize = l.size
+	l.rotatedMu.Unlock()
+	l.mu.Unlock()
+
+	// the backup directories are read without the Logger locked, so that
+	// writes don't wait for them.
+	l.summarizeBackups(&s)
+	s.BackupDirFree, s.BackupDirUsed, _ = statDisk(l.backupDirs()[0])
+	return s
+}
+
+// summarizeBackups fills in the summary of the backups in s.  Backups that
+// can't be listed are left out.
+func (l *Logger) summarizeBackups(s *Stats) {
+	files, err := l.oldLogFiles()
+	if err != nil || len(files) == 0 {
+		return
+	}
+	for _, f := range files {
+		s.Backups++
+		s.BackupBytes += f.Size()
+		if isCompressed(f.Name()) {
+			s.CompressedBackups++
+		}
+	}
+	now := l.now()
+	s.NewestBackupAge = now.Sub(files[0].timestamp)
+	s.OldestBackupAge = now.Sub(files[len(files)-1].timestamp)
+}
+
+// countBytes adds n written bytes to the statistics.
+func (l *Logger) countBytes(n int) {
+	l.stats.BytesWritten += int64(n)
+	l.stats.BytesSinceRotation += int64(n)
+}
+
+// countPhysical records a write of n bytes to the log file.
+func (l *Logger) countPhysical(n int) {
+	l.stats.PhysicalWrites++
+	l.stats.PhysicalBytes += int64(n)
+}
+
+// countCompression records the compression of a backup that took d.  It is
+// called by the mill, without the Logger locked.
+func (l *Logger) countCompression(d time.Duration) {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	l.stats.Compressions++
+	l.stats.CompressionTime += d
+}
+
+// countCompacted records the merging of n backups into one.  It is called by
+// the mill, without the Logger locked.
+func (l *Logger) countCompacted(n int) {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	l.stats.Compacted += int64(n)
+}
+
+// countDeduplicated records the removal of a duplicate backup.  It is called
+// by the mill, without the Logger locked.
+func (l *Logger) countDeduplicated() {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	l.stats.Deduplicated++
+}
+
+// countRemoved records the removal of a backup.  It is called by the mill,
+// without the Logger locked.
+func (l *Logger) countRemoved() {
+	l.rotatedMu.Lock()
+	defer l.rotatedMu.Unlock()
+	l.stats.Removed++
+}
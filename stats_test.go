@@ -0,0 +1,188 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestStatsBytes(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	// the disk usage changes under the test, so it isn't reported.
+	statDisk = func(string) (int64, int64, bool) { return 0, 0, false }
+	defer func() { statDisk = diskUsage }()
+	// backup names only hold milliseconds, which the ages are computed from.
+	fakeCurrentTime = fakeCurrentTime.Truncate(time.Millisecond)
+
+	dir := makeTempDir("TestStatsBytes", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{
+		Filename: logFile(dir),
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	_, err := l.Write(b)
+	isNil(err, t)
+	_, err = l.Write(b)
+	isNil(err, t)
+	equals(Stats{Writes: 2, BytesWritten: 8, BytesSinceRotation: 8, PhysicalWrites: 2, PhysicalBytes: 8, CurrentSize: 8}, l.Stats(), t)
+
+	newFakeTime()
+
+	// this will make us rotate.
+	b2 := []byte("foooooo!")
+	_, err = l.Write(b2)
+	isNil(err, t)
+	equals(Stats{Writes: 3, BytesWritten: 16, BytesSinceRotation: 8, PhysicalWrites: 3, PhysicalBytes: 16, CurrentSize: 8, Rotations: 1, Backups: 1, BackupBytes: 8}, l.Stats(), t)
+
+	isNil(l.Rotate(), t)
+	equals(Stats{Writes: 3, BytesWritten: 16, BytesSinceRotation: 0, PhysicalWrites: 3, PhysicalBytes: 16, Rotations: 2, Backups: 2, BackupBytes: 16}, l.Stats(), t)
+}
+
+func TestStatsWriteAmplification(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestStatsWriteAmplification", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{
+		Filename: logFile(dir),
+		MaxSize:  10,
+	}
+	defer l.Close()
+
+	equals(0.0, l.Stats().WriteAmplification(), t)
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	equals(1.0, l.Stats().WriteAmplification(), t)
+
+	// a write that is too long doesn't reach the log file.
+	_, err = l.Write([]byte("this is too long"))
+	notNil(err, t)
+	equals(0.5, l.Stats().WriteAmplification(), t)
+}
+
+func TestStatsMill(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestStatsMill", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		MaxBackups: 1,
+		Compress:   true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+
+	stats := l.Stats()
+	equals(int64(2), stats.Rotations, t)
+	equals(int64(2), stats.Compressions, t)
+	assert(stats.CompressionTime > 0, t, "expected the compression time to be recorded, got %v", stats)
+	equals(int64(1), stats.Removed, t)
+
+	_, err := l.Write([]byte("this is too long"))
+	notNil(err, t)
+	equals(int64(1), l.Stats().WriteErrors, t)
+}
+
+func TestStatsBackupSummary(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	// backup names only hold milliseconds, which the ages are computed from.
+	fakeCurrentTime = fakeCurrentTime.Truncate(time.Millisecond)
+
+	dir := makeTempDir("TestStatsBackupSummary", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              10,
+		Compress:             true,
+		KeepLastDecompressed: 1,
+	}
+	defer l.Close()
+
+	stats := l.Stats()
+	equals(0, stats.Backups, t)
+	equals(0.0, stats.CompressedFraction(), t)
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	writeToCurrentLog(t, l, filename, []byte("foo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	isNil(l.Mill(context.Background()), t)
+	newest := backupFile(dir)
+	newFakeTime()
+
+	// the older backup is compressed, the newest isn't.
+	stats = l.Stats()
+	equals(2, stats.Backups, t)
+	equals(1, stats.CompressedBackups, t)
+	equals(0.5, stats.CompressedFraction(), t)
+	fi, err := os.Stat(newest)
+	isNil(err, t)
+	assert(stats.BackupBytes > fi.Size(), t, "expected the size of both backups, got %d", stats.BackupBytes)
+	equals(2*24*time.Hour, stats.NewestBackupAge, t)
+	equals(4*24*time.Hour, stats.OldestBackupAge, t)
+}
+
+func TestStatsBackupDirUsage(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestStatsBackupDirUsage", t)
+	defer os.RemoveAll(dir)
+	backups := filepath.Join(dir, "backups")
+
+	var statted []string
+	statDisk = func(dir string) (int64, int64, bool) {
+		statted = append(statted, dir)
+		return 1000, 3000, true
+	}
+	defer func() { statDisk = diskUsage }()
+
+	filename := logFile(dir)
+	var rotation RotationInfo
+	l := &Logger{
+		Filename:  filename,
+		MaxSize:   10,
+		BackupDir: backups,
+		OnRotate:  func(info RotationInfo) { rotation = info },
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	equals(int64(1000), rotation.BackupDirFree, t)
+	equals(int64(3000), rotation.BackupDirUsed, t)
+
+	stats := l.Stats()
+	equals(int64(1000), stats.BackupDirFree, t)
+	equals(int64(3000), stats.BackupDirUsed, t)
+	equals([]string{backups, backups}, statted, t)
+}
@@ -0,0 +1,26 @@
+package lumberjack_test
+
+import (
+	"testing"
+
+	"github.com/jfrog/lumberjack/v2"
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func TestStress(t *testing.T) {
+	lumberjack.RestoreGlobals()
+
+	cfg := lumberjacktest.StressConfig{Writers: 4, Writes: 2000, Size: 512, Rotations: 20, Crashes: 3}
+	result := lumberjacktest.Stress(t, cfg, func(filename string) lumberjacktest.StressLogger {
+		return &lumberjack.Logger{Filename: filename, MaxSize: 1, Compress: true, RestartMarker: true}
+	})
+	if result.Files < 20 {
+		t.Fatalf("expected the records to be spread over the backups, got %d files", result.Files)
+	}
+
+	// renumbering backups mustn't lose any either.
+	cfg = lumberjacktest.StressConfig{Writers: 4, Writes: 500, Rotations: 30, Crashes: 2}
+	lumberjacktest.Stress(t, cfg, func(filename string) lumberjacktest.StressLogger {
+		return &lumberjack.Logger{Filename: filename, SequentialBackups: true, BackupDir: filename + ".d"}
+	})
+}
@@ -0,0 +1,80 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// isCurrentLink reports whether name, the file Filename points to, is a log
+// file created because of SymlinkCurrent, which is already named like a
+// backup, so it doesn't need to be moved aside to rotate it.
+func (l *Logger) isCurrentLink(name string) bool {
+	if !l.SymlinkCurrent {
+		return false
+	}
+	if info, err := os.Lstat(l.filename()); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return false
+	}
+	_, _, err := l.codec().Decode(filepath.Base(name))
+	return err == nil
+}
+
+// newCurrentName returns the name of a new log file for SymlinkCurrent, a
+// backup name for the current time, and makes its directory.
+func (l *Logger) newCurrentName() (string, error) {
+	if err := l.checkTimeFormat(); err != nil {
+		return "", err
+	}
+	name := l.layoutName(l.backupName(l.LocalTime))
+	if err := l.mkdirAll(filepath.Dir(name)); err != nil {
+		return "", fmt.Errorf("can't make directories for new logfile: %s", err)
+	}
+	l.rotatedMu.Lock()
+	l.current = name
+	l.rotatedMu.Unlock()
+	return name, nil
+}
+
+// linkCurrent points Filename at the new log file with the given name.  The
+// link is replaced in one step, so Filename always refers to a log file.
+func (l *Logger) linkCurrent(name string) error {
+	link := l.filename()
+	if err := l.mkdirAll(filepath.Dir(link)); err != nil {
+		return fmt.Errorf("can't make directories for log file link: %s", err)
+	}
+	target := name
+	if rel, err := filepath.Rel(filepath.Dir(link), name); err == nil {
+		target = rel
+	}
+	tmp := link + ".tmp"
+	_ = os.Remove(tmp)
+	if err := os.Symlink(target, tmp); err != nil {
+		return fmt.Errorf("can't link log file: %s", err)
+	}
+	if err := os.Rename(tmp, link); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("can't link log file: %s", err)
+	}
+	return nil
+}
+
+// currentFilter returns a func reporting whether a file found among the
+// backups is really the log file, which has a backup name with
+// SymlinkCurrent, or nil if SymlinkCurrent isn't set.
+func (l *Logger) currentFilter() func(name string, info os.FileInfo) bool {
+	if !l.SymlinkCurrent {
+		return nil
+	}
+	l.rotatedMu.Lock()
+	current := l.current
+	l.rotatedMu.Unlock()
+	// the log file may have been created by another Logger or run.
+	active, _ := os_Stat(l.filename())
+	return func(name string, info os.FileInfo) bool {
+		if current != "" && filepath.Clean(name) == filepath.Clean(current) {
+			return true
+		}
+		return active != nil && os.SameFile(active, info)
+	}
+}
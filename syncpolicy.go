@@ -0,0 +1,96 @@
+package lumberjack
+
+import (
+	"fmt"
+	"time"
+)
+
+// defaultSyncInterval is the time between a write and the sync of the log
+// file with SyncPeriodic if SyncInterval isn't set.
+const defaultSyncInterval = time.Second
+
+// SyncPolicy determines when a Logger commits the log file to stable storage
+// with File.Sync, so that it survives a crash of the operating system or a
+// power loss.
+type SyncPolicy string
+
+const (
+	// SyncNone leaves it to the operating system, like the default ("").
+	SyncNone SyncPolicy = "none"
+
+	// SyncAlways syncs the log file after every write to it, which is the
+	// safest and the slowest.  A write that fails to sync returns the error,
+	// even though it was written.
+	SyncAlways SyncPolicy = "always"
+
+	// SyncPeriodic syncs the log file SyncInterval after a write to it, and
+	// when it is rotated or closed, so at most SyncInterval worth of writes
+	// can be lost.  Errors are reported to OnError.
+	SyncPeriodic SyncPolicy = "interval"
+
+	// SyncOnRotate syncs each log file once it is complete, when it is
+	// rotated, so only the writes to the current log file can be lost.
+	SyncOnRotate SyncPolicy = "on-rotate"
+)
+
+// valid reports whether p is one of the known policies.
+func (p SyncPolicy) valid() bool {
+	switch p {
+	case "", SyncNone, SyncAlways, SyncPeriodic, SyncOnRotate:
+		return true
+	}
+	return false
+}
+
+// syncWritten commits a write that was just made to the log file according
+// to SyncPolicy.
+func (l *Logger) syncWritten() error {
+	switch l.SyncPolicy {
+	case SyncAlways:
+		return l.sync()
+	case SyncPeriodic:
+		if l.syncTimer == nil {
+			interval := l.SyncInterval
+			if interval <= 0 {
+				interval = defaultSyncInterval
+			}
+			l.syncTimer = l.scheduleWork(WorkSync, interval, l.syncDue)
+		}
+	}
+	return nil
+}
+
+// syncDue syncs the log file SyncInterval after a write, for SyncPeriodic.
+func (l *Logger) syncDue() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.syncTimer == nil {
+		return
+	}
+	l.syncTimer = nil
+	if l.stalled != nil {
+		// syncing a file with a stalled write would most likely stall too.
+		return
+	}
+	err := l.flush()
+	if err == nil {
+		err = l.sync()
+	}
+	if err != nil && l.OnError != nil {
+		l.OnError(fmt.Errorf("can't sync log file: %s", err))
+	}
+}
+
+// stopSync cancels the pending sync of SyncPeriodic.
+func (l *Logger) stopSync() {
+	if l.syncTimer != nil {
+		l.syncTimer()
+		l.syncTimer = nil
+	}
+}
+
+// syncOnClose reports whether the log file is synced when it is closed, and
+// when it is rotated if rotating is set.
+func (l *Logger) syncOnClose(rotating bool) bool {
+	return l.SyncPolicy == SyncPeriodic || (rotating && l.SyncPolicy == SyncOnRotate)
+}
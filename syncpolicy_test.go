@@ -0,0 +1,88 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jfrog/lumberjack/v2/lumberjacktest"
+)
+
+func TestSyncAlways(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSyncAlways", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{
+		Filename:   logFile(dir),
+		MaxSize:    100,
+		SyncPolicy: SyncAlways,
+	}
+	defer l.Close()
+
+	for i := 0; i < 2; i++ {
+		_, err := l.Write([]byte("boo!"))
+		isNil(err, t)
+	}
+	equals(int64(2), l.Stats().Syncs, t)
+}
+
+func TestSyncPeriodic(t *testing.T) {
+	megabyte = 1
+
+	dir := makeTempDir("TestSyncPeriodic", t)
+	defer os.RemoveAll(dir)
+
+	clock := lumberjacktest.NewFakeClock(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC))
+	l := &Logger{
+		Filename:     logFile(dir),
+		MaxSize:      100,
+		SyncPolicy:   SyncPeriodic,
+		SyncInterval: time.Minute,
+		Clock:        clock,
+	}
+	defer l.Close()
+
+	// the writes within an interval are synced together.
+	for i := 0; i < 2; i++ {
+		_, err := l.Write([]byte("boo!"))
+		isNil(err, t)
+	}
+	equals(int64(0), l.Stats().Syncs, t)
+	clock.Advance(time.Minute)
+	equals(int64(1), l.Stats().Syncs, t)
+
+	// nothing is left unsynced when the log file is closed.
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	isNil(l.Close(), t)
+	equals(int64(2), l.Stats().Syncs, t)
+	clock.Advance(time.Minute)
+	equals(int64(2), l.Stats().Syncs, t)
+}
+
+func TestSyncOnRotate(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSyncOnRotate", t)
+	defer os.RemoveAll(dir)
+
+	l := &Logger{
+		Filename:   logFile(dir),
+		MaxSize:    100,
+		SyncPolicy: SyncOnRotate,
+	}
+	defer l.Close()
+
+	_, err := l.Write([]byte("boo!"))
+	isNil(err, t)
+	equals(int64(0), l.Stats().Syncs, t)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+	equals(int64(1), l.Stats().Syncs, t)
+	isNil(l.Close(), t)
+	equals(int64(1), l.Stats().Syncs, t)
+}
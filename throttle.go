@@ -0,0 +1,40 @@
+package lumberjack
+
+import (
+	"io"
+	"time"
+)
+
+// systemLoad is a var so we can mock it out during tests.
+var systemLoad = loadAverage
+
+// loadPollInterval is how often the system load is checked while compression
+// is paused.  It is a var so we can shorten it during tests.
+var loadPollInterval = time.Second
+
+// loadThrottledReader pauses reads while the system load is above maxLoad.
+type loadThrottledReader struct {
+	r       io.Reader
+	maxLoad float64
+	checked time.Time
+}
+
+func (t *loadThrottledReader) Read(p []byte) (int, error) {
+	// checking the load for every read would cost more than it saves.
+	if time.Now().Sub(t.checked) >= loadPollInterval {
+		waitForLoad(t.maxLoad)
+		t.checked = time.Now()
+	}
+	return t.r.Read(p)
+}
+
+// waitForLoad blocks until the system load is at most maxLoad.
+func waitForLoad(maxLoad float64) {
+	for {
+		load, ok := systemLoad()
+		if !ok || load <= maxLoad {
+			return
+		}
+		time.Sleep(loadPollInterval)
+	}
+}
@@ -0,0 +1,61 @@
+package lumberjack
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCompressPausedByLoad(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	var mu sync.Mutex
+	load := 2.0
+	systemLoad = func() (float64, bool) {
+		mu.Lock()
+		defer mu.Unlock()
+		return load, true
+	}
+	loadPollInterval = time.Millisecond
+	defer func() {
+		systemLoad = loadAverage
+		loadPollInterval = time.Second
+	}()
+
+	dir := makeTempDir("TestCompressPausedByLoad", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:        filename,
+		MaxSize:         10,
+		Compress:        true,
+		CompressMaxLoad: 0.8,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	newFakeTime()
+	isNil(l.Rotate(), t)
+
+	// we need to wait a little bit since the files get compressed on a
+	// different goroutine.
+	<-time.After(300 * time.Millisecond)
+
+	// compression hasn't started while the load is high.
+	backup := backupFile(dir)
+	existsWithContent(backup, b, t)
+	notExist(backup+compressSuffix, t)
+
+	mu.Lock()
+	load = 0.5
+	mu.Unlock()
+	<-time.After(300 * time.Millisecond)
+
+	notExist(backup, t)
+	verifyCompressedFile(backup, b, t)
+	fileCount(dir, 2, t)
+}
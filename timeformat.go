@@ -0,0 +1,134 @@
+package lumberjack
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// TimePrecision is the precision of the fractional seconds in the default time
+// format.
+type TimePrecision string
+
+const (
+	// TimePrecisionNone leaves fractional seconds out, e.g.
+	// 2006-01-02T15-04-05.
+	TimePrecisionNone TimePrecision = "none"
+
+	// TimePrecisionMilli gives milliseconds, e.g. 2006-01-02T15-04-05.000.
+	TimePrecisionMilli TimePrecision = "milli"
+
+	// TimePrecisionMicro gives microseconds, e.g. 2006-01-02T15-04-05.000000.
+	TimePrecisionMicro TimePrecision = "micro"
+
+	// TimePrecisionNano gives nanoseconds, e.g.
+	// 2006-01-02T15-04-05.000000000.
+	TimePrecisionNano TimePrecision = "nano"
+)
+
+// secondsTimeFormat is the default time format without fractional seconds.
+const secondsTimeFormat = "2006-01-02T15-04-05"
+
+// format returns the default time format with the precision p.  Unknown
+// precisions give DefaultTimeFormat.
+func (p TimePrecision) format() string {
+	switch p {
+	case TimePrecisionNone:
+		return secondsTimeFormat
+	case TimePrecisionMicro:
+		return secondsTimeFormat + ".000000"
+	case TimePrecisionNano:
+		return secondsTimeFormat + ".000000000"
+	default:
+		return DefaultTimeFormat
+	}
+}
+
+// valid reports whether p is one of the known precisions, or empty.
+func (p TimePrecision) valid() bool {
+	switch p {
+	case "", TimePrecisionNone, TimePrecisionMilli, TimePrecisionMicro, TimePrecisionNano:
+		return true
+	}
+	return false
+}
+
+// unsafeNameChars are the characters that make a backup name unusable on the
+// current platform.  Path separators are never allowed, as they'd make
+// backups end up in directories that don't exist.
+var unsafeNameChars = "/"
+
+func init() {
+	if runtime.GOOS == "windows" {
+		unsafeNameChars = unsafeTimeFormatChars
+	}
+}
+
+// timeFormatZones are the layout elements for time zone offsets that contain
+// colons, and their equivalents without.
+var timeFormatZones = strings.NewReplacer(
+	"Z07:00:00", "Z070000",
+	"-07:00:00", "-070000",
+	"Z07:00", "Z0700",
+	"-07:00", "-0700",
+)
+
+// SanitizeTimeFormat returns a TimeFormat like format, but producing names
+// that can be used as file names on all platforms: characters that aren't
+// allowed in file names, such as path separators and colons, are replaced by
+// "-", and time zone offsets are written without colons.
+func SanitizeTimeFormat(format string) string {
+	format = timeFormatZones.Replace(format)
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(unsafeTimeFormatChars, r) {
+			return '-'
+		}
+		return r
+	}, format)
+}
+
+// anyPrecision returns the format with its fractional seconds, if any, made
+// optional and of any number of digits, for parsing.
+func anyPrecision(format string) string {
+	for i := 0; i+1 < len(format); i++ {
+		if c := format[i]; c != '.' && c != ',' {
+			continue
+		}
+		digit := format[i+1]
+		if digit != '0' && digit != '9' {
+			continue
+		}
+		j := i + 1
+		for j < len(format) && format[j] == digit {
+			j++
+		}
+		if j < len(format) && '0' <= format[j] && format[j] <= '9' {
+			// a run of digits, such as ".01" for the month, isn't a
+			// fractional second.
+			continue
+		}
+		return format[:i+1] + "999999999" + format[j:]
+	}
+	return format
+}
+
+// timeFormatRef is the time used to check what a TimeFormat produces, with a
+// different value in each element.
+var timeFormatRef = time.Date(2001, 2, 3, 4, 5, 6, 789000000, time.UTC)
+
+// checkTimeFormat returns an error if the TimeFormat produces backup names that
+// can't be used on the current platform, or that can't be parsed back to tell
+// which backups are the oldest, as Validate reports them.  Custom NameCodecs
+// and SequentialBackups are responsible for their own names.
+func (l *Logger) checkTimeFormat() error {
+	if l.NameCodec != nil || l.SequentialBackups {
+		return nil
+	}
+	format := l.timeFormat()
+	if problems := timeFormatProblems(format, unsafeNameChars); len(problems) > 0 {
+		p := problems[0]
+		return fmt.Errorf("TimeFormat %q: %s; %s", format, p.Why, p.Suggestion)
+	}
+	return nil
+}
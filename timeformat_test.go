@@ -0,0 +1,200 @@
+package lumberjack
+
+import (
+	"context"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSanitizeTimeFormat(t *testing.T) {
+	tests := map[string]string{
+		DefaultTimeFormat:              DefaultTimeFormat,
+		"2006-01-02T15:04:05":          "2006-01-02T15-04-05",
+		"2006/01/02 15.04.05":          "2006-01-02 15.04.05",
+		time.RFC3339:                   "2006-01-02T15-04-05Z0700",
+		"2006-01-02T15:04:05-07:00:00": "2006-01-02T15-04-05-070000",
+		`2006\01\02*15?04"05<>|`:       "2006-01-02-15-04-05---",
+	}
+	for format, exp := range tests {
+		got := SanitizeTimeFormat(format)
+		equals(exp, got, t)
+		name := time.Now().Format(got)
+		assert(!strings.ContainsAny(name, unsafeTimeFormatChars), t, "unsafe name %q for %q", name, got)
+		equals(0, len(Config{TimeFormat: got}.Validate()), t)
+	}
+}
+
+func TestUnsafeTimeFormat(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestUnsafeTimeFormat", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		TimeFormat: "2006/01/02",
+	}
+	defer l.Close()
+
+	// the TimeFormat is checked before anything is written.
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	notNil(err, t)
+	equals(0, n, t)
+	assert(strings.Contains(err.Error(), `"2006-01-02"`), t, "unexpected error: %v", err)
+	notExist(filename, t)
+	notNil(l.Open(), t)
+
+	l.TimeFormat = SanitizeTimeFormat(l.TimeFormat)
+	writeToCurrentLog(t, l, filename, b)
+
+	// a rotation fails the same way, and leaves the log file open.
+	l.TimeFormat = "2006/01/02"
+	newFakeTime()
+	notNil(l.Rotate(), t)
+	notNil(l.file, t)
+	_, err = l.Write(b)
+	isNil(err, t)
+	existsWithContent(filename, append(b, b...), t)
+	fileCount(dir, 1, t)
+
+	l.TimeFormat = SanitizeTimeFormat(l.TimeFormat)
+	isNil(l.Rotate(), t)
+	existsWithContent(backupFile(dir, withTimeFormat(l.TimeFormat)), append(b, b...), t)
+}
+
+func TestTimePrecision(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	tests := map[TimePrecision]string{
+		"":                 DefaultTimeFormat,
+		TimePrecisionNone:  "2006-01-02T15-04-05",
+		TimePrecisionMilli: DefaultTimeFormat,
+		TimePrecisionMicro: "2006-01-02T15-04-05.000000",
+		TimePrecisionNano:  "2006-01-02T15-04-05.000000000",
+	}
+	for precision, format := range tests {
+		dir := makeTempDir("TestTimePrecision"+string(precision), t)
+		defer os.RemoveAll(dir)
+
+		filename := logFile(dir)
+		l := &Logger{
+			Filename:      filename,
+			MaxSize:       10,
+			TimePrecision: precision,
+		}
+		defer l.Close()
+
+		b := []byte("boo!")
+		writeToCurrentLog(t, l, filename, b)
+		newFakeTime()
+		isNil(l.Rotate(), t)
+		existsWithContent(backupFile(dir, withTimeFormat(format)), b, t)
+
+		// the backup is recognized.
+		backups, err := l.Backups()
+		isNil(err, t)
+		equals(1, len(backups), t)
+	}
+
+	// a TimeFormat takes precedence.
+	l := &Logger{TimeFormat: "20060102", TimePrecision: TimePrecisionNano}
+	equals("20060102", l.timeFormat(), t)
+}
+
+func TestAmbiguousTimeFormat(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	tests := map[string]string{
+		"15-04-05":         "doesn't include the date",
+		"2006-1-215-04-05": "can't be parsed back",
+	}
+	i := 0
+	for format, why := range tests {
+		i++
+		dir := makeTempDir("TestAmbiguousTimeFormat"+strconv.Itoa(i), t)
+		defer os.RemoveAll(dir)
+
+		filename := logFile(dir)
+		l := &Logger{
+			Filename:   filename,
+			MaxSize:    10,
+			TimeFormat: format,
+		}
+		defer l.Close()
+
+		_, err := l.Write([]byte("boo!"))
+		notNil(err, t)
+		assert(strings.Contains(err.Error(), why), t, "unexpected error for %q: %v", format, err)
+		fileCount(dir, 0, t)
+	}
+}
+
+func TestDateTimeFormat(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestDateTimeFormat", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:   filename,
+		MaxSize:    10,
+		MaxBackups: 2,
+		TimeFormat: "2006-01-02",
+	}
+	defer l.Close()
+
+	// backups of the same day get sequence numbers, so they don't collide
+	// and are cleaned up in order.
+	for _, s := range []string{"boo!", "foo!", "moo!"} {
+		writeToCurrentLog(t, l, filename, []byte(s))
+		isNil(l.Rotate(), t)
+	}
+	isNil(l.Mill(context.Background()), t)
+	backups, err := l.Backups()
+	isNil(err, t)
+	equals(2, len(backups), t)
+	existsWithContent(backups[0].Path, []byte("moo!"), t)
+	existsWithContent(backups[1].Path, []byte("foo!"), t)
+}
+
+func TestAnyPrecision(t *testing.T) {
+	tests := map[string]string{
+		DefaultTimeFormat:              "2006-01-02T15-04-05.999999999",
+		"2006-01-02T15-04-05,000000":   "2006-01-02T15-04-05,999999999",
+		"2006-01-02T15-04-05.999":      "2006-01-02T15-04-05.999999999",
+		"2006-01-02T15-04-05":          "2006-01-02T15-04-05",
+		"2006.01.02.15.04.05":          "2006.01.02.15.04.05",
+		"2006.01.02.15.04.05.000-0700": "2006.01.02.15.04.05.999999999-0700",
+	}
+	for format, exp := range tests {
+		equals(exp, anyPrecision(format), t)
+	}
+}
+
+func TestTimeFormatChecksAgree(t *testing.T) {
+	currentTime = fakeTime
+	formats := []string{
+		DefaultTimeFormat, "2006-01-02", "20060102", "15-04-05", "2006-1-215-04-05",
+		"Jan _2 15-04-05 2006 MST-0700 MST", "2006-01-02T15:04:05",
+	}
+	for _, format := range formats {
+		l := &Logger{TimeFormat: format}
+		valid := len(Config{TimeFormat: format}.Validate()) == 0
+		err := l.checkTimeFormat()
+		if runtime.GOOS == "windows" || valid {
+			assert(valid == (err == nil), t, "%q: Validate says valid %v, but checkTimeFormat returned %v", format, valid, err)
+		}
+	}
+}
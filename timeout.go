@@ -0,0 +1,88 @@
+package lumberjack
+
+import (
+	"errors"
+	"io"
+	"os"
+	"time"
+)
+
+// ErrWriteTimeout is returned by Write when a write to the log file takes
+// longer than the Logger's WriteTimeout, and by subsequent writes until the
+// stalled write completes.
+var ErrWriteTimeout = errors.New("lumberjack: write to log file timed out")
+
+// writeResult is the outcome of a write to the log file.
+type writeResult struct {
+	n   int
+	err error
+}
+
+// writeFile writes p to the current log file, giving up after WriteTimeout.
+// A write that times out keeps running in the background, and the Logger is
+// considered stalled until it completes.
+func (l *Logger) writeFile(p []byte) (int, error) {
+	if l.WriteTimeout <= 0 {
+		n, err := writeAll(l.file, p)
+		l.countPhysical(n)
+		return n, err
+	}
+
+	// the caller may reuse p as soon as we return, which could be before the
+	// write completes.
+	buf := make([]byte, len(p))
+	copy(buf, p)
+	f := l.file
+	done := make(chan writeResult, 1)
+	go func() {
+		n, err := writeAll(f, buf)
+		done <- writeResult{n, err}
+	}()
+
+	timer := time.NewTimer(l.WriteTimeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		l.countPhysical(r.n)
+		return r.n, r.err
+	case <-timer.C:
+		l.stalled = done
+		return 0, ErrWriteTimeout
+	}
+}
+
+// writeAll writes p to f, carrying on with the rest of p after a short write,
+// so that a record isn't cut off.  It returns the number of bytes written,
+// which is less than len(p) only if it also returns an error.
+func writeAll(f *os.File, p []byte) (n int, err error) {
+	for n < len(p) {
+		m, err := file_Write(f, p[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+		if m == 0 {
+			// no progress, so retrying is unlikely to help.
+			return n, io.ErrShortWrite
+		}
+	}
+	return n, nil
+}
+
+// checkStalled returns ErrWriteTimeout if a write that timed out earlier still
+// hasn't completed.  Once it completes, the bytes it wrote are accounted for.
+func (l *Logger) checkStalled() error {
+	if l.stalled == nil {
+		return nil
+	}
+	select {
+	case r := <-l.stalled:
+		l.stalled = nil
+		l.size += int64(r.n)
+		l.countBytes(r.n)
+		l.countPhysical(r.n)
+		return nil
+	default:
+		return ErrWriteTimeout
+	}
+}
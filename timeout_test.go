@@ -0,0 +1,96 @@
+package lumberjack
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestWriteTimeout(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestWriteTimeout", t)
+	defer os.RemoveAll(dir)
+
+	release := make(chan struct{})
+	file_Write = func(f *os.File, p []byte) (int, error) {
+		<-release
+		return f.Write(p)
+	}
+	defer func() { file_Write = (*os.File).Write }()
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      100,
+		WriteTimeout: 10 * time.Millisecond,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	n, err := l.Write(b)
+	equals(ErrWriteTimeout, err, t)
+	equals(0, n, t)
+
+	// while the first write is stuck, everything else fails fast.
+	n, err = l.Write(b)
+	equals(ErrWriteTimeout, err, t)
+	equals(0, n, t)
+	equals(ErrWriteTimeout, l.Rotate(), t)
+
+	close(release)
+	<-time.After(10 * time.Millisecond)
+
+	b2 := []byte("foo!")
+	n, err = l.Write(b2)
+	isNil(err, t)
+	equals(len(b2), n, t)
+	existsWithContent(filename, append(b, b2...), t)
+	equals(int64(len(b)+len(b2)), l.size, t)
+}
+
+func TestShortWrite(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestShortWrite", t)
+	defer os.RemoveAll(dir)
+
+	// the file only takes 3 bytes at a time, and nothing once it has 10.
+	written := 0
+	file_Write = func(f *os.File, p []byte) (int, error) {
+		if len(p) > 3 {
+			p = p[:3]
+		}
+		if written+len(p) > 10 {
+			p = p[:10-written]
+		}
+		written += len(p)
+		return f.Write(p)
+	}
+	defer func() { file_Write = (*os.File).Write }()
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename: filename,
+		MaxSize:  100,
+	}
+	defer l.Close()
+
+	b := []byte("boo!boo!")
+	n, err := l.Write(b)
+	isNil(err, t)
+	equals(len(b), n, t)
+	existsWithContent(filename, b, t)
+
+	// a write that stops making progress is reported, with what it wrote
+	// accounted for.
+	n, err = l.Write([]byte("foo!"))
+	equals(io.ErrShortWrite, err, t)
+	equals(2, n, t)
+	existsWithContent(filename, []byte("boo!boo!fo"), t)
+	equals(int64(10), l.size, t)
+	equals(int64(10), l.Stats().BytesWritten, t)
+}
@@ -0,0 +1,51 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// enforceTotalSize removes the oldest backups until the log file and its
+// backups fit in MaxTotalSize.  Backups that may not be removed, e.g. because
+// of KeepUnshipped, still count towards the total.
+func (l *Logger) enforceTotalSize() error {
+	if l.MaxTotalSize <= 0 {
+		return nil
+	}
+	unlock, err := l.lockCleanup()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	files, err := l.oldLogFiles()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	if info, errStat := os.Stat(l.activeFilename()); errStat == nil {
+		total += info.Size()
+	}
+	for _, f := range files {
+		total += f.Size()
+	}
+
+	budget := int64(l.MaxTotalSize) * int64(megabyte)
+	removable := l.removableFilter()
+	// backups are sorted newest first.
+	for i := len(files) - 1; i >= 0 && total > budget; i-- {
+		f := files[i]
+		if removable != nil && !removable(f) {
+			continue
+		}
+		errRemove := l.remove(filepath.Join(f.dir, f.Name()))
+		if errRemove != nil {
+			if err == nil {
+				err = errRemove
+			}
+			continue
+		}
+		total -= f.Size()
+	}
+	return err
+}
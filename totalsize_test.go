@@ -0,0 +1,55 @@
+package lumberjack
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestMaxTotalSize(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestMaxTotalSize", t)
+	defer os.RemoveAll(dir)
+
+	// three 10 byte backups, one of them compressed to 5 bytes.
+	data := []byte("0123456789")
+	var backups []string
+	for i := 0; i < 3; i++ {
+		newFakeTime()
+		backups = append(backups, backupFile(dir))
+	}
+	isNil(ioutil.WriteFile(backups[0], data, 0644), t)
+	isNil(ioutil.WriteFile(backups[1]+compressSuffix, data[:5], 0644), t)
+	isNil(ioutil.WriteFile(backups[2], data, 0644), t)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:     filename,
+		MaxSize:      10,
+		MaxTotalSize: 21,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+
+	// 4 + 10 + 5 + 10 bytes is over the budget, so the oldest backup goes,
+	// leaving 19 bytes.
+	isNil(l.millRunOnce(), t)
+	notExist(backups[0], t)
+	exists(backups[1]+compressSuffix, t)
+	exists(backups[2], t)
+
+	// a rotation adds a backup, pushing the next oldest out: 7 + 4 + 10 + 5
+	// bytes is over the budget again.
+	newFakeTime()
+	_, err := l.Write([]byte("foobar!"))
+	isNil(err, t)
+	<-time.After(10 * time.Millisecond)
+	notExist(backups[1]+compressSuffix, t)
+	exists(backups[2], t)
+	existsWithContent(backupFile(dir), b, t)
+}
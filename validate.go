@@ -0,0 +1,471 @@
+package lumberjack
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Problem describes a setting of a Config that is invalid, or that most likely
+// doesn't do what was intended.
+type Problem struct {
+	// Field is the name of the Config or Logger field with the problem.
+	Field string
+
+	// Value is the value of the field.
+	Value interface{}
+
+	// Why explains what is wrong with the value.
+	Why string
+
+	// Suggestion explains how to fix the problem.
+	Suggestion string
+}
+
+// String returns a one line description of the problem.
+func (p Problem) String() string {
+	return fmt.Sprintf("%s %#v: %s; %s", p.Field, p.Value, p.Why, p.Suggestion)
+}
+
+// unsafeTimeFormatChars are the characters that can't be used in file names
+// on at least one of the supported platforms.
+const unsafeTimeFormatChars = `/\:*?"<>|`
+
+// Validate checks the settings for mistakes, such as a TimeFormat producing
+// file names that can't be created, and returns the problems found, if any.
+// It doesn't access the file system, so it can be used to check a Config
+// before deploying it.
+func (c Config) Validate() []Problem {
+	var problems []Problem
+	add := func(field string, value interface{}, why, suggestion string) {
+		problems = append(problems, Problem{field, value, why, suggestion})
+	}
+
+	counts := []struct {
+		field string
+		value int
+	}{
+		{"MaxSize", c.MaxSize},
+		{"MaxAge", c.MaxAge},
+		{"MaxBackups", c.MaxBackups},
+		{"KeepDaily", c.KeepDaily},
+		{"KeepWeekly", c.KeepWeekly},
+		{"MaxTotalSize", c.MaxTotalSize},
+		{"MinFreeSpace", c.MinFreeSpace},
+		{"CompactBelow", c.CompactBelow},
+		{"KeepLastDecompressed", c.KeepLastDecompressed},
+		{"BufferSize", c.BufferSize},
+		{"MaxBytesPerSecond", c.MaxBytesPerSecond},
+	}
+	for _, n := range counts {
+		if n.value < 0 {
+			add(n.field, n.value, "it is negative", "use 0 for the default or a positive number")
+		}
+	}
+
+	if c.MaxAgeDuration < 0 {
+		add("MaxAgeDuration", c.MaxAgeDuration, "it is negative", "use 0 for the default or a positive duration")
+	}
+
+	if c.MaxTotalSize > 0 && int64(c.MaxTotalSize)*int64(megabyte) < c.max() {
+		add("MaxTotalSize", c.MaxTotalSize,
+			"it is smaller than MaxSize, so a full log file leaves no room for backups",
+			"make it several times MaxSize")
+	}
+
+	if c.MinFreePercent < 0 || c.MinFreePercent >= 100 {
+		add("MinFreePercent", c.MinFreePercent,
+			"it isn't a percentage below 100",
+			"use 0 for the default or a percentage such as 10")
+	}
+	if c.RejectLowSpaceWrites && c.MinFreeSpace <= 0 && c.MinFreePercent <= 0 {
+		add("RejectLowSpaceWrites", c.RejectLowSpaceWrites,
+			"it has no effect without MinFreeSpace or MinFreePercent",
+			"set MinFreeSpace or MinFreePercent, or remove RejectLowSpaceWrites")
+	}
+
+	if c.AuditMode {
+		limits := []struct {
+			field string
+			value int
+		}{
+			{"MaxAge", c.MaxAge},
+			{"MaxBackups", c.MaxBackups},
+			{"KeepDaily", c.KeepDaily},
+			{"KeepWeekly", c.KeepWeekly},
+			{"MaxTotalSize", c.MaxTotalSize},
+		}
+		for _, n := range limits {
+			if n.value > 0 {
+				add(n.field, n.value,
+					"it has no effect with AuditMode, which never removes backups",
+					"remove "+n.field+", or don't set AuditMode")
+			}
+		}
+		if c.MaxAgeDuration > 0 {
+			add("MaxAgeDuration", c.MaxAgeDuration,
+				"it has no effect with AuditMode, which never removes backups",
+				"remove MaxAgeDuration, or don't set AuditMode")
+		}
+		if c.CompactBelow > 0 {
+			add("CompactBelow", c.CompactBelow,
+				"it has no effect with AuditMode, which never merges backups",
+				"remove CompactBelow, or don't set AuditMode")
+		}
+		if c.SequentialBackups {
+			add("AuditMode", c.AuditMode,
+				"backups are renumbered with SequentialBackups, so the names of sealed backups clash",
+				"use timestamped backups, or don't set AuditMode")
+		}
+	}
+
+	if c.KeepLastDecompressed > 0 && !c.Compress {
+		add("KeepLastDecompressed", c.KeepLastDecompressed,
+			"it has no effect without Compress",
+			"set Compress, or remove KeepLastDecompressed")
+	} else if c.Compress && c.MaxBackups > 0 && c.KeepLastDecompressed >= c.MaxBackups {
+		add("KeepLastDecompressed", c.KeepLastDecompressed,
+			"it keeps all of the MaxBackups backups decompressed, so nothing is ever compressed",
+			"make it smaller than MaxBackups, or don't set Compress")
+	}
+
+	if c.KeepAllFor != 0 && c.KeepDaily == 0 && c.KeepWeekly == 0 {
+		add("KeepAllFor", c.KeepAllFor,
+			"it has no effect without KeepDaily or KeepWeekly",
+			"set KeepDaily or KeepWeekly, or use MaxAge to keep all backups for a number of days")
+	}
+
+	if c.CompressionCodec != "" {
+		if _, ok := lookupCompressor(c.CompressionCodec); !ok {
+			add("CompressionCodec", c.CompressionCodec,
+				"no Compressor is registered with this name, so backups aren't compressed",
+				"register it with RegisterCompressor, or use \""+DefaultCompression+"\"")
+		} else if !c.Compress {
+			add("CompressionCodec", c.CompressionCodec,
+				"it has no effect without Compress",
+				"set Compress, or remove CompressionCodec")
+		}
+	}
+
+	if c.Checksum != "" {
+		if _, ok := lookupChecksum(c.Checksum); !ok {
+			add("Checksum", c.Checksum,
+				"no hash algorithm is registered with this name, so backups can't be finalized",
+				"register it with RegisterChecksum, or use \"sha256\"")
+		}
+	}
+
+	if c.Encryption != "" {
+		if _, ok := lookupEncrypter(c.Encryption); !ok {
+			add("Encryption", c.Encryption,
+				"no Encrypter is registered with this name, so backups can't be finalized",
+				"register it with RegisterEncrypter")
+		}
+	}
+
+	if c.TimeFormat != "" {
+		problems = append(problems, validateTimeFormat(c.TimeFormat)...)
+	}
+
+	if !c.OversizeWrites.valid() {
+		add("OversizeWrites", c.OversizeWrites,
+			"it isn't a known policy, so writes larger than MaxSize fail",
+			`use "fresh" or "split", or remove OversizeWrites`)
+	}
+
+	if !c.ExistingBackups.valid() {
+		add("ExistingBackups", c.ExistingBackups,
+			"it isn't a known policy, so existing backups are overwritten",
+			`use "fail" or "rename", or remove ExistingBackups`)
+	}
+
+	if !c.RateLimitPolicy.valid() {
+		add("RateLimitPolicy", c.RateLimitPolicy,
+			"it isn't a known policy, so writes over the rate are dropped",
+			`use "drop" or "error", or remove RateLimitPolicy`)
+	} else if c.RateLimitPolicy != RateLimitBlock && c.MaxBytesPerSecond <= 0 {
+		add("RateLimitPolicy", c.RateLimitPolicy,
+			"it has no effect without MaxBytesPerSecond",
+			"set MaxBytesPerSecond, or remove RateLimitPolicy")
+	}
+
+	if !c.SyncPolicy.valid() {
+		add("SyncPolicy", c.SyncPolicy,
+			"it isn't a known policy, so the log file is left to the operating system to sync",
+			`use one of "none", "always", "interval" or "on-rotate"`)
+	} else if c.SyncPolicy == SyncAlways && c.BufferSize > 0 {
+		add("SyncPolicy", c.SyncPolicy,
+			"buffered writes are only synced once the buffer is written out",
+			`remove BufferSize, or use "interval"`)
+	}
+	if c.SyncInterval < 0 || (c.SyncInterval > 0 && c.SyncPolicy != SyncPeriodic) {
+		add("SyncInterval", c.SyncInterval,
+			`it is only used as a positive duration with SyncPolicy "interval"`,
+			`set SyncPolicy to "interval", or remove SyncInterval`)
+	}
+
+	if !c.TimePrecision.valid() {
+		add("TimePrecision", c.TimePrecision,
+			"it isn't a known precision, so the default is used",
+			`use one of "none", "milli", "micro" or "nano"`)
+	} else if c.TimePrecision != "" && c.TimeFormat != "" {
+		add("TimePrecision", c.TimePrecision,
+			"it is ignored when TimeFormat is set",
+			"remove TimePrecision, or TimeFormat to use the default format with this precision")
+	}
+
+	for _, path := range []struct{ field, value string }{
+		{"Filename", c.Filename},
+		{"BackupDir", c.BackupDir},
+	} {
+		if name := reservedName(path.value); name != "" {
+			add(path.field, path.value,
+				fmt.Sprintf("%q is the name of a device on Windows, so the log files can't be created there", name),
+				"use another name, even with an extension")
+		}
+	}
+
+	if c.BackupDir != "" && c.Filename != "" && filepath.Clean(c.BackupDir) == filepath.Clean(c.Filename) {
+		add("BackupDir", c.BackupDir,
+			"it is the log file, so backups can't be put in it",
+			"use a directory, such as the directory of Filename")
+	}
+
+	if c.BackupDir != "" {
+		tmp := filepath.Clean(os.TempDir()) + string(filepath.Separator)
+		if strings.HasPrefix(filepath.Clean(c.BackupDir)+string(filepath.Separator), tmp) {
+			add("BackupDir", c.BackupDir,
+				"it is in the temporary directory, which the system may prune behind the Logger's back",
+				"use a persistent directory such as /var/log")
+		}
+	}
+
+	if c.BackupDirLayout != "" {
+		problems = append(problems, c.validateBackupDirLayout()...)
+	}
+
+	if c.SymlinkCurrent && c.SequentialBackups {
+		add("SymlinkCurrent", c.SymlinkCurrent,
+			"backups are renumbered with SequentialBackups, so the log file can't be named like one",
+			"use timestamped backups, or don't set SymlinkCurrent")
+	}
+
+	if c.RotateAt != "" {
+		if _, err := time.Parse(rotateAtFormat, c.RotateAt); err != nil {
+			add("RotateAt", c.RotateAt,
+				"it isn't a time of day, so it is ignored",
+				`use a 24 hour time such as "00:00" or "06:30"`)
+		}
+	}
+
+	return problems
+}
+
+// ConfigError is returned by Logger.Validate and NewLogger for settings with
+// problems.
+type ConfigError struct {
+	// Problems are the problems found by Config.Validate.
+	Problems []Problem
+}
+
+// Error returns the problems, one per line.
+func (e *ConfigError) Error() string {
+	lines := make([]string, len(e.Problems))
+	for i, p := range e.Problems {
+		lines[i] = p.String()
+	}
+	return "lumberjack: invalid settings:\n\t" + strings.Join(lines, "\n\t")
+}
+
+// Validate checks the Logger's settings like Config.Validate, along with the
+// settings only a Logger has, such as BackupDirs and the timeouts, and
+// returns a *ConfigError with the problems found, if any.  Functions, such as
+// the hooks and the Archiver, aren't checked.
+func (l *Logger) Validate() error {
+	c := l.Config()
+	problems := c.Validate()
+	problems = append(problems, l.validateBackupDirs(c)...)
+	problems = append(problems, l.validateLoggerOnly(c)...)
+	if len(problems) > 0 {
+		return &ConfigError{problems}
+	}
+	return nil
+}
+
+// validateBackupDirs checks BackupDirs like BackupDir, and that
+// BackupShardMode is a known mode.
+func (l *Logger) validateBackupDirs(c Config) []Problem {
+	var problems []Problem
+	add := func(field string, value interface{}, why, suggestion string) {
+		problems = append(problems, Problem{field, value, why, suggestion})
+	}
+	for _, dir := range l.BackupDirs {
+		switch {
+		case dir == "":
+			add("BackupDirs", dir,
+				"it is empty, so backups are put in the current directory",
+				"use a directory, such as the directory of Filename")
+		case c.Filename != "" && filepath.Clean(dir) == filepath.Clean(c.Filename):
+			add("BackupDirs", dir,
+				"it is the log file, so backups can't be put in it",
+				"use a directory, such as the directory of Filename")
+		}
+		if name := reservedName(dir); name != "" {
+			add("BackupDirs", dir,
+				fmt.Sprintf("%q is the name of a device on Windows, so the log files can't be created there", name),
+				"use another name, even with an extension")
+		}
+	}
+	switch l.BackupShardMode {
+	case "", "roundrobin", "hash":
+	default:
+		add("BackupShardMode", l.BackupShardMode,
+			"it isn't a known mode, so backups cycle through BackupDirs in order",
+			`use "roundrobin" or "hash"`)
+	}
+	return problems
+}
+
+// validateLoggerOnly checks the settings of the Logger that aren't in its
+// Config, other than BackupDirs.
+func (l *Logger) validateLoggerOnly(c Config) []Problem {
+	var problems []Problem
+	add := func(field string, value interface{}, why, suggestion string) {
+		problems = append(problems, Problem{field, value, why, suggestion})
+	}
+
+	counts := []struct {
+		field string
+		value int
+	}{
+		{"MaxManualBackups", l.MaxManualBackups},
+		{"KeepLocalBackups", l.KeepLocalBackups},
+		{"RenameRetries", l.RenameRetries},
+	}
+	for _, n := range counts {
+		if n.value < 0 {
+			add(n.field, n.value, "it is negative", "use 0 for the default or a positive number")
+		}
+	}
+
+	durations := []struct {
+		field string
+		value time.Duration
+	}{
+		{"MaxInterval", l.MaxInterval},
+		{"CloseAfterIdle", l.CloseAfterIdle},
+		{"WriteTimeout", l.WriteTimeout},
+		{"SlowWriteThreshold", l.SlowWriteThreshold},
+		{"OpenRetryBackoff", l.OpenRetryBackoff},
+		{"MaxOpenRetryBackoff", l.MaxOpenRetryBackoff},
+		{"RenameRetryBackoff", l.RenameRetryBackoff},
+		{"ArchiveTimeout", l.ArchiveTimeout},
+		{"FilesystemTimeout", l.FilesystemTimeout},
+	}
+	for _, d := range durations {
+		if d.value < 0 {
+			add(d.field, d.value, "it is negative", "use 0 for the default or a positive duration")
+		}
+	}
+
+	if l.CompressMaxLoad < 0 {
+		add("CompressMaxLoad", l.CompressMaxLoad,
+			"it is negative, so it is ignored",
+			"use 0 for the default or a load such as 1")
+	}
+
+	if l.MaxManualBackups > 0 && !l.PartitionBackups {
+		add("MaxManualBackups", l.MaxManualBackups,
+			"it has no effect without PartitionBackups",
+			"set PartitionBackups, or remove MaxManualBackups")
+	}
+
+	if l.Archiver == nil {
+		if l.DeleteArchived {
+			add("DeleteArchived", l.DeleteArchived,
+				"it has no effect without an Archiver",
+				"set Archiver, or remove DeleteArchived")
+		}
+		if l.ArchiveTimeout > 0 {
+			add("ArchiveTimeout", l.ArchiveTimeout,
+				"it has no effect without an Archiver",
+				"set Archiver, or remove ArchiveTimeout")
+		}
+	}
+
+	if l.SplitOversized && c.SequentialBackups {
+		add("SplitOversized", l.SplitOversized,
+			"log files aren't split with SequentialBackups",
+			"use timestamped backups, or remove SplitOversized")
+	}
+	return problems
+}
+
+// validateTimeFormat checks that the format produces file names that can be
+// created on all platforms, and that can be parsed back to order backups
+// correctly.
+func validateTimeFormat(format string) []Problem {
+	return timeFormatProblems(format, unsafeTimeFormatChars)
+}
+
+// timeFormatProblems checks that the format produces file names without any of
+// the unsafe characters, and that can be parsed back to order backups
+// correctly.  A format without the time of day is fine, since backups made on
+// the same day get sequence numbers.  Logger.checkTimeFormat uses the same
+// checks, so a TimeFormat that passes Validate works.
+func timeFormatProblems(format, unsafe string) []Problem {
+	var problems []Problem
+	add := func(why, suggestion string) {
+		problems = append(problems, Problem{"TimeFormat", format, why, suggestion})
+	}
+	formatted := timeFormatRef.Format(format)
+
+	if i := strings.IndexAny(formatted, unsafe); i >= 0 {
+		add(fmt.Sprintf("it produces names containing %q, which can't be used in file names", formatted[i]),
+			fmt.Sprintf("use %q instead", SanitizeTimeFormat(format)))
+	}
+
+	parsed, err := time.Parse(format, formatted)
+	switch {
+	case err != nil || parsed.Format(format) != formatted:
+		add("the times it produces can't be parsed back, so backups aren't recognized and never removed",
+			fmt.Sprintf("use a format without ambiguous elements, such as %q", DefaultTimeFormat))
+	case parsed.Year() != timeFormatRef.Year() || parsed.YearDay() != timeFormatRef.YearDay():
+		add("it doesn't include the date, so backups are removed in the wrong order",
+			fmt.Sprintf("include the year, month and day, as %q does", DefaultTimeFormat))
+	}
+	return problems
+}
+
+// validateBackupDirLayout checks that BackupDirLayout produces relative paths
+// that can be created, and that it has an effect.
+func (c Config) validateBackupDirLayout() []Problem {
+	var problems []Problem
+	add := func(why, suggestion string) {
+		problems = append(problems, Problem{"BackupDirLayout", c.BackupDirLayout, why, suggestion})
+	}
+	ref := time.Date(2001, 2, 3, 4, 5, 6, 789000000, time.UTC)
+	formatted := ref.Format(c.BackupDirLayout)
+
+	// unlike in names, "/" separates the subdirectories.
+	if i := strings.IndexAny(formatted, strings.Replace(unsafeTimeFormatChars, "/", "", 1)); i >= 0 {
+		add(fmt.Sprintf("it produces paths containing %q, which can't be used in file names on all platforms", formatted[i]),
+			`use "/" to separate the elements, such as "2006/01/02"`)
+	}
+	if strings.HasPrefix(formatted, "/") || formatted == ".." || strings.HasPrefix(formatted, "../") ||
+		strings.Contains(formatted, "/../") || strings.HasSuffix(formatted, "/..") {
+		add("it produces paths outside of the backup directory, so backups aren't found by the cleanup",
+			`use a relative layout, such as "2006/01/02"`)
+	}
+	if formatted == c.BackupDirLayout {
+		add("it has no time elements, so all backups go in the same subdirectory",
+			`use a time layout, such as "2006/01/02"`)
+	}
+	if c.SequentialBackups {
+		add("it is ignored with SequentialBackups",
+			"remove BackupDirLayout, or SequentialBackups")
+	}
+	return problems
+}
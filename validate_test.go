@@ -0,0 +1,152 @@
+package lumberjack
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		cfg    Config
+		fields []string
+	}{
+		{Config{}, nil},
+		{Config{MaxSize: 10, MaxBackups: 5, Compress: true, KeepLastDecompressed: 2, TimeFormat: "20060102T150405"}, nil},
+		{Config{MaxSize: -1, MaxAge: -1}, []string{"MaxSize", "MaxAge"}},
+		{Config{MaxAgeDuration: 6 * time.Hour}, nil},
+		{Config{MaxAgeDuration: -time.Hour}, []string{"MaxAgeDuration"}},
+		{Config{KeepLastDecompressed: 2}, []string{"KeepLastDecompressed"}},
+		{Config{BufferSize: -1}, []string{"BufferSize"}},
+		{Config{MaxSize: 10, MaxTotalSize: 50}, nil},
+		{Config{MaxSize: 10, MaxTotalSize: 5}, []string{"MaxTotalSize"}},
+		{Config{MaxTotalSize: 50}, []string{"MaxTotalSize"}},
+		{Config{MaxTotalSize: -1}, []string{"MaxTotalSize"}},
+		{Config{Compress: true, MaxBackups: 2, KeepLastDecompressed: 2}, []string{"KeepLastDecompressed"}},
+		{Config{TimeFormat: "2006-01-02T15:04:05"}, []string{"TimeFormat"}},
+		{Config{TimeFormat: "2006/01/02 15-04-05"}, []string{"TimeFormat"}},
+		{Config{TimeFormat: "15-04-05"}, []string{"TimeFormat"}},
+		{Config{TimeFormat: "Jan _2 15-04-05 2006 MST-0700 MST"}, nil},
+		{Config{TimeFormat: "2006-01-02"}, nil},
+		{Config{TimeFormat: "2006-01-02:15"}, []string{"TimeFormat"}},
+		{Config{TimeFormat: "15:04:05"}, []string{"TimeFormat", "TimeFormat"}},
+		{Config{TimePrecision: TimePrecisionNano}, nil},
+		{Config{TimePrecision: "centi"}, []string{"TimePrecision"}},
+		{Config{OversizeWrites: OversizeFresh}, nil},
+		{Config{ExistingBackups: ExistingRename}, nil},
+		{Config{ExistingBackups: "skip"}, []string{"ExistingBackups"}},
+		{Config{KeepDaily: 7, KeepAllFor: time.Hour}, nil},
+		{Config{KeepAllFor: time.Hour}, []string{"KeepAllFor"}},
+		{Config{MinFreeSpace: -1, MinFreePercent: 100}, []string{"MinFreeSpace", "MinFreePercent"}},
+		{Config{RejectLowSpaceWrites: true}, []string{"RejectLowSpaceWrites"}},
+		{Config{MinFreePercent: 10, RejectLowSpaceWrites: true}, nil},
+		{Config{KeepWeekly: -1}, []string{"KeepWeekly"}},
+		{Config{AuditMode: true, Compress: true}, nil},
+		{Config{AuditMode: true, MaxBackups: 3, MaxTotalSize: 1000, CompactBelow: 1}, []string{"MaxBackups", "MaxTotalSize", "CompactBelow"}},
+		{Config{AuditMode: true, SequentialBackups: true}, []string{"AuditMode"}},
+		{Config{MaxBytesPerSecond: 1024, RateLimitPolicy: RateLimitDrop}, nil},
+		{Config{RateLimitPolicy: RateLimitError}, []string{"RateLimitPolicy"}},
+		{Config{MaxBytesPerSecond: -1, RateLimitPolicy: "queue"}, []string{"MaxBytesPerSecond", "RateLimitPolicy"}},
+		{Config{Encryption: "rot13"}, []string{"Encryption"}},
+		{Config{Filename: `C:\logs\con.log`}, []string{"Filename"}},
+		{Config{Filename: "/var/log/console.log", BackupDir: "/var/log/NUL"}, []string{"BackupDir"}},
+		{Config{OversizeWrites: "truncate"}, []string{"OversizeWrites"}},
+		{Config{TimePrecision: TimePrecisionNone, TimeFormat: "20060102T150405"}, []string{"TimePrecision"}},
+		{Config{BackupDir: filepath.Join(os.TempDir(), "backups")}, []string{"BackupDir"}},
+		{Config{BackupDir: "/var/log/backups"}, nil},
+		{Config{Filename: "/var/log/app.log", BackupDir: "/var/log/app.log/"}, []string{"BackupDir"}},
+		{Config{RotateAt: "06:30"}, nil},
+		{Config{BackupDirLayout: "2006/01/02"}, nil},
+		{Config{SyncPolicy: SyncPeriodic, SyncInterval: time.Second}, nil},
+		{Config{SyncPolicy: "fsync", SyncInterval: time.Second}, []string{"SyncPolicy", "SyncInterval"}},
+		{Config{SyncPolicy: SyncAlways, BufferSize: 4096}, []string{"SyncPolicy"}},
+		{Config{SymlinkCurrent: true, BackupDirLayout: "2006/01/02"}, nil},
+		{Config{SymlinkCurrent: true, SequentialBackups: true}, []string{"SymlinkCurrent"}},
+		{Config{BackupDirLayout: `2006\01`}, []string{"BackupDirLayout"}},
+		{Config{BackupDirLayout: "/2006"}, []string{"BackupDirLayout"}},
+		{Config{BackupDirLayout: "backups"}, []string{"BackupDirLayout"}},
+		{Config{BackupDirLayout: "2006-01", SequentialBackups: true}, []string{"BackupDirLayout"}},
+		{Config{Compress: true, CompressionCodec: "gzip"}, nil},
+		{Config{Compress: true, CompressionCodec: "rar"}, []string{"CompressionCodec"}},
+		{Config{CompressionCodec: "gzip"}, []string{"CompressionCodec"}},
+		{Config{Checksum: "sha256"}, nil},
+		{Config{Checksum: "md4"}, []string{"Checksum"}},
+		{Config{RotateAt: "6pm"}, []string{"RotateAt"}},
+		{Config{RotateAt: "24:00"}, []string{"RotateAt"}},
+	}
+	for i, test := range tests {
+		problems := test.cfg.Validate()
+		var fields []string
+		for _, p := range problems {
+			fields = append(fields, p.Field)
+			assert(p.Why != "" && p.Suggestion != "", t, "test %d: incomplete problem %v", i, p)
+		}
+		assert(len(fields) == len(test.fields), t, "test %d: expected problems with %v, got %v", i, test.fields, problems)
+		for j := range fields {
+			equals(test.fields[j], fields[j], t)
+		}
+	}
+}
+
+func TestProblemString(t *testing.T) {
+	p := Problem{
+		Field:      "MaxSize",
+		Value:      -1,
+		Why:        "it is negative",
+		Suggestion: "use 0 for the default or a positive number",
+	}
+	equals("MaxSize -1: it is negative; use 0 for the default or a positive number", p.String(), t)
+}
+
+func TestLoggerValidate(t *testing.T) {
+	l := &Logger{MaxSize: 100, TimeFormat: "20060102T150405"}
+	isNil(l.Validate(), t)
+
+	l = &Logger{MaxSize: -1, TimeFormat: "2006/01/02T150405"}
+	err := l.Validate()
+	notNil(err, t)
+	problems := err.(*ConfigError).Problems
+	equals(2, len(problems), t)
+	equals("lumberjack: invalid settings:\n\t"+problems[0].String()+"\n\t"+problems[1].String(), err.Error(), t)
+}
+
+func TestLoggerValidateBackupDirs(t *testing.T) {
+	l := &Logger{BackupDirs: []string{"shard1", "shard2"}, BackupShardMode: "hash"}
+	isNil(l.Validate(), t)
+
+	l = &Logger{
+		Filename:        "foo.log",
+		BackupDirs:      []string{"", "foo.log", "nul"},
+		BackupShardMode: "random",
+	}
+	err := l.Validate()
+	notNil(err, t)
+	var fields []string
+	for _, p := range err.(*ConfigError).Problems {
+		fields = append(fields, p.Field)
+	}
+	equals([]string{"BackupDirs", "BackupDirs", "BackupDirs", "BackupShardMode"}, fields, t)
+}
+
+func TestLoggerValidateLoggerOnly(t *testing.T) {
+	l := &Logger{PartitionBackups: true, MaxManualBackups: 5, WriteTimeout: time.Second}
+	isNil(l.Validate(), t)
+
+	l = &Logger{
+		MaxManualBackups:  2,
+		RenameRetries:     -1,
+		CloseAfterIdle:    -time.Minute,
+		CompressMaxLoad:   -1,
+		DeleteArchived:    true,
+		SplitOversized:    true,
+		SequentialBackups: true,
+	}
+	err := l.Validate()
+	notNil(err, t)
+	var fields []string
+	for _, p := range err.(*ConfigError).Problems {
+		fields = append(fields, p.Field)
+	}
+	equals([]string{"RenameRetries", "CloseAfterIdle", "CompressMaxLoad", "MaxManualBackups", "DeleteArchived", "SplitOversized"}, fields, t)
+}
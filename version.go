@@ -0,0 +1,93 @@
+package lumberjack
+
+import (
+	"runtime/debug"
+	"sort"
+	"sync"
+)
+
+// modulePath is the path of this module, to look it up in the build info.
+const modulePath = "github.com/jfrog/lumberjack/v2"
+
+// Version returns the version of lumberjack built into the binary, as
+// recorded by the go command, or "(devel)" if it isn't known, e.g. when built
+// from a local checkout or with GOPATH.
+func Version() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return "(devel)"
+	}
+	if info.Main.Path == modulePath {
+		return info.Main.Version
+	}
+	for _, dep := range info.Deps {
+		if dep.Path != modulePath {
+			continue
+		}
+		if dep.Replace != nil {
+			dep = dep.Replace
+		}
+		if dep.Version == "" {
+			return "(devel)"
+		}
+		return dep.Version
+	}
+	return "(devel)"
+}
+
+// Capabilities reports which optional parts of lumberjack a binary contains,
+// for operators and support tooling.
+type Capabilities struct {
+	// Version is the version of lumberjack, as returned by Version.
+	Version string `json:"version"`
+
+	// Compressors are the names of the registered Compressors, which can be
+	// used as CompressionCodec.
+	Compressors []string `json:"compressors"`
+
+	// Checksums are the names of the registered hash algorithms, which can be
+	// used as Checksum.
+	Checksums []string `json:"checksums"`
+
+	// Features are the names of the features supported on this platform, such
+	// as "inotify" for watching the log file efficiently, and of those
+	// registered with RegisterFeature, such as archiver backends.
+	Features []string `json:"features"`
+}
+
+var (
+	featuresMu sync.Mutex
+	features   = map[string]bool{}
+)
+
+// RegisterFeature adds a feature to the ones reported by GetCapabilities.  It
+// is meant to be called at initialization by packages extending lumberjack,
+// e.g. with "archiver/s3" by a package providing an Archiver for S3.
+func RegisterFeature(name string) {
+	featuresMu.Lock()
+	defer featuresMu.Unlock()
+	features[name] = true
+}
+
+// GetCapabilities reports which optional parts of lumberjack the binary
+// contains.  The names are sorted.
+func GetCapabilities() Capabilities {
+	c := Capabilities{Version: Version()}
+
+	compressorsMu.RLock()
+	for name := range compressors {
+		c.Compressors = append(c.Compressors, name)
+	}
+	compressorsMu.RUnlock()
+	sort.Strings(c.Compressors)
+	c.Checksums = checksumNames()
+
+	c.Features = append(c.Features, platformFeatures...)
+	featuresMu.Lock()
+	for name := range features {
+		c.Features = append(c.Features, name)
+	}
+	featuresMu.Unlock()
+	sort.Strings(c.Features)
+	return c
+}
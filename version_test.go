@@ -0,0 +1,35 @@
+package lumberjack
+
+import (
+	"sort"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	// tests run as the main module, whose version isn't known.
+	equals("(devel)", Version(), t)
+}
+
+func TestGetCapabilities(t *testing.T) {
+	RegisterFeature("archiver/test")
+
+	c := GetCapabilities()
+	equals(Version(), c.Version, t)
+	assert(sort.StringsAreSorted(c.Compressors), t, "expected sorted compressors, got %v", c.Compressors)
+	assert(sort.StringsAreSorted(c.Features), t, "expected sorted features, got %v", c.Features)
+	assert(contains(c.Compressors, DefaultCompression), t, "expected %q in %v", DefaultCompression, c.Compressors)
+	assert(contains(c.Checksums, "sha256"), t, "expected sha256 in %v", c.Checksums)
+	assert(contains(c.Features, "archiver/test"), t, "expected the registered feature in %v", c.Features)
+	for _, f := range platformFeatures {
+		assert(contains(c.Features, f), t, "expected %q in %v", f, c.Features)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
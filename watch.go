@@ -0,0 +1,110 @@
+package lumberjack
+
+import (
+	"os"
+	"time"
+)
+
+// ChangeReason describes how the log file was changed by something else.
+type ChangeReason string
+
+const (
+	// ChangeRemoved is used when the log file was removed or renamed.
+	ChangeRemoved ChangeReason = "removed"
+
+	// ChangeReplaced is used when Filename refers to a different file than
+	// the log file, e.g. because it was replaced by a new file, or the
+	// filesystem it is on was remounted.
+	ChangeReplaced ChangeReason = "replaced"
+
+	// ChangeTruncated is used when the log file was truncated.
+	ChangeTruncated ChangeReason = "truncated"
+)
+
+// watchFile is a var so we can mock it out during tests.
+var watchFile = notifyFile
+
+// watchPollInterval is how often the log file is checked when it can't be
+// watched for changes.  It is a var so we can shorten it during tests.
+var watchPollInterval = time.Second
+
+// startWatch starts watching the newly opened log file with the given name
+// for external changes, if enabled.  The file is polled even if it can be
+// watched, since a watch stays with the file, and doesn't notice the name
+// resolving to another file.
+func (l *Logger) startWatch(name string) {
+	if !l.WatchExternalChanges {
+		return
+	}
+	l.stopWatch()
+	stop := make(chan struct{})
+	l.watchStop = stop
+	f := l.file
+	changed := func() { l.checkExternal(f, name) }
+	_ = watchFile(name, stop, changed)
+	go l.pollFile(stop, watchPollInterval, changed)
+}
+
+// stopWatch stops watching the log file.
+func (l *Logger) stopWatch() {
+	if l.watchStop != nil {
+		close(l.watchStop)
+		l.watchStop = nil
+	}
+}
+
+// pollFile calls changed every interval until stop is closed.  The interval is
+// read by startWatch, under the Logger's lock.
+func (l *Logger) pollFile(stop <-chan struct{}, interval time.Duration, changed func()) {
+	for {
+		due := make(chan struct{})
+		cancel := l.scheduleWork(WorkWatch, interval, func() { close(due) })
+		select {
+		case <-stop:
+			cancel()
+			return
+		case <-due:
+			changed()
+		}
+	}
+}
+
+// checkExternal closes the log file f, which was opened with the given name,
+// if something else removed, renamed, replaced or truncated it.
+func (l *Logger) checkExternal(f *os.File, name string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file != f {
+		return
+	}
+	reason := l.changedExternally(name)
+	if reason == "" {
+		return
+	}
+	l.stats.BytesSinceRotation = 0
+	if l.OnExternalChange != nil {
+		l.OnExternalChange(name, reason)
+	}
+	// what am I going to do, log this?
+	_ = l.close()
+}
+
+// changedExternally returns how the log file was changed, if it isn't the file
+// with the given name any more or has been truncated, or "" otherwise.
+func (l *Logger) changedExternally(name string) ChangeReason {
+	opened, err := l.file.Stat()
+	if err != nil {
+		return ChangeRemoved
+	}
+	current, err := os_Stat(name)
+	if err != nil {
+		return ChangeRemoved
+	}
+	if !os.SameFile(opened, current) {
+		return ChangeReplaced
+	}
+	if opened.Size() < l.size {
+		return ChangeTruncated
+	}
+	return ""
+}
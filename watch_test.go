@@ -0,0 +1,151 @@
+package lumberjack
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// pollForChanges makes the Logger poll the log file for changes.
+func pollForChanges() func() {
+	watchFile = func(string, <-chan struct{}, func()) error {
+		return errors.New("not supported")
+	}
+	watchPollInterval = time.Millisecond
+	return func() {
+		watchFile = notifyFile
+		watchPollInterval = time.Second
+	}
+}
+
+func TestWatchRemoved(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer pollForChanges()()
+
+	dir := makeTempDir("TestWatchRemoved", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		WatchExternalChanges: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	isNil(os.Remove(filename), t)
+
+	// give the Logger a little time to notice.
+	<-time.After(50 * time.Millisecond)
+
+	b := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b)
+	equals(int64(len(b)), l.Stats().BytesSinceRotation, t)
+}
+
+func TestWatchTruncated(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer pollForChanges()()
+
+	dir := makeTempDir("TestWatchTruncated", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		WatchExternalChanges: true,
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	isNil(os.Truncate(filename, 0), t)
+	<-time.After(50 * time.Millisecond)
+
+	// the write goes at the start of the file, rather than after a hole.
+	b := []byte("foo!")
+	writeToCurrentLog(t, l, filename, b)
+}
+
+func TestWatchUnchanged(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	defer pollForChanges()()
+
+	dir := makeTempDir("TestWatchUnchanged", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		WatchExternalChanges: true,
+	}
+	defer l.Close()
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	<-time.After(50 * time.Millisecond)
+
+	l.mu.Lock()
+	open := l.file != nil
+	l.mu.Unlock()
+	assert(open, t, "log file was closed although it didn't change")
+}
+
+func TestWatchPathFlipped(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+	// a watch that never reports anything, like inotify when a mount is
+	// flipped.
+	watchFile = func(string, <-chan struct{}, func()) error { return nil }
+	watchPollInterval = time.Millisecond
+	defer func() {
+		watchFile = notifyFile
+		watchPollInterval = time.Second
+	}()
+
+	dir := makeTempDir("TestWatchPathFlipped", t)
+	defer os.RemoveAll(dir)
+	volA := filepath.Join(dir, "a")
+	volB := filepath.Join(dir, "b")
+	isNil(os.Mkdir(volA, 0700), t)
+	isNil(os.Mkdir(volB, 0700), t)
+	mount := filepath.Join(dir, "mnt")
+	isNil(os.Symlink(volA, mount), t)
+
+	var reasons []ChangeReason
+	filename := logFile(mount)
+	l := &Logger{
+		Filename:             filename,
+		MaxSize:              100,
+		WatchExternalChanges: true,
+		OnExternalChange: func(name string, reason ChangeReason) {
+			equals(filename, name, t)
+			reasons = append(reasons, reason)
+		},
+	}
+	defer l.Close()
+
+	writeToCurrentLog(t, l, filename, []byte("boo!"))
+	isNil(ioutil.WriteFile(logFile(volB), []byte("foo!"), 0644), t)
+
+	// flip the mount over to the other volume.
+	isNil(os.Remove(mount), t)
+	isNil(os.Symlink(volB, mount), t)
+	<-time.After(50 * time.Millisecond)
+
+	_, err := l.Write([]byte("bar!"))
+	isNil(err, t)
+	existsWithContent(logFile(volA), []byte("boo!"), t)
+	existsWithContent(logFile(volB), []byte("foo!bar!"), t)
+	l.mu.Lock()
+	equals([]ChangeReason{ChangeReplaced}, reasons, t)
+	l.mu.Unlock()
+}
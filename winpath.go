@@ -0,0 +1,71 @@
+package lumberjack
+
+import (
+	"fmt"
+	"strings"
+)
+
+// windowsMaxPath is the length from which paths need the \\?\ prefix on
+// Windows.  It is MAX_PATH less the room for an 8.3 file name, which is the
+// limit for directories.
+const windowsMaxPath = 248
+
+// windowsReservedNames are the names of devices on Windows, which can't be
+// used as file or directory names, even with an extension.
+var windowsReservedNames = map[string]bool{
+	"CON": true, "PRN": true, "AUX": true, "NUL": true,
+	"CONIN$": true, "CONOUT$": true,
+	"COM1": true, "COM2": true, "COM3": true, "COM4": true, "COM5": true,
+	"COM6": true, "COM7": true, "COM8": true, "COM9": true,
+	"LPT1": true, "LPT2": true, "LPT3": true, "LPT4": true, "LPT5": true,
+	"LPT6": true, "LPT7": true, "LPT8": true, "LPT9": true,
+}
+
+// reservedName returns the first element of the Windows path that is the name
+// of a device, or "" if there is none.
+func reservedName(path string) string {
+	path = strings.TrimPrefix(path, `\\?\`)
+	if len(path) >= 2 && path[1] == ':' {
+		path = path[2:]
+	}
+	elems := strings.FieldsFunc(path, func(r rune) bool { return r == '\\' || r == '/' })
+	for _, elem := range elems {
+		name := elem
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		if windowsReservedNames[strings.ToUpper(strings.TrimRight(name, " "))] {
+			return elem
+		}
+	}
+	return ""
+}
+
+// longPath adds the \\?\ prefix to an absolute, clean Windows path that is
+// too long for the Windows API without it.
+func longPath(path string) string {
+	if len(path) < windowsMaxPath || strings.HasPrefix(path, `\\?\`) {
+		return path
+	}
+	if strings.HasPrefix(path, `\\`) {
+		// a UNC path, \\server\share\...
+		return `\\?\UNC\` + path[2:]
+	}
+	return `\\?\` + path
+}
+
+// checkPaths returns an error if the log file or the backup directories can't
+// be used on this platform.
+func (l *Logger) checkPaths() error {
+	for _, path := range append([]string{l.filename()}, l.backupDirs()...) {
+		if err := checkPath(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reservedNameError is the error for a path using the name of a device.
+func reservedNameError(path, name string) error {
+	return fmt.Errorf("can't use %s: %q is a reserved device name on Windows", path, name)
+}
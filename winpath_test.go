@@ -0,0 +1,37 @@
+package lumberjack
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReservedName(t *testing.T) {
+	tests := []struct {
+		path string
+		name string
+	}{
+		{`C:\logs\app.log`, ""},
+		{`C:\logs\console.log`, ""},
+		{`C:\logs\CON`, "CON"},
+		{`C:\logs\nul.log`, "nul.log"},
+		{`C:\COM1\app.log`, "COM1"},
+		{`\\?\C:\logs\LPT9.txt`, "LPT9.txt"},
+		{`logs/aux /app.log`, "aux "},
+		{"/var/log/app.log", ""},
+	}
+	for _, test := range tests {
+		equals(test.name, reservedName(test.path), t)
+	}
+}
+
+func TestLongPath(t *testing.T) {
+	short := `C:\logs\app.log`
+	equals(short, longPath(short), t)
+
+	deep := `C:\logs` + strings.Repeat(`\deeper`, 40) + `\app.log`
+	equals(`\\?\`+deep, longPath(deep), t)
+	equals(`\\?\`+deep, longPath(`\\?\`+deep), t)
+
+	unc := `\\server\share` + strings.Repeat(`\deeper`, 40) + `\app.log`
+	equals(`\\?\UNC\server\share`+strings.Repeat(`\deeper`, 40)+`\app.log`, longPath(unc), t)
+}
@@ -0,0 +1,45 @@
+package lumberjack
+
+import (
+	"io"
+)
+
+// RotatingWriter is the interface of a Logger, for applications that want to
+// swap it for another implementation, such as a mock in unit tests or a
+// writer that discards everything in development.  Logger and AsyncLogger
+// implement it.
+type RotatingWriter interface {
+	io.WriteCloser
+
+	// Rotate starts a new log file, like Logger.Rotate.
+	Rotate() error
+
+	// Sync commits what was written to stable storage, like Logger.Sync.
+	Sync() error
+
+	// Stats returns statistics about the writes made, like Logger.Stats.
+	Stats() Stats
+}
+
+// WriteSyncer is an io.Writer that can commit what was written to stable
+// storage.  It has the same method set as zapcore.WriteSyncer, so Logger and
+// AsyncLogger can be used with zap directly, without an adapter:
+//
+//	w := zapcore.AddSync(&lumberjack.Logger{Filename: "/var/log/myapp/foo.log"})
+//
+// zapcore.AddSync keeps the Sync method of a writer that has one, so syncing
+// the zap logger flushes any buffered writes and fsyncs the log file, rather
+// than doing nothing.  Syncing is a no-op for special files such as stdout,
+// which can't be synced, so it doesn't fail for them either.
+type WriteSyncer interface {
+	io.Writer
+	Sync() error
+}
+
+var (
+	_ RotatingWriter = (*Logger)(nil)
+	_ RotatingWriter = (*AsyncLogger)(nil)
+
+	_ WriteSyncer = (*Logger)(nil)
+	_ WriteSyncer = (*AsyncLogger)(nil)
+)
@@ -0,0 +1,48 @@
+package lumberjack
+
+import (
+	"os"
+	"testing"
+)
+
+func TestSync(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSync", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	l := &Logger{Filename: filename, MaxSize: 100}
+	defer l.Close()
+
+	// nothing to sync before the log file is opened.
+	isNil(l.Sync(), t)
+
+	b := []byte("boo!")
+	writeToCurrentLog(t, l, filename, b)
+	isNil(l.Sync(), t)
+	isNil(l.Close(), t)
+	isNil(l.Sync(), t)
+}
+
+func TestSyncBuffered(t *testing.T) {
+	currentTime = fakeTime
+	megabyte = 1
+
+	dir := makeTempDir("TestSyncBuffered", t)
+	defer os.RemoveAll(dir)
+
+	filename := logFile(dir)
+	var w RotatingWriter = &Logger{Filename: filename, MaxSize: 100, BufferSize: 100}
+	defer w.Close()
+
+	_, err := w.Write([]byte("boo!"))
+	isNil(err, t)
+	notExist(filename, t)
+
+	// syncing writes out the buffer first.
+	isNil(w.Sync(), t)
+	existsWithContent(filename, []byte("boo!"), t)
+	equals(int64(1), w.Stats().PhysicalWrites, t)
+}